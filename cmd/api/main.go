@@ -1,11 +1,20 @@
 package main
 
 import (
-    "fmt"
-    "github.com/rajatx185/golang-scalable-background-job-system/internal/queue"
+	"log/slog"
+	"net/http"
+	"os"
+
+	"github.com/rajatx185/golang-scalable-background-job-system/internal/handler"
 )
 
 func main() {
-    fmt.Println("API starting")
-    _ = queue1.Enqueue(/*...*/)
-}
\ No newline at end of file
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", handler.LongRunning)
+
+	slog.Info("API starting", "addr", ":8080")
+	if err := http.ListenAndServe(":8080", handler.WithRequestID(handler.WithUser(mux))); err != nil {
+		slog.Error("API stopped", "err", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/rajatx185/golang-scalable-background-job-system/internal/ctxutil"
+	"github.com/rajatx185/golang-scalable-background-job-system/internal/handler"
+	"github.com/rajatx185/golang-scalable-background-job-system/internal/ids"
+	"github.com/rajatx185/golang-scalable-background-job-system/internal/shutdown"
+	"github.com/rajatx185/golang-scalable-background-job-system/internal/worker"
+)
+
+func main() {
+	addr := flag.String("addr", ":8081", "address to accept jobs on")
+	handoff := flag.String("handoff", "queue.json", "file the queue is handed over through across restarts")
+	workers := flag.Int("workers", 4, "number of workers")
+	retention := flag.Duration("result-retention", 10*time.Minute, "how long GET /jobs/{id} still returns a finished job's result")
+	retryAfter := flag.Duration("retry-after", 5*time.Second, "Retry-After sent with a full queue before its drain rate can be estimated")
+	configPath := flag.String("config", "", "JSON file of pool settings that override the flags, reread on SIGHUP")
+	flag.Parse()
+
+	// Jobs, their children and requests all draw IDs from the same
+	// generator.
+	var gen ids.Generator = ids.Random{}
+	base := worker.Config{Workers: *workers, QueueSize: 1024, Overflow: worker.OverflowError, ResultRetention: *retention, IDGenerator: gen}
+	cfg, err := loadConfig(*configPath, base)
+	if err != nil {
+		slog.Error("loading config", "path", *configPath, "err", err)
+		os.Exit(1)
+	}
+	pool := worker.NewPool(cfg, process)
+	// Pick up whatever the previous process handed off before taking new
+	// work, so tasks keep their place in line.
+	if err := pool.Load(*handoff); err != nil {
+		slog.Error("loading handed-off queue", "path", *handoff, "err", err)
+		os.Exit(1)
+	}
+	pool.Start(context.Background())
+	go func() {
+		for r := range pool.Results() {
+			slog.Info("job finished", "job", r.ID, "attempt", r.Attempt, "err", r.Err)
+		}
+	}()
+
+	mux := http.NewServeMux()
+	mux.Handle("POST /jobs", handler.SubmitJob(pool, gen, *retryAfter))
+	// Block until the job finishes, for callers that want its result
+	// synchronously.
+	mux.HandleFunc("GET /jobs/{id}", func(w http.ResponseWriter, r *http.Request) {
+		res, err := pool.Wait(r.Context(), r.PathValue("id"))
+		switch {
+		case errors.Is(err, worker.ErrUnknownJob):
+			http.Error(w, err.Error(), http.StatusNotFound)
+		case err != nil:
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		case res.Err != nil:
+			http.Error(w, res.Err.Error(), http.StatusInternalServerError)
+		default:
+			io.WriteString(w, res.Value+"\n")
+		}
+	})
+	mux.Handle("GET /queue/stats", handler.QueueStats(pool))
+	srv := &http.Server{Addr: *addr, Handler: handler.WithRequestIDFrom(gen, handler.WithUser(mux))}
+
+	// Stop taking jobs, hand the backlog to the next process, then let the
+	// tasks already running finish.
+	shutdowns := shutdown.New(nil)
+	shutdowns.Register("http", 0, 10*time.Second, srv.Shutdown)
+	shutdowns.Register("pool", 10, 30*time.Second, func(context.Context) error {
+		err := pool.Persist(*handoff)
+		pool.Close()
+		return err
+	})
+
+	if *configPath != "" {
+		// Settings that can't change live are logged by Reconfigure and
+		// kept as they were.
+		shutdowns.OnReload("pool", func() error {
+			cfg, err := loadConfig(*configPath, base)
+			if err != nil {
+				return err
+			}
+			pool.Reconfigure(cfg)
+			return nil
+		})
+	}
+
+	ctx, stop := context.WithCancel(context.Background())
+	go func() {
+		slog.Info("worker starting", "addr", *addr, "workers", cfg.Workers)
+		if err := srv.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("server stopped", "err", err)
+			stop()
+		}
+	}()
+	shutdowns.Run(ctx)
+	slog.Info("worker stopped")
+}
+
+// fileConfig is the JSON read from -config. Fields left out keep their
+// values from the flags.
+type fileConfig struct {
+	Workers        *int     `json:"workers"`
+	QueueSize      *int     `json:"queue_size"`
+	DefaultTimeout *string  `json:"default_timeout"` // a time.ParseDuration string
+	RetryBudget    *float64 `json:"retry_budget"`    // retries per second
+}
+
+// loadConfig returns base with the settings in the JSON file at path
+// applied, or base itself if path is empty.
+func loadConfig(path string, base worker.Config) (worker.Config, error) {
+	if path == "" {
+		return base, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return base, err
+	}
+	var fc fileConfig
+	if err := json.Unmarshal(data, &fc); err != nil {
+		return base, err
+	}
+	cfg := base
+	if fc.Workers != nil {
+		cfg.Workers = *fc.Workers
+	}
+	if fc.QueueSize != nil {
+		cfg.QueueSize = *fc.QueueSize
+	}
+	if fc.DefaultTimeout != nil {
+		if cfg.DefaultTimeout, err = time.ParseDuration(*fc.DefaultTimeout); err != nil {
+			return base, err
+		}
+	}
+	if fc.RetryBudget != nil {
+		cfg.RetryBudget = *fc.RetryBudget
+	}
+	return cfg, nil
+}
+
+// process stands in for real job handling, on behalf of the user who
+// submitted the job if the request named one.
+func process(ctx context.Context, t worker.Task) (string, error) {
+	user, ok := ctxutil.UserIDFromContext(ctx)
+	if !ok {
+		user = "anonymous"
+	}
+	select {
+	case <-time.After(time.Second):
+		return "processed " + t.Data + " for " + user, nil
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
@@ -0,0 +1,231 @@
+// Package clock abstracts the passage of time so time-driven code can be
+// tested without sleeping.
+package clock
+
+import (
+	"slices"
+	"sync"
+	"time"
+)
+
+// Clock is the subset of the time package that schedulers depend on.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+	NewTicker(d time.Duration) Ticker
+	NewTimer(d time.Duration) Timer
+}
+
+// Ticker is a time.Ticker obtained from a Clock.
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+// Timer is a time.Timer obtained from a Clock. As with time.Timer since
+// Go 1.23, Reset and Stop discard a tick that fired but was not received,
+// so one timer can be reused for a series of deadlines.
+type Timer interface {
+	C() <-chan time.Time
+	Reset(d time.Duration)
+	Stop()
+}
+
+// Real returns the Clock backed by the time package.
+func Real() Clock { return realClock{} }
+
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+func (realClock) NewTicker(d time.Duration) Ticker       { return realTicker{time.NewTicker(d)} }
+func (realClock) NewTimer(d time.Duration) Timer         { return realTimer{time.NewTimer(d)} }
+
+type realTicker struct{ t *time.Ticker }
+
+func (t realTicker) C() <-chan time.Time { return t.t.C }
+func (t realTicker) Stop()               { t.t.Stop() }
+
+type realTimer struct{ t *time.Timer }
+
+func (t realTimer) C() <-chan time.Time   { return t.t.C }
+func (t realTimer) Reset(d time.Duration) { t.t.Reset(d) }
+func (t realTimer) Stop()                 { t.t.Stop() }
+
+// Manual is a Clock that only moves when Advance is called. Like their
+// real counterparts, its channels hold a single pending tick and drop the
+// rest, so a consumer that falls behind during a large Advance sees the
+// same gaps it would in production.
+type Manual struct {
+	mu      sync.Mutex
+	cond    *sync.Cond // broadcast whenever waiters changes
+	now     time.Time
+	waiters []*waiter
+}
+
+type waiter struct {
+	at     time.Time
+	period time.Duration // zero for one-shot After channels
+	c      chan time.Time
+}
+
+// NewManual returns a Manual clock reading start.
+func NewManual(start time.Time) *Manual {
+	m := &Manual{now: start}
+	m.cond = sync.NewCond(&m.mu)
+	return m
+}
+
+// Now returns the clock's current reading.
+func (m *Manual) Now() time.Time {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.now
+}
+
+// After returns a channel that receives the clock's reading once it has
+// advanced by d.
+func (m *Manual) After(d time.Duration) <-chan time.Time {
+	return m.add(d, 0).c
+}
+
+// NewTicker returns a Ticker that fires every d of advanced time.
+func (m *Manual) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("clock: non-positive interval for NewTicker")
+	}
+	return &manualTicker{m: m, w: m.add(d, d)}
+}
+
+// NewTimer returns a Timer that fires once the clock has advanced by d.
+func (m *Manual) NewTimer(d time.Duration) Timer {
+	t := &manualTimer{m: m, c: make(chan time.Time, 1)}
+	t.Reset(d)
+	return t
+}
+
+func (m *Manual) add(d, period time.Duration) *waiter {
+	return m.addChan(d, period, make(chan time.Time, 1))
+}
+
+// addChan registers a waiter that fires on c, which has room for one tick.
+func (m *Manual) addChan(d, period time.Duration, c chan time.Time) *waiter {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	w := &waiter{at: m.now.Add(d), period: period, c: c}
+	if d <= 0 && period == 0 {
+		select {
+		case w.c <- m.now:
+		default:
+		}
+		return w
+	}
+	m.waiters = append(m.waiters, w)
+	m.cond.Broadcast()
+	return w
+}
+
+func (m *Manual) remove(w *waiter) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i, x := range m.waiters {
+		if x == w {
+			m.waiters = append(m.waiters[:i], m.waiters[i+1:]...)
+			m.cond.Broadcast()
+			return
+		}
+	}
+}
+
+// Advance moves the clock forward by d, firing every timer and ticker
+// that comes due on the way in chronological order.
+func (m *Manual) Advance(d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.advanceTo(m.now.Add(d))
+}
+
+// Tick moves the clock straight to the soonest pending deadline, firing
+// every timer and ticker due then, and returns the new reading. It is one
+// tick of whatever the code under test waits on, without the test having
+// to know its interval. With nothing pending the clock stays put.
+func (m *Manual) Tick() time.Time {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.waiters) == 0 {
+		return m.now
+	}
+	next := m.waiters[0].at
+	for _, w := range m.waiters[1:] {
+		if w.at.Before(next) {
+			next = w.at
+		}
+	}
+	m.advanceTo(next)
+	return m.now
+}
+
+// advanceTo is Advance to the reading end. It requires m.mu.
+func (m *Manual) advanceTo(end time.Time) {
+	for {
+		slices.SortStableFunc(m.waiters, func(a, b *waiter) int { return a.at.Compare(b.at) })
+		if len(m.waiters) == 0 || m.waiters[0].at.After(end) {
+			break
+		}
+		w := m.waiters[0]
+		m.now = w.at
+		select {
+		case w.c <- w.at:
+		default: // the previous tick is still unread
+		}
+		if w.period > 0 {
+			w.at = w.at.Add(w.period)
+		} else {
+			m.waiters = m.waiters[1:]
+			m.cond.Broadcast()
+		}
+	}
+	m.now = end
+}
+
+// BlockUntil waits until at least n timers or tickers are pending, so a
+// test can be sure the code under test is waiting before it advances.
+func (m *Manual) BlockUntil(n int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for len(m.waiters) < n {
+		m.cond.Wait()
+	}
+}
+
+type manualTicker struct {
+	m *Manual
+	w *waiter
+}
+
+func (t *manualTicker) C() <-chan time.Time { return t.w.c }
+func (t *manualTicker) Stop()               { t.m.remove(t.w) }
+
+type manualTimer struct {
+	m *Manual
+	c chan time.Time
+	w *waiter // nil once stopped
+}
+
+func (t *manualTimer) C() <-chan time.Time { return t.c }
+
+func (t *manualTimer) Reset(d time.Duration) {
+	t.Stop()
+	t.w = t.m.addChan(d, 0, t.c)
+}
+
+func (t *manualTimer) Stop() {
+	if t.w != nil {
+		t.m.remove(t.w)
+		t.w = nil
+	}
+	select {
+	case <-t.c:
+	default:
+	}
+}
@@ -0,0 +1,97 @@
+package clock_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rajatx185/golang-scalable-background-job-system/internal/clock"
+)
+
+func TestManualAfter(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clk := clock.NewManual(start)
+	c := clk.After(time.Minute)
+
+	clk.Advance(59 * time.Second)
+	select {
+	case <-c:
+		t.Fatal("After fired early")
+	default:
+	}
+	clk.Advance(time.Second)
+	if got := <-c; !got.Equal(start.Add(time.Minute)) {
+		t.Fatalf("After delivered %v, want %v", got, start.Add(time.Minute))
+	}
+}
+
+func TestManualTickerDropsUnreadTicks(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clk := clock.NewManual(start)
+	ticker := clk.NewTicker(10 * time.Second)
+	defer ticker.Stop()
+
+	clk.Advance(35 * time.Second)
+	if got := <-ticker.C(); !got.Equal(start.Add(10 * time.Second)) {
+		t.Fatalf("first tick = %v, want the oldest one", got)
+	}
+	select {
+	case got := <-ticker.C():
+		t.Fatalf("ticks were queued: got %v", got)
+	default:
+	}
+	clk.Advance(5 * time.Second)
+	if got := <-ticker.C(); !got.Equal(start.Add(40 * time.Second)) {
+		t.Fatalf("next tick = %v, want %v", got, start.Add(40*time.Second))
+	}
+}
+
+func TestManualTickJumpsToNextDeadline(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clk := clock.NewManual(start)
+	if got := clk.Tick(); !got.Equal(start) {
+		t.Fatalf("Tick with nothing pending moved the clock to %v", got)
+	}
+	ticker := clk.NewTicker(time.Minute)
+	defer ticker.Stop()
+	after := clk.After(time.Minute)
+
+	if got := clk.Tick(); !got.Equal(start.Add(time.Minute)) {
+		t.Fatalf("Tick = %v, want the ticker's first deadline", got)
+	}
+	<-ticker.C()
+	<-after // due at the same instant, so fired by the same Tick
+	if got := clk.Tick(); !got.Equal(start.Add(2 * time.Minute)) {
+		t.Fatalf("second Tick = %v, want %v", got, start.Add(2*time.Minute))
+	}
+	<-ticker.C()
+}
+
+func TestManualTimerReset(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clk := clock.NewManual(start)
+	timer := clk.NewTimer(10 * time.Second)
+
+	clk.Advance(10 * time.Second)
+	// The unread tick is discarded by Reset, which moves the timer on.
+	timer.Reset(time.Minute)
+	clk.Advance(30 * time.Second)
+	select {
+	case got := <-timer.C():
+		t.Fatalf("timer fired at %v before its reset deadline", got)
+	default:
+	}
+	// Resetting to sooner pulls it in.
+	timer.Reset(5 * time.Second)
+	clk.Advance(5 * time.Second)
+	if got := <-timer.C(); !got.Equal(start.Add(45 * time.Second)) {
+		t.Fatalf("timer fired at %v, want %v", got, start.Add(45*time.Second))
+	}
+	timer.Reset(time.Second)
+	timer.Stop()
+	clk.Advance(time.Minute)
+	select {
+	case got := <-timer.C():
+		t.Fatalf("stopped timer fired at %v", got)
+	default:
+	}
+}
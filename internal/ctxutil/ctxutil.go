@@ -0,0 +1,111 @@
+// Package ctxutil holds typed accessors for request-scoped context values.
+// Keys are unexported so no other package can collide with them or store a
+// value of the wrong type under them.
+package ctxutil
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+type contextKey string
+
+const (
+	userIDKey    contextKey = "userID"
+	requestIDKey contextKey = "requestID"
+)
+
+// WithUserID returns a copy of ctx carrying the given user ID.
+func WithUserID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, userIDKey, id)
+}
+
+// UserIDFromContext returns the user ID stored by WithUserID. It reports
+// false, rather than panicking, when the value is missing.
+func UserIDFromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(userIDKey).(string)
+	return id, ok
+}
+
+// WithRequestID returns a copy of ctx carrying the given request ID.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey, id)
+}
+
+// RequestIDFromContext returns the request ID stored by WithRequestID,
+// reporting false when there is none.
+func RequestIDFromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(requestIDKey).(string)
+	return id, ok
+}
+
+// Keys returns the keys of every value this package stores, so callers can
+// name them — to propagate them to background work with
+// worker.WithPropagatedValues, say — without being able to misuse them.
+func Keys() []any {
+	return []any{userIDKey, requestIDKey}
+}
+
+// EffectiveDeadline returns when work given timeout under ctx really has
+// to finish: the earlier of timeout from now and ctx's own deadline, since
+// a context derived with context.WithTimeout is still cancelled when its
+// parent is. A timeout of zero or less adds no deadline of its own. ok is
+// false when there is no deadline at all.
+func EffectiveDeadline(ctx context.Context, timeout time.Duration) (deadline time.Time, ok bool) {
+	deadline, ok = ctx.Deadline()
+	if timeout > 0 {
+		if own := time.Now().Add(timeout); !ok || own.Before(deadline) {
+			deadline, ok = own, true
+		}
+	}
+	return deadline, ok
+}
+
+// MergeContexts returns a context that is done as soon as either a or b
+// is, carrying a's values and the earlier of their deadlines. Its Err is
+// that of whichever parent finished it, and context.Cause reports that
+// parent's cause. Calling cancel releases the link to b; like any
+// CancelFunc it should be called once the context is no longer needed.
+//
+// No goroutine waits on the parents: the link to b is a
+// context.AfterFunc, which only runs once b is done, so a merge whose
+// parents outlive it leaks nothing even if cancel is forgotten until
+// then.
+func MergeContexts(a, b context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancelCause(a)
+	m := &merged{Context: ctx, b: b}
+	stop := context.AfterFunc(b, func() {
+		if ctx.Err() == nil {
+			m.byB.Store(true)
+		}
+		cancel(context.Cause(b))
+	})
+	return m, func() {
+		stop()
+		cancel(context.Canceled)
+	}
+}
+
+// merged is a's cancellable child, also cancelled by b.
+type merged struct {
+	context.Context
+	b   context.Context
+	byB atomic.Bool // b finished it
+}
+
+func (m *merged) Deadline() (time.Time, bool) {
+	deadline, ok := m.Context.Deadline()
+	if other, otherOK := m.b.Deadline(); otherOK && (!ok || other.Before(deadline)) {
+		return other, true
+	}
+	return deadline, ok
+}
+
+func (m *merged) Err() error {
+	err := m.Context.Err()
+	if err != nil && m.byB.Load() {
+		return m.b.Err()
+	}
+	return err
+}
@@ -0,0 +1,139 @@
+package ctxutil_test
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/rajatx185/golang-scalable-background-job-system/internal/ctxutil"
+)
+
+func TestUserIDRoundTrip(t *testing.T) {
+	ctx := ctxutil.WithUserID(context.Background(), "rajatx185")
+
+	id, ok := ctxutil.UserIDFromContext(ctx)
+	if !ok || id != "rajatx185" {
+		t.Fatalf("UserIDFromContext = %q, %v; want %q, true", id, ok, "rajatx185")
+	}
+}
+
+func TestUserIDMissing(t *testing.T) {
+	// A plain string key with the same text must not be mistaken for ours.
+	ctx := context.WithValue(context.Background(), "userID", "someone")
+
+	id, ok := ctxutil.UserIDFromContext(ctx)
+	if ok || id != "" {
+		t.Fatalf("UserIDFromContext = %q, %v; want \"\", false", id, ok)
+	}
+}
+
+func TestEffectiveDeadline(t *testing.T) {
+	parent, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	parentDeadline, _ := parent.Deadline()
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		timeout time.Duration
+		want    time.Time // zero means no deadline
+	}{
+		{"parent is sooner", parent, 2 * time.Second, parentDeadline},
+		{"timeout is sooner", parent, time.Millisecond, time.Now().Add(time.Millisecond)},
+		{"no timeout of its own", parent, 0, parentDeadline},
+		{"no parent deadline", context.Background(), time.Minute, time.Now().Add(time.Minute)},
+		{"neither", context.Background(), 0, time.Time{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ctxutil.EffectiveDeadline(tt.ctx, tt.timeout)
+			if ok != !tt.want.IsZero() {
+				t.Fatalf("ok = %v, want %v", ok, !tt.want.IsZero())
+			}
+			if d := got.Sub(tt.want); d < -50*time.Millisecond || d > 50*time.Millisecond {
+				t.Fatalf("deadline off by %s", d)
+			}
+		})
+	}
+}
+
+func TestNestedTimeoutFiresAtParentDeadline(t *testing.T) {
+	// A 2s query timeout under a request with 100ms left must still be
+	// cut short by the request.
+	parent, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	child, cancelChild := context.WithTimeout(parent, 2*time.Second)
+	defer cancelChild()
+
+	start := time.Now()
+	<-child.Done()
+	if took := time.Since(start); took > time.Second {
+		t.Fatalf("child fired after %s, want about 100ms", took)
+	}
+	if !errors.Is(child.Err(), context.DeadlineExceeded) {
+		t.Fatalf("child err = %v, want DeadlineExceeded", child.Err())
+	}
+}
+
+func TestMergeContexts(t *testing.T) {
+	type key struct{}
+	a, cancelA := context.WithCancel(context.WithValue(context.Background(), key{}, "a"))
+	defer cancelA()
+	b, cancelB := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancelB()
+
+	ctx, cancel := ctxutil.MergeContexts(a, b)
+	defer cancel()
+	if v := ctx.Value(key{}); v != "a" {
+		t.Fatalf("Value = %v, want a's value", v)
+	}
+	want, _ := b.Deadline()
+	if deadline, ok := ctx.Deadline(); !ok || !deadline.Equal(want) {
+		t.Fatalf("Deadline = %v, %v, want b's %v", deadline, ok, want)
+	}
+	<-ctx.Done()
+	if !errors.Is(ctx.Err(), context.DeadlineExceeded) || a.Err() != nil {
+		t.Fatalf("Err = %v with a still live, want b's DeadlineExceeded", ctx.Err())
+	}
+
+	// Either parent will do.
+	a2, cancelA2 := context.WithCancel(context.Background())
+	ctx, cancel = ctxutil.MergeContexts(a2, context.Background())
+	defer cancel()
+	cancelA2()
+	<-ctx.Done()
+	if !errors.Is(ctx.Err(), context.Canceled) {
+		t.Fatalf("Err = %v after a was cancelled, want Canceled", ctx.Err())
+	}
+}
+
+func TestMergeContextsLeaksNoGoroutine(t *testing.T) {
+	const n = 1000
+	before := runtime.NumGoroutine()
+	a, cancelA := context.WithCancel(context.Background())
+	b, cancelB := context.WithCancel(context.Background())
+	cancels := make([]context.CancelFunc, n)
+	for i := range cancels {
+		_, cancels[i] = ctxutil.MergeContexts(a, b)
+	}
+	// A merge that spawned a watcher would show here, with both parents
+	// still live.
+	if during := runtime.NumGoroutine(); during-before > 10 {
+		t.Fatalf("%d goroutines running for %d live merges, want none", during-before, n)
+	}
+	for _, cancel := range cancels {
+		cancel()
+	}
+	cancelA()
+	cancelB()
+
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if after := runtime.NumGoroutine(); after > before {
+		t.Fatalf("%d goroutines left after cancelling every merge, want %d", after, before)
+	}
+}
@@ -1,2 +1,22 @@
 package handler
 
+import (
+	"net/http"
+
+	"github.com/rajatx185/golang-scalable-background-job-system/internal/ctxutil"
+)
+
+// UserIDHeader is the request header WithUser reads the caller's ID from.
+const UserIDHeader = "X-User-ID"
+
+// WithUser copies the caller's user ID from the request header into the
+// request context, where handlers and the jobs they submit can read it with
+// ctxutil.UserIDFromContext.
+func WithUser(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if id := r.Header.Get(UserIDHeader); id != "" {
+			r = r.WithContext(ctxutil.WithUserID(r.Context(), id))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/rajatx185/golang-scalable-background-job-system/internal/ctxutil"
+	"github.com/rajatx185/golang-scalable-background-job-system/internal/ids"
+	"github.com/rajatx185/golang-scalable-background-job-system/internal/worker"
+)
+
+// JobSubmitter is the part of a worker.Pool SubmitJob needs.
+type JobSubmitter interface {
+	Submit(ctx context.Context, t worker.Task) error
+	DrainEstimate() (time.Duration, bool)
+}
+
+// SubmitJob returns the POST /jobs handler: it submits the request body
+// to pool as a task's Data, under an ID from gen, and answers 202 with
+// the ID. The request's ctxutil values, such as the user WithUser stored,
+// are propagated to the task's context. A refused submit answers 503. When the queue is full, that 503
+// carries a Retry-After saying when the backlog should have room again,
+// going by pool's DrainEstimate or fallback when it has none. Retry-After
+// is never under a second.
+func SubmitJob(pool JobSubmitter, gen ids.Generator, fallback time.Duration) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		t := worker.Task{ID: ids.From(gen, "job"), Data: string(body)}
+		ctx := worker.WithPropagatedValues(r.Context(), ctxutil.Keys()...)
+		if err := pool.Submit(ctx, t); err != nil {
+			if errors.Is(err, worker.ErrQueueFull) {
+				wait, ok := pool.DrainEstimate()
+				if !ok {
+					wait = fallback
+				}
+				w.Header().Set("Retry-After", retryAfter(wait))
+			}
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+		io.WriteString(w, t.ID+"\n")
+	}
+}
+
+// retryAfter renders wait as a Retry-After value in whole seconds, rounded
+// up and at least one.
+func retryAfter(wait time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(max(wait, time.Second).Seconds())))
+}
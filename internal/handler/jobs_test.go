@@ -0,0 +1,94 @@
+package handler_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rajatx185/golang-scalable-background-job-system/internal/ctxutil"
+	"github.com/rajatx185/golang-scalable-background-job-system/internal/handler"
+	"github.com/rajatx185/golang-scalable-background-job-system/internal/ids"
+	"github.com/rajatx185/golang-scalable-background-job-system/internal/worker"
+)
+
+// fakePool answers every Submit with err, and DrainEstimate with estimate
+// and ok, recording the tasks it was given.
+type fakePool struct {
+	err      error
+	estimate time.Duration
+	ok       bool
+	got      []worker.Task
+}
+
+func (p *fakePool) Submit(_ context.Context, t worker.Task) error {
+	p.got = append(p.got, t)
+	return p.err
+}
+
+func (p *fakePool) DrainEstimate() (time.Duration, bool) { return p.estimate, p.ok }
+
+func TestSubmitJob(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		pool       fakePool
+		wantStatus int
+		wantRetry  string
+	}{
+		{"accepted", fakePool{}, http.StatusAccepted, ""},
+		{"full, estimated", fakePool{err: worker.ErrQueueFull, estimate: 2500 * time.Millisecond, ok: true}, http.StatusServiceUnavailable, "3"},
+		{"full, estimate under a second", fakePool{err: worker.ErrQueueFull, estimate: -time.Hour, ok: true}, http.StatusServiceUnavailable, "1"},
+		{"full, no estimate", fakePool{err: worker.ErrQueueFull}, http.StatusServiceUnavailable, "5"},
+		{"closed", fakePool{err: worker.ErrClosed}, http.StatusServiceUnavailable, ""},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			srv := httptest.NewServer(handler.SubmitJob(&tc.pool, &ids.Sequence{}, 5*time.Second))
+			defer srv.Close()
+
+			resp, err := http.Post(srv.URL, "text/plain", strings.NewReader("payload"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tc.wantStatus {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tc.wantStatus)
+			}
+			if got := resp.Header.Get("Retry-After"); got != tc.wantRetry {
+				t.Errorf("Retry-After = %q, want %q", got, tc.wantRetry)
+			}
+			if len(tc.pool.got) != 1 || tc.pool.got[0].Data != "payload" || tc.pool.got[0].ID != "job-1" {
+				t.Errorf("submitted %+v, want one task job-1 carrying the body", tc.pool.got)
+			}
+		})
+	}
+}
+
+func TestSubmitJobPropagatesUser(t *testing.T) {
+	users := make(chan string, 1)
+	pool := worker.NewPool(worker.Config{}, func(ctx context.Context, t worker.Task) (string, error) {
+		user, _ := ctxutil.UserIDFromContext(ctx)
+		users <- user
+		return "", nil
+	})
+	pool.Start(context.Background())
+	defer pool.Close()
+	go func() {
+		for range pool.Results() {
+		}
+	}()
+	srv := httptest.NewServer(handler.WithUser(handler.SubmitJob(pool, &ids.Sequence{}, time.Second)))
+	defer srv.Close()
+
+	req, _ := http.NewRequest(http.MethodPost, srv.URL, strings.NewReader("payload"))
+	req.Header.Set(handler.UserIDHeader, "u-42")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if user := <-users; user != "u-42" {
+		t.Fatalf("task saw user %q, want u-42", user)
+	}
+}
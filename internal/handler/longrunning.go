@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/rajatx185/golang-scalable-background-job-system/internal/ctxutil"
+)
+
+// StatusClientClosedRequest is the non-standard status, borrowed from nginx,
+// that LongRunning records when the client hung up before the response.
+const StatusClientClosedRequest = 499
+
+// LongRunning simulates a slow operation that stops as soon as the client
+// goes away or the request's deadline passes, answering 499 for the first
+// and 504 for the second so the logs show who gave up. It expects
+// WithRequestID to have tagged the context.
+func LongRunning(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	requestID, _ := ctxutil.RequestIDFromContext(ctx)
+
+	result := make(chan string, 1)
+	go processRequest(ctx, result)
+
+	select {
+	case res := <-result:
+		fmt.Fprintf(w, "Request completed: %s\n", res)
+	case <-ctx.Done():
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			slog.Warn("request timed out", "request_id", requestID, "err", ctx.Err())
+			http.Error(w, "Request timed out", http.StatusGatewayTimeout)
+			return
+		}
+		slog.Info("client closed request", "request_id", requestID, "err", ctx.Err())
+		http.Error(w, "Client closed request", StatusClientClosedRequest)
+	}
+}
+
+func processRequest(ctx context.Context, result chan<- string) {
+	requestID, _ := ctxutil.RequestIDFromContext(ctx)
+
+	for i := 0; i < 5; i++ {
+		select {
+		case <-ctx.Done():
+			slog.Info("request stopping early", "request_id", requestID, "err", ctx.Err())
+			return
+		case <-time.After(100 * time.Millisecond):
+			slog.Info("request processing", "request_id", requestID, "step", i+1)
+		}
+	}
+
+	result <- "Done!"
+}
@@ -0,0 +1,33 @@
+package handler_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/rajatx185/golang-scalable-background-job-system/internal/handler"
+)
+
+func TestLongRunningStatusNamesWhoGaveUp(t *testing.T) {
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	timedOut, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+
+	for _, tc := range []struct {
+		name string
+		ctx  context.Context
+		want int
+	}{
+		{"client cancel", cancelled, handler.StatusClientClosedRequest},
+		{"deadline", timedOut, http.StatusGatewayTimeout},
+	} {
+		rec := httptest.NewRecorder()
+		handler.LongRunning(rec, httptest.NewRequest(http.MethodGet, "/", nil).WithContext(tc.ctx))
+		if rec.Code != tc.want {
+			t.Errorf("%s: status = %d, want %d", tc.name, rec.Code, tc.want)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/rajatx185/golang-scalable-background-job-system/internal/ctxutil"
+	"github.com/rajatx185/golang-scalable-background-job-system/internal/ids"
+)
+
+// RequestIDHeader carries the generated request ID back to the client.
+const RequestIDHeader = "X-Request-ID"
+
+// WithRequestID gives every request an ID, stores it in the request context
+// for ctxutil.RequestIDFromContext, and logs one line per request with the
+// method, path, status and duration tagged with that ID.
+func WithRequestID(next http.Handler) http.Handler {
+	return WithRequestIDFrom(ids.Random{}, next)
+}
+
+// WithRequestIDFrom is WithRequestID with the IDs taken from gen.
+func WithRequestIDFrom(gen ids.Generator, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := ids.From(gen, "req")
+		start := time.Now()
+		w.Header().Set(RequestIDHeader, id)
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+
+		next.ServeHTTP(rec, r.WithContext(ctxutil.WithRequestID(r.Context(), id)))
+
+		slog.Info("request",
+			"request_id", id,
+			"method", r.Method,
+			"path", r.URL.Path,
+			"status", rec.status,
+			"duration", time.Since(start))
+	})
+}
+
+// statusRecorder remembers the status code written through it.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -0,0 +1,43 @@
+package handler_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rajatx185/golang-scalable-background-job-system/internal/ctxutil"
+	"github.com/rajatx185/golang-scalable-background-job-system/internal/handler"
+	"github.com/rajatx185/golang-scalable-background-job-system/internal/ids"
+)
+
+func TestWithRequestIDTagsContextAndResponse(t *testing.T) {
+	var seen string
+	h := handler.WithRequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen, _ = ctxutil.RequestIDFromContext(r.Context())
+		w.WriteHeader(http.StatusAccepted)
+	}))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if seen == "" {
+		t.Fatal("handler saw no request ID in its context")
+	}
+	if got := rec.Header().Get(handler.RequestIDHeader); got != seen {
+		t.Fatalf("%s header = %q, want %q", handler.RequestIDHeader, got, seen)
+	}
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusAccepted)
+	}
+}
+
+func TestWithRequestIDFromGenerator(t *testing.T) {
+	h := handler.WithRequestIDFrom(&ids.Sequence{}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	for _, want := range []string{"req-1", "req-2"} {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		if got := rec.Header().Get(handler.RequestIDHeader); got != want {
+			t.Fatalf("%s header = %q, want %q", handler.RequestIDHeader, got, want)
+		}
+	}
+}
@@ -0,0 +1,22 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/rajatx185/golang-scalable-background-job-system/internal/worker"
+)
+
+// StatsSource is the part of a worker.Pool QueueStats needs.
+type StatsSource interface {
+	Stats() worker.Stats
+}
+
+// QueueStats returns the GET /queue/stats handler, which answers with a
+// snapshot of pool's Stats as JSON. Durations are in nanoseconds.
+func QueueStats(pool StatsSource) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(pool.Stats())
+	}
+}
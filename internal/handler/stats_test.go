@@ -0,0 +1,56 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/rajatx185/golang-scalable-background-job-system/internal/handler"
+	"github.com/rajatx185/golang-scalable-background-job-system/internal/worker"
+)
+
+type fakeStats worker.Stats
+
+func (s fakeStats) Stats() worker.Stats { return worker.Stats(s) }
+
+func TestQueueStatsFieldNames(t *testing.T) {
+	srv := httptest.NewServer(handler.QueueStats(fakeStats{
+		Queued:        3,
+		OldestQueued:  2 * time.Second,
+		Retrying:      1,
+		Workers:       4,
+		ActiveWorkers: 2,
+		Succeeded:     10,
+		Failed:        5,
+	}))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "application/json" {
+		t.Fatalf("Content-Type = %q, want application/json", ct)
+	}
+	var got map[string]any
+	if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	// Dashboards read these by name, so renaming a field breaks them.
+	for name, want := range map[string]float64{
+		"Queued":        3,
+		"OldestQueued":  float64(2 * time.Second),
+		"Retrying":      1,
+		"Workers":       4,
+		"ActiveWorkers": 2,
+		"Succeeded":     10,
+		"Failed":        5,
+	} {
+		if v, ok := got[name].(float64); !ok || v != want {
+			t.Errorf("%s = %v, want %v", name, got[name], want)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+// Package httpclient owns the process-wide http.Client used for every
+// outbound request, so fan-out work shares one tuned connection pool instead
+// of churning connections through http.DefaultClient.
+package httpclient
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Config tunes the shared client's transport.
+type Config struct {
+	// MaxIdleConnsPerHost caps idle keep-alive connections kept per host.
+	MaxIdleConnsPerHost int
+	// IdleConnTimeout is how long an idle connection stays in the pool.
+	IdleConnTimeout time.Duration
+	// Timeout bounds each request end to end (dial, headers and body).
+	Timeout time.Duration
+}
+
+// DefaultConfig returns the settings used when Init is never called.
+func DefaultConfig() Config {
+	return Config{
+		MaxIdleConnsPerHost: 32,
+		IdleConnTimeout:     90 * time.Second,
+		Timeout:             30 * time.Second,
+	}
+}
+
+var (
+	once   sync.Once
+	shared *http.Client
+)
+
+// New builds a client from cfg. Most callers want Default instead; New is
+// for the rare caller that needs a pool isolated from everyone else.
+func New(cfg Config) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConnsPerHost = cfg.MaxIdleConnsPerHost
+	transport.IdleConnTimeout = cfg.IdleConnTimeout
+	return &http.Client{
+		Transport: transport,
+		Timeout:   cfg.Timeout,
+	}
+}
+
+// Init builds the shared client from cfg exactly once. Only the first call
+// (from Init or Default) decides the configuration; later calls return the
+// existing client and ignore cfg.
+func Init(cfg Config) *http.Client {
+	once.Do(func() {
+		shared = New(cfg)
+	})
+	return shared
+}
+
+// Default returns the shared client, building it with DefaultConfig if Init
+// has not run yet.
+func Default() *http.Client {
+	return Init(DefaultConfig())
+}
+
+// FetchWithTimeout GETs url through the shared client and returns the body,
+// giving up after timeout or when ctx is done, whichever comes first.
+func FetchWithTimeout(ctx context.Context, url string, timeout time.Duration) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := Default().Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	return string(body), nil
+}
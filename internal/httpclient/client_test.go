@@ -0,0 +1,143 @@
+package httpclient_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rajatx185/golang-scalable-background-job-system/internal/httpclient"
+)
+
+// slowServer answers "ok" after delay, or gives up when the client does.
+func slowServer(t *testing.T, delay time.Duration) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(delay):
+			io.WriteString(w, "ok")
+		case <-r.Context().Done():
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestFetchWithTimeout(t *testing.T) {
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		delay   time.Duration
+		timeout time.Duration
+		want    string
+		wantErr error
+	}{
+		{name: "within timeout", ctx: context.Background(), timeout: time.Second, want: "ok"},
+		{name: "past timeout", ctx: context.Background(), delay: time.Second, timeout: 20 * time.Millisecond, wantErr: context.DeadlineExceeded},
+		{name: "parent canceled", ctx: canceled, timeout: time.Second, wantErr: context.Canceled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := slowServer(t, tt.delay)
+			got, err := httpclient.FetchWithTimeout(tt.ctx, srv.URL, tt.timeout)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("body = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewAppliesConfigTimeout(t *testing.T) {
+	tests := []struct {
+		name    string
+		timeout time.Duration
+		delay   time.Duration
+		wantErr bool
+	}{
+		{name: "within timeout", timeout: time.Second},
+		{name: "past timeout", timeout: 20 * time.Millisecond, delay: time.Second, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := slowServer(t, tt.delay)
+			cfg := httpclient.DefaultConfig()
+			cfg.Timeout = tt.timeout
+			resp, err := httpclient.New(cfg).Get(srv.URL)
+			if err == nil {
+				resp.Body.Close()
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error %v", err, tt.wantErr)
+			}
+			var netErr interface{ Timeout() bool }
+			if tt.wantErr && !(errors.As(err, &netErr) && netErr.Timeout()) {
+				t.Errorf("err = %v, want a timeout", err)
+			}
+		})
+	}
+}
+
+// A retry after a timed-out fetch goes through the same shared client, so
+// one slow call must not leave it unusable for the next.
+func TestFetchRetryAfterTimeout(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if hits.Add(1) == 1 {
+			<-r.Context().Done()
+			return
+		}
+		io.WriteString(w, "ok")
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name    string
+		want    string
+		wantErr error
+	}{
+		{name: "first attempt times out", wantErr: context.DeadlineExceeded},
+		{name: "retry succeeds", want: "ok"},
+	}
+	for _, tt := range tests {
+		got, err := httpclient.FetchWithTimeout(context.Background(), srv.URL, 50*time.Millisecond)
+		if !errors.Is(err, tt.wantErr) || got != tt.want {
+			t.Fatalf("%s: got (%q, %v), want (%q, %v)", tt.name, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+// The tuned transport keeps connections alive between fetches; when the
+// server drops one, the next GET is retried on a fresh connection instead
+// of failing.
+func TestFetchRetriesDroppedKeepAlive(t *testing.T) {
+	srv := slowServer(t, 0)
+	for i := range 3 {
+		got, err := httpclient.FetchWithTimeout(context.Background(), srv.URL, time.Second)
+		if err != nil || got != "ok" {
+			t.Fatalf("fetch %d: got (%q, %v), want (\"ok\", nil)", i, got, err)
+		}
+		srv.CloseClientConnections()
+	}
+}
+
+// Other tests may already have built the shared client, so this only pins
+// that later configuration is ignored.
+func TestInitConfiguresOnce(t *testing.T) {
+	first := httpclient.Default()
+	if again := httpclient.Init(httpclient.Config{Timeout: time.Hour}); again != first {
+		t.Error("Init built a new client after Default")
+	}
+	if first.Timeout == time.Hour {
+		t.Error("later Init changed the shared client's Timeout")
+	}
+}
@@ -0,0 +1,43 @@
+// Package ids generates identifiers for requests and jobs.
+package ids
+
+import (
+	"crypto/rand"
+	"strconv"
+	"sync/atomic"
+)
+
+// Generator produces the unique part of an ID. Implementations must be
+// safe for concurrent use.
+type Generator interface {
+	NewID() string
+}
+
+// Random is the default Generator: a random 128-bit identifier from
+// crypto/rand, so unlike timestamp-based IDs they don't collide when many
+// are generated in a tight loop or across processes.
+type Random struct{}
+
+func (Random) NewID() string { return rand.Text() }
+
+// Sequence is a deterministic Generator for tests: it returns 1, 2, 3, and
+// so on. The zero value is ready to use.
+type Sequence struct {
+	n atomic.Int64
+}
+
+func (s *Sequence) NewID() string { return strconv.FormatInt(s.n.Add(1), 10) }
+
+// New returns prefix followed by an ID from Random.
+func New(prefix string) string {
+	return From(Random{}, prefix)
+}
+
+// From returns prefix followed by an ID from g, or from Random if g is
+// nil.
+func From(g Generator, prefix string) string {
+	if g == nil {
+		g = Random{}
+	}
+	return prefix + "-" + g.NewID()
+}
@@ -0,0 +1,39 @@
+// Package lazy builds shared dependencies on first use.
+package lazy
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Initializer builds a value exactly once, on the first call to Get, and
+// hands the same value to every caller after that.
+//
+// It relies on the sync.Once guarantee that the init function returns
+// before any Do call returns: every Get, from any goroutine, observes the
+// fully built value without extra locking. A failed or panicking init is
+// not retried; every caller gets the same error.
+type Initializer[T any] struct {
+	once  sync.Once
+	init  func() (T, error)
+	value T
+	err   error
+}
+
+// NewInitializer returns an Initializer that will build its value with init.
+func NewInitializer[T any](init func() (T, error)) *Initializer[T] {
+	return &Initializer[T]{init: init}
+}
+
+// Get returns the value, building it first if no caller has yet.
+func (i *Initializer[T]) Get() (T, error) {
+	i.once.Do(func() {
+		defer func() {
+			if r := recover(); r != nil {
+				i.err = fmt.Errorf("lazy: panic during initialization: %v", r)
+			}
+		}()
+		i.value, i.err = i.init()
+	})
+	return i.value, i.err
+}
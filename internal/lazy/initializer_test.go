@@ -0,0 +1,54 @@
+package lazy_test
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rajatx185/golang-scalable-background-job-system/internal/lazy"
+)
+
+type dependency struct {
+	ready bool
+}
+
+func TestInitializerRunsOnce(t *testing.T) {
+	var calls atomic.Int32
+	init := lazy.NewInitializer(func() (*dependency, error) {
+		calls.Add(1)
+		time.Sleep(10 * time.Millisecond) // widen the window for racing callers
+		return &dependency{ready: true}, nil
+	})
+
+	var wg sync.WaitGroup
+	got := make([]*dependency, 100)
+	for i := range got {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			dep, err := init.Get()
+			if err != nil {
+				t.Error(err)
+			}
+			got[i] = dep
+		}()
+	}
+	wg.Wait()
+
+	if n := calls.Load(); n != 1 {
+		t.Fatalf("init ran %d times, want 1", n)
+	}
+	for i, dep := range got {
+		if dep != got[0] || !dep.ready {
+			t.Fatalf("goroutine %d saw %+v, want the single initialized value", i, dep)
+		}
+	}
+}
+
+func TestInitializerPanicBecomesError(t *testing.T) {
+	init := lazy.NewInitializer(func() (int, error) { panic("no database") })
+	if _, err := init.Get(); err == nil {
+		t.Fatal("Get after panicking init returned nil error")
+	}
+}
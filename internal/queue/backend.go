@@ -0,0 +1,29 @@
+package queue1
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrUnknownMessage is returned by Ack for an ID that is not in flight.
+var ErrUnknownMessage = errors.New("queue: unknown message")
+
+// Message is one opaque payload on a backend.
+type Message struct {
+	ID   string
+	Body []byte
+	// Deliveries counts how many times the message has been handed out,
+	// including the current delivery.
+	Deliveries int
+}
+
+// QueueBackend is a durable queue with at-least-once delivery. A dequeued
+// message stays invisible to other consumers until it is acked or its
+// visibility timeout runs out, at which point it is delivered again.
+type QueueBackend interface {
+	Enqueue(ctx context.Context, msg Message) error
+	// Dequeue blocks until a message is available or ctx is done.
+	Dequeue(ctx context.Context) (Message, error)
+	// Ack removes a delivered message for good.
+	Ack(ctx context.Context, id string) error
+}
@@ -0,0 +1,182 @@
+package queue1
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// ErrInjected is the error a FakeBackend returns for an injected failure
+// that was given no error of its own.
+var ErrInjected = errors.New("queue: injected failure")
+
+// Op names a QueueBackend method, for injecting failures into a
+// FakeBackend and counting its calls.
+type Op string
+
+const (
+	OpEnqueue Op = "Enqueue"
+	OpDequeue Op = "Dequeue"
+	OpAck     Op = "Ack"
+)
+
+// FakeBackend is a MemoryBackend whose calls can be made to fail or lag on
+// demand, for exercising the retry, dead-letter and backoff paths of a
+// consumer deterministically. Until told otherwise it behaves exactly like
+// the MemoryBackend it wraps. It is safe for concurrent use.
+type FakeBackend struct {
+	*MemoryBackend
+
+	mu      sync.Mutex
+	calls   map[Op]int
+	onCall  map[Op]map[int]error
+	forID   map[Op]map[string]error
+	latency time.Duration
+}
+
+// NewFakeBackend returns an empty FakeBackend that redelivers any message
+// not acked within visibility of being dequeued.
+func NewFakeBackend(visibility time.Duration) *FakeBackend {
+	return &FakeBackend{
+		MemoryBackend: NewMemoryBackend(visibility),
+		calls:         make(map[Op]int),
+		onCall:        make(map[Op]map[int]error),
+		forID:         make(map[Op]map[string]error),
+	}
+}
+
+// FailOnCall makes the nth call to op, counting from 1 and including calls
+// already made, return err, or ErrInjected if err is nil. A failed call
+// has no effect on the queue.
+func (b *FakeBackend) FailOnCall(op Op, n int, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.onCall[op] == nil {
+		b.onCall[op] = make(map[int]error)
+	}
+	b.onCall[op][n] = orInjected(err)
+}
+
+// FailForID makes every call to op for message id return err, or
+// ErrInjected if err is nil, until ClearFailures. A Dequeue that fails
+// this way has still taken the message, as a consumer that crashes mid-
+// delivery would: it is redelivered once its visibility timeout runs out.
+func (b *FakeBackend) FailForID(op Op, id string, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.forID[op] == nil {
+		b.forID[op] = make(map[string]error)
+	}
+	b.forID[op][id] = orInjected(err)
+}
+
+// ClearFailures drops every failure injected so far.
+func (b *FakeBackend) ClearFailures() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	clear(b.onCall)
+	clear(b.forID)
+}
+
+// SetLatency delays every call by d before it does anything, or until its
+// ctx is done.
+func (b *FakeBackend) SetLatency(d time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.latency = d
+}
+
+// Calls reports how many times op has been called, failed calls included.
+func (b *FakeBackend) Calls(op Op) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.calls[op]
+}
+
+// AssertCalls fails t unless op has been called exactly want times.
+func (b *FakeBackend) AssertCalls(t testing.TB, op Op, want int) {
+	t.Helper()
+	if got := b.Calls(op); got != want {
+		t.Errorf("%s called %d times, want %d", op, got, want)
+	}
+}
+
+// Enqueue appends msg to the back of the queue unless a failure is
+// injected for it.
+func (b *FakeBackend) Enqueue(ctx context.Context, msg Message) error {
+	if err := b.begin(ctx, OpEnqueue, msg.ID); err != nil {
+		return err
+	}
+	return b.MemoryBackend.Enqueue(ctx, msg)
+}
+
+// Dequeue hands out the oldest visible message unless a failure is
+// injected for the call or for the message it takes.
+func (b *FakeBackend) Dequeue(ctx context.Context) (Message, error) {
+	if err := b.begin(ctx, OpDequeue, ""); err != nil {
+		return Message{}, err
+	}
+	msg, err := b.MemoryBackend.Dequeue(ctx)
+	if err != nil {
+		return Message{}, err
+	}
+	if err := b.failureForID(OpDequeue, msg.ID); err != nil {
+		return Message{}, err
+	}
+	return msg, nil
+}
+
+// Ack drops an in-flight message unless a failure is injected for it.
+func (b *FakeBackend) Ack(ctx context.Context, id string) error {
+	if err := b.begin(ctx, OpAck, id); err != nil {
+		return err
+	}
+	return b.MemoryBackend.Ack(ctx, id)
+}
+
+// begin counts a call to op, waits out the latency and returns the failure
+// injected for it, if any. id is the message the call is for, or empty
+// for a Dequeue, which doesn't know yet.
+func (b *FakeBackend) begin(ctx context.Context, op Op, id string) error {
+	b.mu.Lock()
+	b.calls[op]++
+	n, latency := b.calls[op], b.latency
+	err := b.onCall[op][n]
+	b.mu.Unlock()
+
+	if latency > 0 {
+		timer := time.NewTimer(latency)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("%s call %d: %w", op, n, err)
+	}
+	if id == "" {
+		return nil
+	}
+	return b.failureForID(op, id)
+}
+
+func (b *FakeBackend) failureForID(op Op, id string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if err := b.forID[op][id]; err != nil {
+		return fmt.Errorf("%s %s: %w", op, id, err)
+	}
+	return nil
+}
+
+func orInjected(err error) error {
+	if err == nil {
+		return ErrInjected
+	}
+	return err
+}
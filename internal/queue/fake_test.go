@@ -0,0 +1,64 @@
+package queue1_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	queue1 "github.com/rajatx185/golang-scalable-background-job-system/internal/queue"
+)
+
+func TestFakeBackendInjectsFailures(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	b := queue1.NewFakeBackend(20 * time.Millisecond)
+	down := errors.New("redis down")
+	b.FailOnCall(queue1.OpEnqueue, 1, down)
+	b.FailForID(queue1.OpAck, "job-2", nil)
+
+	if err := b.Enqueue(ctx, queue1.Message{ID: "job-1"}); !errors.Is(err, down) {
+		t.Fatalf("first Enqueue = %v, want %v", err, down)
+	}
+	for _, id := range []string{"job-1", "job-2"} {
+		if err := b.Enqueue(ctx, queue1.Message{ID: id}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, want := range []string{"job-1", "job-2"} {
+		msg, err := b.Dequeue(ctx)
+		if err != nil || msg.ID != want {
+			t.Fatalf("Dequeue = %+v, %v, want %s", msg, err, want)
+		}
+		err = b.Ack(ctx, msg.ID)
+		if wantErr := want == "job-2"; wantErr != errors.Is(err, queue1.ErrInjected) {
+			t.Fatalf("Ack(%s) = %v", msg.ID, err)
+		}
+	}
+
+	// The failed ack leaves job-2 in flight, so it comes back.
+	b.ClearFailures()
+	msg, err := b.Dequeue(ctx)
+	if err != nil || msg.ID != "job-2" || msg.Deliveries != 2 {
+		t.Fatalf("Dequeue = %+v, %v, want job-2 redelivered", msg, err)
+	}
+	if err := b.Ack(ctx, msg.ID); err != nil {
+		t.Fatal(err)
+	}
+	b.AssertCalls(t, queue1.OpEnqueue, 3)
+	b.AssertCalls(t, queue1.OpDequeue, 3)
+	b.AssertCalls(t, queue1.OpAck, 3)
+}
+
+func TestFakeBackendLatencyHonoursContext(t *testing.T) {
+	b := queue1.NewFakeBackend(time.Minute)
+	b.SetLatency(time.Hour)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := b.Enqueue(ctx, queue1.Message{ID: "job-1"}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Enqueue = %v, want DeadlineExceeded", err)
+	}
+	if n := b.Len(); n != 0 {
+		t.Fatalf("Len = %d after a timed-out Enqueue, want 0", n)
+	}
+}
@@ -1,3 +1,121 @@
 package queue1
 
-func FromMemory() {}
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryBackend is an in-process QueueBackend. It has the same redelivery
+// semantics as the persistent backends, which makes it the backend of
+// choice for tests, but loses everything when the process exits.
+type MemoryBackend struct {
+	visibility time.Duration
+
+	mu       sync.Mutex
+	ready    []Message
+	inflight map[string]inflightMessage
+	notify   chan struct{}
+}
+
+type inflightMessage struct {
+	msg      Message
+	deadline time.Time
+}
+
+// NewMemoryBackend returns an empty backend that redelivers any message not
+// acked within visibility of being dequeued.
+func NewMemoryBackend(visibility time.Duration) *MemoryBackend {
+	return &MemoryBackend{
+		visibility: visibility,
+		inflight:   make(map[string]inflightMessage),
+		notify:     make(chan struct{}, 1),
+	}
+}
+
+// Enqueue appends msg to the back of the queue.
+func (b *MemoryBackend) Enqueue(ctx context.Context, msg Message) error {
+	b.mu.Lock()
+	b.ready = append(b.ready, msg)
+	b.mu.Unlock()
+	b.wake()
+	return nil
+}
+
+// Dequeue hands out the oldest visible message, waiting for one if needed.
+func (b *MemoryBackend) Dequeue(ctx context.Context) (Message, error) {
+	for {
+		msg, ok, wait := b.tryDequeue(time.Now())
+		if ok {
+			return msg, nil
+		}
+
+		var timer <-chan time.Time
+		if wait > 0 {
+			timer = time.After(wait)
+		}
+		select {
+		case <-ctx.Done():
+			return Message{}, ctx.Err()
+		case <-b.notify:
+		case <-timer:
+		}
+	}
+}
+
+// tryDequeue returns the next message if there is one; otherwise it reports
+// how long until the earliest in-flight message expires (zero if none is in
+// flight).
+func (b *MemoryBackend) tryDequeue(now time.Time) (Message, bool, time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var wait time.Duration
+	for id, in := range b.inflight {
+		if !now.Before(in.deadline) {
+			delete(b.inflight, id)
+			b.ready = append(b.ready, in.msg)
+		} else if left := in.deadline.Sub(now); wait == 0 || left < wait {
+			wait = left
+		}
+	}
+	if len(b.ready) == 0 {
+		return Message{}, false, wait
+	}
+
+	msg := b.ready[0]
+	b.ready = b.ready[1:]
+	msg.Deliveries++
+	b.inflight[msg.ID] = inflightMessage{msg: msg, deadline: now.Add(b.visibility)}
+	return msg, true, 0
+}
+
+// Ack drops an in-flight message. A consumer that overran its visibility
+// timeout gets ErrUnknownMessage if the message has gone back to waiting,
+// and it is delivered again. If another consumer has already taken the
+// redelivered copy, acking by ID drops that copy instead, and the other
+// consumer's own Ack gets ErrUnknownMessage. Handlers must be idempotent
+// either way.
+func (b *MemoryBackend) Ack(ctx context.Context, id string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.inflight[id]; !ok {
+		return ErrUnknownMessage
+	}
+	delete(b.inflight, id)
+	return nil
+}
+
+// Len reports how many messages are waiting or in flight.
+func (b *MemoryBackend) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.ready) + len(b.inflight)
+}
+
+func (b *MemoryBackend) wake() {
+	select {
+	case b.notify <- struct{}{}:
+	default:
+	}
+}
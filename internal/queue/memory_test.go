@@ -0,0 +1,39 @@
+package queue1_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	queue1 "github.com/rajatx185/golang-scalable-background-job-system/internal/queue"
+)
+
+func TestMemoryBackendRedeliversUnacked(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	b := queue1.NewMemoryBackend(20 * time.Millisecond)
+
+	if err := b.Enqueue(ctx, queue1.Message{ID: "job-1", Body: []byte("payload")}); err != nil {
+		t.Fatal(err)
+	}
+	first, err := b.Dequeue(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Simulate a crash: the consumer goes away without acking.
+
+	second, err := b.Dequeue(ctx)
+	if err != nil {
+		t.Fatalf("message was not redelivered: %v", err)
+	}
+	if second.ID != first.ID || second.Deliveries != 2 {
+		t.Fatalf("got %+v, want %s on delivery 2", second, first.ID)
+	}
+
+	if err := b.Ack(ctx, second.ID); err != nil {
+		t.Fatal(err)
+	}
+	if n := b.Len(); n != 0 {
+		t.Fatalf("Len after ack = %d, want 0", n)
+	}
+}
@@ -0,0 +1,50 @@
+// Package scheduler runs work on a recurring cadence.
+package scheduler
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/rajatx185/golang-scalable-background-job-system/internal/clock"
+)
+
+// Every calls fn on each tick of interval until ctx is done, then returns
+// the number of ticks that were dropped.
+//
+// A time.Ticker's channel holds a single tick, so when fn runs longer than
+// interval the ticks that fire meanwhile are discarded rather than queued.
+// Every detects the gap between consecutive ticks it receives and logs how
+// many were skipped, so a slow consumer shows up instead of silently
+// running at a lower rate.
+func Every(ctx context.Context, interval time.Duration, fn func(ctx context.Context, tick time.Time)) int {
+	return EveryOn(ctx, clock.Real(), interval, fn)
+}
+
+// EveryOn is Every driven by clk, so tests can tick with a clock.Manual:
+// its Tick fires the next tick straight away, with nothing sleeping for
+// the interval. Tick exists only on the Manual clock; under a real one the
+// ticks come when they come.
+func EveryOn(ctx context.Context, clk clock.Clock, interval time.Duration, fn func(ctx context.Context, tick time.Time)) int {
+	ticker := clk.NewTicker(interval)
+	defer ticker.Stop()
+
+	dropped := 0
+	var last time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return dropped
+		case tick := <-ticker.C():
+			if !last.IsZero() {
+				if missed := int(tick.Sub(last)/interval) - 1; missed > 0 {
+					dropped += missed
+					slog.Warn("scheduler: consumer slower than tick interval",
+						"interval", interval, "dropped", missed, "dropped_total", dropped)
+				}
+			}
+			last = tick
+			fn(ctx, tick)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package scheduler_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rajatx185/golang-scalable-background-job-system/internal/clock"
+	"github.com/rajatx185/golang-scalable-background-job-system/internal/scheduler"
+)
+
+func TestEveryStopsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	ticks := 0
+	done := make(chan int)
+	go func() {
+		done <- scheduler.Every(ctx, 5*time.Millisecond, func(ctx context.Context, _ time.Time) {
+			if ticks++; ticks == 3 {
+				cancel()
+			}
+		})
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Every did not return after cancel")
+	}
+	if ticks != 3 {
+		t.Fatalf("fn ran %d times, want 3", ticks)
+	}
+}
+
+func TestEveryCountsDroppedTicks(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	clk := clock.NewManual(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	ticks := make(chan time.Time)
+	resume := make(chan struct{})
+	done := make(chan int)
+	go func() {
+		done <- scheduler.EveryOn(ctx, clk, time.Minute, func(ctx context.Context, tick time.Time) {
+			ticks <- tick
+			<-resume
+		})
+	}()
+
+	clk.BlockUntil(1)
+	clk.Advance(time.Minute)
+	<-ticks
+	// fn is busy while the next four ticks come due; the ticker keeps the
+	// first and drops the rest.
+	clk.Advance(4 * time.Minute)
+	resume <- struct{}{}
+	<-ticks
+	clk.Advance(time.Minute)
+	resume <- struct{}{}
+	if tick := <-ticks; tick.Minute() != 6 {
+		t.Fatalf("third tick at minute %d, want 6", tick.Minute())
+	}
+	close(resume)
+	cancel()
+	if dropped := <-done; dropped != 3 {
+		t.Fatalf("dropped = %d, want 3", dropped)
+	}
+}
+
+func TestEveryOnRunsOnManualTick(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clk := clock.NewManual(start)
+	ticks := make(chan time.Time)
+	done := make(chan int)
+	go func() {
+		done <- scheduler.EveryOn(ctx, clk, time.Hour, func(ctx context.Context, tick time.Time) { ticks <- tick })
+	}()
+
+	clk.BlockUntil(1)
+	for i := 1; i <= 3; i++ {
+		clk.Tick()
+		if tick := <-ticks; !tick.Equal(start.Add(time.Duration(i) * time.Hour)) {
+			t.Fatalf("tick %d at %v, want %v", i, tick, start.Add(time.Duration(i)*time.Hour))
+		}
+	}
+	cancel()
+	if dropped := <-done; dropped != 0 {
+		t.Fatalf("dropped = %d, want 0", dropped)
+	}
+}
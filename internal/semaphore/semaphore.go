@@ -0,0 +1,62 @@
+// Package semaphore provides a counting semaphore for capping how many
+// goroutines do something at once.
+package semaphore
+
+import "context"
+
+// Semaphore admits up to its capacity holders at a time. It is a buffered
+// channel with one slot per holder: Acquire fills a slot and Release
+// empties one, so waiters are admitted in no particular order. For
+// weighted or first-come-first-served admission, build on something else.
+type Semaphore struct {
+	slots chan struct{}
+}
+
+// New returns a Semaphore that admits n holders at once. n must be
+// positive.
+func New(n int) *Semaphore {
+	if n <= 0 {
+		panic("semaphore: capacity must be positive")
+	}
+	return &Semaphore{slots: make(chan struct{}, n)}
+}
+
+// Acquire takes a slot, waiting for one to free up or for ctx to be done,
+// in which case it returns ctx.Err() without holding one. A ctx that is
+// already done always fails, even if a slot is free.
+func (s *Semaphore) Acquire(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	select {
+	case s.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// TryAcquire takes a slot if one is free right now and reports whether it
+// did.
+func (s *Semaphore) TryAcquire() bool {
+	select {
+	case s.slots <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+// Release gives back a slot taken by Acquire or TryAcquire. Releasing more
+// than was acquired is a bug in the caller, so it panics rather than
+// silently raising the limit.
+func (s *Semaphore) Release() {
+	select {
+	case <-s.slots:
+	default:
+		panic("semaphore: Release without a matching Acquire")
+	}
+}
+
+// Held reports how many slots are taken.
+func (s *Semaphore) Held() int { return len(s.slots) }
@@ -0,0 +1,64 @@
+package semaphore_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rajatx185/golang-scalable-background-job-system/internal/semaphore"
+)
+
+func TestAcquireRelease(t *testing.T) {
+	s := semaphore.New(2)
+	if err := s.Acquire(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if !s.TryAcquire() {
+		t.Fatal("TryAcquire failed with a slot free")
+	}
+	if s.TryAcquire() {
+		t.Fatal("TryAcquire succeeded on a full semaphore")
+	}
+	if n := s.Held(); n != 2 {
+		t.Fatalf("Held = %d, want 2", n)
+	}
+	s.Release()
+	if !s.TryAcquire() {
+		t.Fatal("TryAcquire failed after a Release")
+	}
+}
+
+func TestAcquireHonoursContext(t *testing.T) {
+	s := semaphore.New(1)
+	s.TryAcquire()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+	}()
+	if err := s.Acquire(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Acquire on a full semaphore = %v, want context.Canceled", err)
+	}
+	if n := s.Held(); n != 1 {
+		t.Fatalf("Held = %d after a cancelled Acquire, want 1", n)
+	}
+
+	s.Release()
+	if err := s.Acquire(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Acquire with a done ctx = %v, want context.Canceled even with room", err)
+	}
+}
+
+func TestOverReleasePanics(t *testing.T) {
+	s := semaphore.New(1)
+	s.TryAcquire()
+	s.Release()
+	defer func() {
+		if recover() == nil {
+			t.Fatal("Release without a matching Acquire did not panic")
+		}
+	}()
+	s.Release()
+}
@@ -0,0 +1,165 @@
+// Package shutdown tears a process's components down in a fixed order, and
+// has them reload their configuration on SIGHUP until then.
+package shutdown
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"slices"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// StopFunc stops one component. It should return once the component has
+// stopped or ctx, which carries the component's timeout, is done.
+type StopFunc func(ctx context.Context) error
+
+// Manager stops registered components one at a time, lowest priority
+// first, so each can rely on the ones stopped before it: stop accepting
+// requests, then drain the workers, then stop background sweepers, then
+// close the stores they write to.
+type Manager struct {
+	mu      sync.Mutex
+	steps   []step
+	reloads []reload
+	done    bool
+	logger  *slog.Logger
+}
+
+type reload struct {
+	name string
+	fn   func() error
+}
+
+type step struct {
+	name     string
+	priority int
+	timeout  time.Duration
+	stop     StopFunc
+}
+
+// New returns a Manager that logs each step to logger, or to slog.Default
+// if logger is nil.
+func New(logger *slog.Logger) *Manager {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Manager{logger: logger}
+}
+
+// Register adds a component to stop at priority. Components with the same
+// priority stop in the order they were registered. A timeout of zero
+// gives the component no deadline of its own.
+func (m *Manager) Register(name string, priority int, timeout time.Duration, stop StopFunc) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.steps = append(m.steps, step{name: name, priority: priority, timeout: timeout, stop: stop})
+}
+
+// OnReload adds a component to reload on SIGHUP while Run waits.
+// Components reload in the order they were registered.
+func (m *Manager) OnReload(name string, fn func() error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.reloads = append(m.reloads, reload{name: name, fn: fn})
+}
+
+// Run waits for SIGINT or SIGTERM, or for ctx to be done, then runs
+// Shutdown. Each SIGHUP in the meantime runs Reload.
+func (m *Manager) Run(ctx context.Context) error {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	for ctx.Err() == nil {
+		select {
+		case <-ctx.Done():
+		case <-hup:
+			m.logger.Info("reloading")
+			m.Reload()
+		}
+	}
+	signal.Stop(hup)
+	stop()
+	m.logger.Info("shutting down")
+	return m.Shutdown(context.Background())
+}
+
+// Reload reloads every component registered with OnReload and returns
+// their errors joined. A component that fails is logged and the rest
+// still reload; it is up to each to keep its old settings when it can't
+// take the new ones.
+func (m *Manager) Reload() error {
+	m.mu.Lock()
+	reloads := slices.Clone(m.reloads)
+	m.mu.Unlock()
+
+	var errs []error
+	for _, r := range reloads {
+		if err := r.fn(); err != nil {
+			m.logger.Error("component did not reload", "component", r.name, "err", err)
+			errs = append(errs, fmt.Errorf("reload: %s: %w", r.name, err))
+			continue
+		}
+		m.logger.Info("component reloaded", "component", r.name)
+	}
+	return errors.Join(errs...)
+}
+
+// Shutdown stops every component in order and returns their errors
+// joined. A component that fails, or overruns its timeout, is logged and
+// skipped over rather than holding up the rest: Shutdown stops waiting for
+// it and moves on. ctx bounds the whole sequence; once it is done, the
+// components not yet reached get an already-expired context. Only the
+// first call does anything.
+func (m *Manager) Shutdown(ctx context.Context) error {
+	m.mu.Lock()
+	if m.done {
+		m.mu.Unlock()
+		return nil
+	}
+	m.done = true
+	steps := slices.Clone(m.steps)
+	m.mu.Unlock()
+
+	slices.SortStableFunc(steps, func(a, b step) int { return cmp.Compare(a.priority, b.priority) })
+	var errs []error
+	for _, s := range steps {
+		start := time.Now()
+		if err := s.run(ctx); err != nil {
+			m.logger.Error("component did not stop cleanly", "component", s.name, "took", time.Since(start), "err", err)
+			errs = append(errs, fmt.Errorf("shutdown: %s: %w", s.name, err))
+			continue
+		}
+		m.logger.Info("component stopped", "component", s.name, "took", time.Since(start))
+	}
+	return errors.Join(errs...)
+}
+
+// run calls s.stop on its own goroutine, so one that ignores its context
+// can't stall the components after it.
+func (s step) run(ctx context.Context) error {
+	if s.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.timeout)
+		defer cancel()
+	}
+	done := make(chan error, 1)
+	go func() { done <- s.stop(ctx) }()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		select {
+		case err := <-done:
+			return err // it made it after all
+		default:
+			return ctx.Err()
+		}
+	}
+}
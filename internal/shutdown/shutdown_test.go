@@ -0,0 +1,83 @@
+package shutdown_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/rajatx185/golang-scalable-background-job-system/internal/shutdown"
+)
+
+func TestShutdownOrder(t *testing.T) {
+	m := shutdown.New(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	var order []string
+	record := func(name string) shutdown.StopFunc {
+		return func(context.Context) error {
+			order = append(order, name)
+			return nil
+		}
+	}
+	m.Register("store", 30, 0, record("store"))
+	m.Register("http", 0, 0, record("http"))
+	m.Register("workers", 10, 0, record("workers"))
+	m.Register("sweeper", 20, 0, record("sweeper"))
+	m.Register("scheduler", 20, 0, record("scheduler"))
+
+	if err := m.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"http", "workers", "sweeper", "scheduler", "store"}
+	if !slices.Equal(order, want) {
+		t.Fatalf("stopped %v, want %v", order, want)
+	}
+	if err := m.Shutdown(context.Background()); err != nil || len(order) != len(want) {
+		t.Fatalf("second Shutdown ran components again: %v, %v", order, err)
+	}
+}
+
+func TestShutdownMovesPastFailures(t *testing.T) {
+	m := shutdown.New(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	boom := errors.New("boom")
+	var reached bool
+	m.Register("broken", 0, 0, func(context.Context) error { return boom })
+	m.Register("stuck", 1, 10*time.Millisecond, func(context.Context) error {
+		select {} // ignores its context
+	})
+	m.Register("last", 2, 0, func(context.Context) error {
+		reached = true
+		return nil
+	})
+
+	err := m.Shutdown(context.Background())
+	if !errors.Is(err, boom) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Shutdown = %v, want both the failure and the timeout", err)
+	}
+	if !reached {
+		t.Fatal("components after a failed one were not stopped")
+	}
+}
+
+func TestReloadRunsEveryComponent(t *testing.T) {
+	m := shutdown.New(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	boom := errors.New("bad config")
+	var order []string
+	m.OnReload("pool", func() error {
+		order = append(order, "pool")
+		return boom
+	})
+	m.OnReload("limits", func() error {
+		order = append(order, "limits")
+		return nil
+	})
+
+	if err := m.Reload(); !errors.Is(err, boom) {
+		t.Fatalf("Reload = %v, want the pool's failure", err)
+	}
+	if want := []string{"pool", "limits"}; !slices.Equal(order, want) {
+		t.Fatalf("reloaded %v, want %v", order, want)
+	}
+}
@@ -0,0 +1,223 @@
+// Package store provides a concurrency-safe key/value map for state shared
+// between the API and the workers.
+package store
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/rajatx185/golang-scalable-background-job-system/internal/clock"
+)
+
+// CompositeStore is a map guarded by a sync.RWMutex: readers proceed in
+// parallel and only writers take the lock exclusively, which suits the
+// read-heavy status lookups it backs. K can be any comparable type, so a
+// key made of several parts can be a struct rather than a concatenated
+// string whose parts might contain the delimiter.
+//
+// Keys set with SetWithTTL expire: once their TTL has passed they read as
+// absent, and Sweep reclaims them, or the sweeper of a store made with
+// NewStoreWithSweeper.
+type CompositeStore[K comparable, V any] struct {
+	mu    sync.RWMutex
+	data  map[K]entry[V]
+	clock clock.Clock
+
+	// ctx is the sweeper's context; nil for a store without one.
+	ctx    context.Context
+	cancel context.CancelFunc
+	swept  chan struct{} // closed once the sweeper has exited
+	closed bool
+}
+
+type entry[V any] struct {
+	v       V
+	expires time.Time // zero for keys that never expire
+}
+
+func (e entry[V]) live(now time.Time) bool {
+	return e.expires.IsZero() || now.Before(e.expires)
+}
+
+// Store is the CompositeStore with string keys.
+type Store[V any] = CompositeStore[string, V]
+
+// NewStore returns an empty store.
+func NewStore[V any]() *Store[V] {
+	return NewStoreWithClock[V](clock.Real())
+}
+
+// NewStoreWithClock returns an empty store that measures TTLs on clk.
+func NewStoreWithClock[V any](clk clock.Clock) *Store[V] {
+	return NewCompositeStoreWithClock[string, V](clk)
+}
+
+// NewCompositeStore returns an empty store keyed by K.
+func NewCompositeStore[K comparable, V any]() *CompositeStore[K, V] {
+	return NewCompositeStoreWithClock[K, V](clock.Real())
+}
+
+// NewCompositeStoreWithClock returns an empty store keyed by K that
+// measures TTLs on clk.
+func NewCompositeStoreWithClock[K comparable, V any](clk clock.Clock) *CompositeStore[K, V] {
+	return &CompositeStore[K, V]{data: make(map[K]entry[V]), clock: clk}
+}
+
+// NewStoreWithSweeper returns an empty store that measures TTLs on clk and
+// sweeps expired keys every interval until ctx is done or Close is called.
+// From then on the store stays readable but ignores writes, so nothing can
+// pile up in a store nobody sweeps any more.
+func NewStoreWithSweeper[V any](ctx context.Context, clk clock.Clock, interval time.Duration) *Store[V] {
+	s := NewStoreWithClock[V](clk)
+	s.ctx, s.cancel = context.WithCancel(ctx)
+	s.swept = make(chan struct{})
+	ticker := clk.NewTicker(interval)
+	go func() {
+		defer close(s.swept)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-s.ctx.Done():
+				return
+			case <-ticker.C():
+				s.Sweep()
+			}
+		}
+	}()
+	return s
+}
+
+// Close stops the sweeper, if the store has one, and waits for it to
+// exit. The store ignores writes from then on.
+func (s *CompositeStore[K, V]) Close() {
+	s.mu.Lock()
+	s.closed = true
+	s.mu.Unlock()
+	if s.cancel != nil {
+		s.cancel()
+		<-s.swept
+	}
+}
+
+// closedLocked reports whether the store has stopped taking writes.
+func (s *CompositeStore[K, V]) closedLocked() bool {
+	return s.closed || (s.ctx != nil && s.ctx.Err() != nil)
+}
+
+// Get returns the value stored under key.
+func (s *CompositeStore[K, V]) Get(key K) (V, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	e, ok := s.data[key]
+	if !ok || !e.live(s.clock.Now()) {
+		var zero V
+		return zero, false
+	}
+	return e.v, true
+}
+
+// GetContext is Get for request-scoped reads: it returns ctx.Err() without
+// reading if ctx is already done. Store's reads only ever wait behind a
+// writer's short critical section, and a sync.RWMutex can't be abandoned
+// half-way, so ctx is checked once, up front; a store whose reads could
+// wait longer would also watch ctx while waiting.
+func (s *CompositeStore[K, V]) GetContext(ctx context.Context, key K) (V, bool, error) {
+	if err := ctx.Err(); err != nil {
+		var zero V
+		return zero, false, err
+	}
+	v, ok := s.Get(key)
+	return v, ok, nil
+}
+
+// Set stores v under key, replacing any previous value.
+func (s *CompositeStore[K, V]) Set(key K, v V) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closedLocked() {
+		return
+	}
+	s.data[key] = entry[V]{v: v}
+}
+
+// SetWithTTL stores v under key, replacing any previous value, until ttl
+// has passed.
+func (s *CompositeStore[K, V]) SetWithTTL(key K, v V, ttl time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closedLocked() {
+		return
+	}
+	s.data[key] = entry[V]{v: v, expires: s.clock.Now().Add(ttl)}
+}
+
+// Delete removes key.
+func (s *CompositeStore[K, V]) Delete(key K) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.data, key)
+}
+
+// GetOrSet returns the existing value for key if present. Otherwise it
+// stores and returns v. loaded reports whether the value was already there.
+func (s *CompositeStore[K, V]) GetOrSet(key K, v V) (actual V, loaded bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if existing, ok := s.data[key]; ok && existing.live(s.clock.Now()) {
+		return existing.v, true
+	}
+	if s.closedLocked() {
+		return v, false
+	}
+	s.data[key] = entry[V]{v: v}
+	return v, false
+}
+
+// Len reports the number of keys, counting expired ones Sweep has not
+// reclaimed yet.
+func (s *CompositeStore[K, V]) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.data)
+}
+
+// Range calls f for each live key and its value until f returns false.
+// It copies the keys under the read lock and releases it before calling
+// f, so f may read or write the store without deadlocking; each value is
+// re-read just before f sees it. Entries can therefore change between the
+// copy and the callback: a key deleted or expired in between is skipped,
+// one updated is seen with its new value, and one added is not visited.
+func (s *CompositeStore[K, V]) Range(f func(key K, v V) bool) {
+	s.mu.RLock()
+	keys := make([]K, 0, len(s.data))
+	for key := range s.data {
+		keys = append(keys, key)
+	}
+	s.mu.RUnlock()
+	for _, key := range keys {
+		v, ok := s.Get(key)
+		if !ok {
+			continue
+		}
+		if !f(key, v) {
+			return
+		}
+	}
+}
+
+// Sweep deletes the keys whose TTL has passed and returns how many it
+// removed.
+func (s *CompositeStore[K, V]) Sweep() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.clock.Now()
+	n := 0
+	for key, e := range s.data {
+		if !e.live(now) {
+			delete(s.data, key)
+			n++
+		}
+	}
+	return n
+}
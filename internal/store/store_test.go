@@ -0,0 +1,139 @@
+package store_test
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/rajatx185/golang-scalable-background-job-system/internal/clock"
+	"github.com/rajatx185/golang-scalable-background-job-system/internal/store"
+)
+
+func TestSetWithTTL(t *testing.T) {
+	clk := clock.NewManual(time.Unix(0, 0))
+	s := store.NewStoreWithClock[string](clk)
+	s.Set("forever", "a")
+	s.SetWithTTL("brief", "b", time.Minute)
+
+	clk.Advance(59 * time.Second)
+	if v, ok := s.Get("brief"); !ok || v != "b" {
+		t.Fatalf("Get before expiry = %q, %v", v, ok)
+	}
+	clk.Advance(time.Second)
+	if _, ok := s.Get("brief"); ok {
+		t.Fatal("key still readable once its TTL passed")
+	}
+	if v, loaded := s.GetOrSet("brief", "c"); loaded || v != "c" {
+		t.Fatalf("GetOrSet on expired key = %q, loaded %v; want it replaced", v, loaded)
+	}
+
+	s.SetWithTTL("brief", "d", time.Second)
+	clk.Advance(time.Second)
+	if n := s.Sweep(); n != 1 || s.Len() != 1 {
+		t.Fatalf("Sweep removed %d, %d keys left; want 1 and 1", n, s.Len())
+	}
+	if v, ok := s.Get("forever"); !ok || v != "a" {
+		t.Fatalf("key without TTL = %q, %v after Sweep", v, ok)
+	}
+}
+
+func TestGetContext(t *testing.T) {
+	s := store.NewStore[string]()
+	s.Set("k", "v")
+	if v, ok, err := s.GetContext(context.Background(), "k"); err != nil || !ok || v != "v" {
+		t.Fatalf("GetContext = %q, %v, %v", v, ok, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if v, ok, err := s.GetContext(ctx, "k"); !errors.Is(err, context.Canceled) || ok || v != "" {
+		t.Fatalf("GetContext with a done ctx = %q, %v, %v; want context.Canceled", v, ok, err)
+	}
+}
+
+func TestSweeperStopsWithoutLeaking(t *testing.T) {
+	clk := clock.NewManual(time.Unix(0, 0))
+	before := runtime.NumGoroutine()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancelled := store.NewStoreWithSweeper[string](ctx, clk, time.Minute)
+	closed := store.NewStoreWithSweeper[string](context.Background(), clk, time.Minute)
+	closed.SetWithTTL("brief", "b", time.Second)
+	clk.Advance(time.Minute)
+	deadline := time.Now().Add(time.Second)
+	for closed.Len() != 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if n := closed.Len(); n != 0 {
+		t.Fatalf("Len = %d after a sweep interval, want the expired key swept", n)
+	}
+
+	cancel()
+	closed.Close()
+	for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if n := runtime.NumGoroutine(); n > before {
+		t.Fatalf("%d goroutines after stopping both sweepers, want %d", n, before)
+	}
+
+	for _, s := range []*store.Store[string]{cancelled, closed} {
+		s.Set("k", "v")
+		if _, ok := s.Get("k"); ok {
+			t.Fatal("a stopped store accepted a write")
+		}
+	}
+}
+
+func TestCompositeKeys(t *testing.T) {
+	type key struct{ tenant, entity string }
+	s := store.NewCompositeStore[key, int]()
+	// Concatenated with a ":" these two would collide as "a:b:c".
+	s.Set(key{"a:b", "c"}, 1)
+	s.Set(key{"a", "b:c"}, 2)
+
+	if v, ok := s.Get(key{"a:b", "c"}); !ok || v != 1 {
+		t.Fatalf("Get = %d, %v; want 1", v, ok)
+	}
+	if v, loaded := s.GetOrSet(key{"a", "b:c"}, 3); !loaded || v != 2 {
+		t.Fatalf("GetOrSet = %d, loaded %v; want the existing 2", v, loaded)
+	}
+	s.Delete(key{"a:b", "c"})
+	if _, ok := s.Get(key{"a:b", "c"}); ok || s.Len() != 1 {
+		t.Fatalf("after Delete: %d keys left, deleted key readable %v; want 1, false", s.Len(), ok)
+	}
+}
+
+func TestRangeAllowsWrites(t *testing.T) {
+	clk := clock.NewManual(time.Unix(0, 0))
+	s := store.NewStoreWithClock[int](clk)
+	s.Set("a", 1)
+	s.Set("b", 2)
+	s.SetWithTTL("gone", 3, time.Minute)
+	clk.Advance(time.Minute)
+
+	seen := map[string]int{}
+	s.Range(func(key string, v int) bool {
+		seen[key] = v
+		s.Set(key, v*10) // would deadlock under a held read lock
+		s.Set("added-"+key, v)
+		return true
+	})
+	if len(seen) != 2 || seen["a"] != 1 || seen["b"] != 2 {
+		t.Fatalf("Range saw %v, want a=1 b=2 and not the expired key or keys added mid-range", seen)
+	}
+	if v, _ := s.Get("a"); v != 10 {
+		t.Fatalf("a = %d after Range wrote it, want 10", v)
+	}
+
+	n := 0
+	s.Range(func(string, int) bool {
+		n++
+		return false
+	})
+	if n != 1 {
+		t.Fatalf("Range called f %d times after it returned false, want 1", n)
+	}
+}
@@ -0,0 +1,203 @@
+// Package webhook notifies an HTTP endpoint when jobs finish, so clients
+// don't have to poll for status.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/rajatx185/golang-scalable-background-job-system/internal/httpclient"
+	"github.com/rajatx185/golang-scalable-background-job-system/internal/worker"
+)
+
+// Payload is the JSON body POSTed for every finished job.
+type Payload struct {
+	JobID  string `json:"job_id"`
+	Status string `json:"status"` // "succeeded" or "failed"
+	Result string `json:"result,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Config controls where and how persistently a Notifier delivers.
+type Config struct {
+	// URL receives a POST per finished job.
+	URL string
+	// Client sends the requests. Defaults to httpclient.Default.
+	Client *http.Client
+	// Timeout bounds each delivery attempt. Defaults to 5s.
+	Timeout time.Duration
+	// MaxAttempts is how many times a delivery is tried before it is
+	// dead-lettered. Defaults to 3.
+	MaxAttempts int
+	// Backoff is the wait before the first retry; it doubles after each
+	// failed attempt. Defaults to 500ms.
+	Backoff time.Duration
+	// Buffer is how many notifications may wait for delivery. Once it is
+	// full, new ones are dead-lettered immediately. Defaults to 100.
+	Buffer int
+	// Logger receives the dead-letter log: one error record per
+	// notification that could not be delivered. Defaults to slog.Default.
+	Logger *slog.Logger
+}
+
+// Notifier is a worker event subscriber that POSTs a Payload to Config.URL
+// whenever a job reaches a terminal state. Deliveries happen on the
+// Notifier's own goroutine, so retries never hold up the pool's event bus.
+type Notifier struct {
+	cfg     Config
+	pending chan notification
+	done    chan struct{}
+	mu      sync.Mutex
+	closed  bool
+}
+
+// notification is a Payload waiting for delivery, with the context of the
+// event it came from, which bounds the delivery.
+type notification struct {
+	ctx context.Context
+	p   Payload
+}
+
+// New returns a Notifier and starts its delivery goroutine. Register it
+// with pool.Subscribe(n.Handle), and Close it after the pool.
+func New(cfg Config) *Notifier {
+	if cfg.Client == nil {
+		cfg.Client = httpclient.Default()
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 5 * time.Second
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 3
+	}
+	if cfg.Backoff <= 0 {
+		cfg.Backoff = 500 * time.Millisecond
+	}
+	if cfg.Buffer <= 0 {
+		cfg.Buffer = 100
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = slog.Default()
+	}
+	n := &Notifier{
+		cfg:     cfg,
+		pending: make(chan notification, cfg.Buffer),
+		done:    make(chan struct{}),
+	}
+	go n.run()
+	return n
+}
+
+// Handle queues a notification for terminal events and ignores the rest.
+// It never blocks. The delivery runs later, on the Notifier's goroutine,
+// but is still bounded by ctx: once the pool abandons its work and cancels
+// it, an in-flight POST or backoff wait is cut short and the notification
+// dead-lettered, so a slow endpoint can't hold up Close.
+func (n *Notifier) Handle(ctx context.Context, e worker.Event) {
+	p := Payload{JobID: e.Task.ID}
+	switch e.Kind {
+	case worker.EventSucceeded:
+		p.Status, p.Result = "succeeded", e.Value
+	case worker.EventFailed:
+		p.Status, p.Error = "failed", e.Err.Error()
+	default:
+		return
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.closed {
+		n.deadLetter(p, 0, errors.New("notifier closed"))
+		return
+	}
+	select {
+	case n.pending <- notification{ctx, p}:
+	default:
+		n.deadLetter(p, 0, errors.New("delivery buffer full"))
+	}
+}
+
+// Close stops accepting notifications and waits for the queued ones to be
+// delivered or dead-lettered.
+func (n *Notifier) Close() {
+	n.mu.Lock()
+	if !n.closed {
+		n.closed = true
+		close(n.pending)
+	}
+	n.mu.Unlock()
+	<-n.done
+}
+
+func (n *Notifier) run() {
+	defer close(n.done)
+	for note := range n.pending {
+		n.deliver(note.ctx, note.p)
+	}
+}
+
+// deliver POSTs p, retrying with backoff until an attempt gets a 2xx,
+// MaxAttempts run out or ctx is done.
+func (n *Notifier) deliver(ctx context.Context, p Payload) {
+	body, err := json.Marshal(p)
+	if err != nil {
+		n.deadLetter(p, 0, err)
+		return
+	}
+	backoff := n.cfg.Backoff
+	for attempt := 1; ; attempt++ {
+		err = n.post(ctx, body)
+		if err == nil {
+			return
+		}
+		if attempt == n.cfg.MaxAttempts || ctx.Err() != nil {
+			n.deadLetter(p, attempt, err)
+			return
+		}
+		n.cfg.Logger.Warn("webhook delivery failed, retrying", "job", p.JobID, "attempt", attempt, "backoff", backoff, "err", err)
+		timer := time.NewTimer(backoff)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			n.deadLetter(p, attempt, ctx.Err())
+			return
+		}
+		backoff *= 2
+	}
+}
+
+func (n *Notifier) post(ctx context.Context, body []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, n.cfg.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.cfg.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	// Drain so the connection goes back to the pool.
+	io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("webhook: %s", resp.Status)
+	}
+	return nil
+}
+
+func (n *Notifier) deadLetter(p Payload, attempts int, err error) {
+	n.cfg.Logger.Error("webhook dead-lettered", "job", p.JobID, "status", p.Status, "attempts", attempts, "err", err)
+}
@@ -0,0 +1,154 @@
+package webhook_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rajatx185/golang-scalable-background-job-system/internal/webhook"
+	"github.com/rajatx185/golang-scalable-background-job-system/internal/worker"
+)
+
+// syncBuffer is a bytes.Buffer safe for concurrent log writes.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestNotifierRetriesThenDelivers(t *testing.T) {
+	var mu sync.Mutex
+	var got []webhook.Payload
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if hits.Add(1) == 1 {
+			http.Error(w, "try later", http.StatusServiceUnavailable)
+			return
+		}
+		var p webhook.Payload
+		if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+			t.Error(err)
+		}
+		mu.Lock()
+		got = append(got, p)
+		mu.Unlock()
+	}))
+	defer srv.Close()
+
+	var logs syncBuffer
+	n := webhook.New(webhook.Config{URL: srv.URL, Backoff: time.Millisecond, Logger: slog.New(slog.NewTextHandler(&logs, nil))})
+	pool := worker.NewPool(worker.Config{}, func(ctx context.Context, t worker.Task) (string, error) {
+		if t.ID == "bad" {
+			return "", errors.New("invalid payload")
+		}
+		return "done", nil
+	})
+	pool.Subscribe(n.Handle)
+	pool.Process(context.Background(), []worker.Task{{ID: "good"}, {ID: "bad"}})
+	pool.Close()
+	n.Close()
+
+	byID := map[string]webhook.Payload{}
+	for _, p := range got {
+		byID[p.JobID] = p
+	}
+	if p := byID["good"]; p.Status != "succeeded" || p.Result != "done" {
+		t.Errorf("good job payload = %+v", p)
+	}
+	if p := byID["bad"]; p.Status != "failed" || p.Error != "invalid payload" {
+		t.Errorf("bad job payload = %+v", p)
+	}
+	if strings.Contains(logs.String(), "dead-lettered") {
+		t.Errorf("retried delivery was dead-lettered:\n%s", logs.String())
+	}
+}
+
+func TestNotifierDeadLettersAfterMaxAttempts(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	var logs syncBuffer
+	n := webhook.New(webhook.Config{URL: srv.URL, MaxAttempts: 3, Backoff: time.Millisecond, Logger: slog.New(slog.NewTextHandler(&logs, nil))})
+	n.Handle(context.Background(), worker.Event{Kind: worker.EventSucceeded, Task: worker.Task{ID: "job-1"}})
+	n.Handle(context.Background(), worker.Event{Kind: worker.EventStarted, Task: worker.Task{ID: "job-1"}}) // not terminal
+	n.Close()
+
+	if h := hits.Load(); h != 3 {
+		t.Fatalf("server hit %d times, want 3", h)
+	}
+	if out := logs.String(); !strings.Contains(out, "webhook dead-lettered") || !strings.Contains(out, "job=job-1") {
+		t.Fatalf("missing dead-letter log:\n%s", out)
+	}
+}
+
+func TestNotifierStopsWhenCtxIsDone(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		hang bool // hold the POST open rather than fail it
+	}{
+		{"mid-POST", true},
+		{"mid-backoff", false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			hit := make(chan struct{}, 1)
+			release := make(chan struct{})
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				select {
+				case hit <- struct{}{}:
+				default:
+				}
+				if tc.hang {
+					<-release
+				}
+				w.WriteHeader(http.StatusInternalServerError)
+			}))
+			defer srv.Close()
+			defer close(release)
+
+			var logs syncBuffer
+			n := webhook.New(webhook.Config{URL: srv.URL, Timeout: time.Minute, Backoff: time.Minute, Logger: slog.New(slog.NewTextHandler(&logs, nil))})
+			ctx, cancel := context.WithCancel(context.Background())
+			n.Handle(ctx, worker.Event{Kind: worker.EventSucceeded, Task: worker.Task{ID: "job-1"}})
+			<-hit
+			cancel()
+
+			closed := make(chan struct{})
+			go func() {
+				n.Close()
+				close(closed)
+			}()
+			select {
+			case <-closed:
+			case <-time.After(5 * time.Second):
+				t.Fatal("Close still waiting on a delivery after its ctx was cancelled")
+			}
+			if out := logs.String(); !strings.Contains(out, "webhook dead-lettered") || !strings.Contains(out, "context canceled") {
+				t.Fatalf("missing dead-letter log:\n%s", out)
+			}
+		})
+	}
+}
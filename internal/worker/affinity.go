@@ -0,0 +1,13 @@
+package worker
+
+import "context"
+
+type workerIDKey struct{}
+
+// WorkerIDFromContext returns the ID of the worker running the task, so a
+// handler can keep per-worker state for the AffinityKeys routed to it. It
+// reports false outside a pool-managed context.
+func WorkerIDFromContext(ctx context.Context) (int, bool) {
+	id, ok := ctx.Value(workerIDKey{}).(int)
+	return id, ok
+}
@@ -0,0 +1,68 @@
+package worker_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/rajatx185/golang-scalable-background-job-system/internal/worker"
+)
+
+func TestAffinityKeyPinsTasksToOneWorker(t *testing.T) {
+	var mu sync.Mutex
+	workersByKey := map[string]map[int]bool{}
+	pool := worker.NewPool(worker.Config{Workers: 4, QueueSize: 64}, func(ctx context.Context, t worker.Task) (string, error) {
+		id, ok := worker.WorkerIDFromContext(ctx)
+		if !ok {
+			return "", errors.New("no worker ID in context")
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if workersByKey[t.AffinityKey] == nil {
+			workersByKey[t.AffinityKey] = map[int]bool{}
+		}
+		workersByKey[t.AffinityKey][id] = true
+		return "", nil
+	})
+
+	var tasks []worker.Task
+	for i := range 40 {
+		tasks = append(tasks, worker.Task{ID: fmt.Sprint(i), AffinityKey: fmt.Sprint("customer-", i%5)})
+	}
+	for i := range 20 {
+		tasks = append(tasks, worker.Task{ID: fmt.Sprint("free-", i)})
+	}
+	for _, r := range pool.Process(context.Background(), tasks) {
+		if r.Err != nil {
+			t.Fatalf("task %s: %v", r.ID, r.Err)
+		}
+	}
+
+	for key, workers := range workersByKey {
+		if key != "" && len(workers) != 1 {
+			t.Errorf("key %q ran on workers %v, want exactly one", key, workers)
+		}
+	}
+}
+
+func TestPinnedDepth(t *testing.T) {
+	pool := worker.NewPool(worker.Config{Workers: 3, QueueSize: 8}, func(ctx context.Context, t worker.Task) (string, error) { return "", nil })
+	// Not started, so everything stays queued.
+	for i := range 4 {
+		pool.Submit(context.Background(), worker.Task{ID: fmt.Sprint(i), AffinityKey: "k"})
+	}
+	pool.Submit(context.Background(), worker.Task{ID: "free"})
+
+	total, pinnedTo := 0, 0
+	for _, n := range pool.Stats().PinnedDepth {
+		if n > 0 {
+			pinnedTo++
+		}
+		total += n
+	}
+	if len(pool.Stats().PinnedDepth) != 3 || total != 4 || pinnedTo != 1 {
+		t.Fatalf("PinnedDepth = %v, want all 4 keyed tasks on one of 3 workers", pool.Stats().PinnedDepth)
+	}
+}
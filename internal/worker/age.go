@@ -0,0 +1,71 @@
+package worker
+
+import "time"
+
+// OldestQueuedAge reports how long the task at the head of the queue has
+// been waiting since its EnqueuedAt, or zero if the queue is empty. Tasks
+// leave the queue in order, so the head is the oldest waiting task, except
+// that retries rejoin at the back and tasks pinned by AffinityKey can be
+// overtaken. A retried task counts from when it was first submitted. The
+// age is read without taking the queue's lock.
+func (p *TypedPool[In, Out]) OldestQueuedAge() time.Duration {
+	return p.queue.oldest(p.cfg.Clock.Now())
+}
+
+// startAgeWatch runs the queue-age alert if Config.MaxQueueAge is set and
+// returns a func that stops it.
+func (p *TypedPool[In, Out]) startAgeWatch() (stop func()) {
+	if p.cfg.MaxQueueAge <= 0 {
+		return func() {}
+	}
+	ticker := p.cfg.Clock.NewTicker(max(p.cfg.MaxQueueAge/4, time.Millisecond))
+	done := make(chan struct{})
+	exited := make(chan struct{})
+	go func() {
+		defer close(exited)
+		defer ticker.Stop()
+		alerted := false
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C():
+			}
+			age := p.OldestQueuedAge()
+			if age <= p.cfg.MaxQueueAge {
+				alerted = false
+				continue
+			}
+			if alerted {
+				continue
+			}
+			if j, ok := p.queue.peek(); ok {
+				alerted = true
+				p.cfg.Logger.Warn("queued task older than max queue age", "task", j.task.ID, metadataAttr(j.task.Metadata), "age", age, "max", p.cfg.MaxQueueAge)
+				p.publish(EventQueueAgeExceeded, j, nil)
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-exited
+	}
+}
+
+// watchBlockedSubmit logs a warning if the Submit of task id is still
+// blocked on the full queue after Config.SubmitBlockWarning, and returns a
+// func to call once it is through.
+func (p *TypedPool[In, Out]) watchBlockedSubmit(id string) (stop func()) {
+	if p.cfg.SubmitBlockWarning < 0 {
+		return func() {}
+	}
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-done:
+		case <-p.cfg.Clock.After(p.cfg.SubmitBlockWarning):
+			p.cfg.Logger.Warn("submit blocked on a full queue", "task", id, "waited", p.cfg.SubmitBlockWarning, "workers", p.online.Load(), "active", p.active.Load())
+		}
+	}()
+	return func() { close(done) }
+}
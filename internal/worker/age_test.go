@@ -0,0 +1,139 @@
+package worker_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rajatx185/golang-scalable-background-job-system/internal/clock"
+	"github.com/rajatx185/golang-scalable-background-job-system/internal/worker"
+)
+
+func TestQueueAgeAlert(t *testing.T) {
+	clk := clock.NewManual(time.Unix(0, 0))
+	release := make(chan struct{})
+	started := make(chan struct{})
+	pool := worker.NewPool(worker.Config{
+		Workers:     1,
+		MaxQueueAge: time.Minute,
+		Clock:       clk,
+		Logger:      slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}, func(ctx context.Context, task worker.Task) (string, error) {
+		if task.ID == "block" {
+			close(started)
+			<-release
+		}
+		return "", nil
+	})
+	aged := make(chan worker.Event, 4)
+	pool.Subscribe(func(_ context.Context, e worker.Event) {
+		if e.Kind == worker.EventQueueAgeExceeded {
+			aged <- e
+		}
+	})
+	pool.Start(context.Background())
+	go func() {
+		for range pool.Results() {
+		}
+	}()
+
+	ctx := context.Background()
+	if err := pool.Submit(ctx, worker.Task{ID: "block"}); err != nil {
+		t.Fatal(err)
+	}
+	<-started
+	if err := pool.Submit(ctx, worker.Task{ID: "waiting"}); err != nil {
+		t.Fatal(err)
+	}
+
+	clk.Advance(30 * time.Second)
+	if age := pool.OldestQueuedAge(); age != 30*time.Second {
+		t.Fatalf("OldestQueuedAge = %s, want 30s", age)
+	}
+	clk.Advance(45 * time.Second)
+	select {
+	case e := <-aged:
+		if e.Task.ID != "waiting" || !e.Task.EnqueuedAt.Equal(time.Unix(0, 0)) {
+			t.Fatalf("alert for %+v, want the waiting task", e.Task)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no EventQueueAgeExceeded after the task outwaited MaxQueueAge")
+	}
+	if age := pool.Stats().OldestQueued; age != 75*time.Second {
+		t.Fatalf("Stats().OldestQueued = %s, want 75s", age)
+	}
+
+	close(release)
+	pool.Close()
+	if age := pool.OldestQueuedAge(); age != 0 {
+		t.Fatalf("OldestQueuedAge = %s on an empty queue", age)
+	}
+	if n := len(aged); n != 0 {
+		t.Fatalf("%d more alerts for the same backlog, want one", n)
+	}
+}
+
+func TestSubmitWithoutWorkers(t *testing.T) {
+	pool := worker.NewPool(worker.Config{QueueSize: 1}, func(context.Context, worker.Task) (string, error) { return "", nil })
+	if err := pool.Submit(context.Background(), worker.Task{ID: "a"}); err != nil {
+		t.Fatalf("queueing before Start: %v", err)
+	}
+	if err := pool.Submit(context.Background(), worker.Task{ID: "b"}); !errors.Is(err, worker.ErrNoWorkers) {
+		t.Fatalf("Submit to a full, unstarted pool: err = %v, want ErrNoWorkers", err)
+	}
+}
+
+func TestBlockedSubmitWarning(t *testing.T) {
+	clk := clock.NewManual(time.Unix(0, 0))
+	var logs syncBuffer
+	release := make(chan struct{})
+	started := make(chan struct{}, 1)
+	pool := worker.NewPool(worker.Config{
+		Workers:            1,
+		QueueSize:          1,
+		SubmitBlockWarning: time.Second,
+		Clock:              clk,
+		Logger:             slog.New(slog.NewTextHandler(&logs, nil)),
+	}, func(ctx context.Context, t worker.Task) (string, error) {
+		select {
+		case started <- struct{}{}:
+		default:
+		}
+		<-release
+		return "", nil
+	})
+	pool.Start(context.Background())
+	go func() {
+		for range pool.Results() {
+		}
+	}()
+	if err := pool.Submit(context.Background(), worker.Task{ID: "running"}); err != nil {
+		t.Fatal(err)
+	}
+	<-started
+	if err := pool.Submit(context.Background(), worker.Task{ID: "queued"}); err != nil {
+		t.Fatal(err)
+	}
+	submitted := make(chan error)
+	go func() { submitted <- pool.Submit(context.Background(), worker.Task{ID: "blocked"}) }()
+
+	clk.BlockUntil(2) // the rate window's ticker and the watchdog's timer
+	clk.Advance(time.Second)
+	deadline := time.Now().Add(time.Second)
+	for !strings.Contains(logs.String(), "submit blocked on a full queue") && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if !strings.Contains(logs.String(), "task=blocked") {
+		t.Fatalf("no warning for the blocked submit:\n%s", logs.String())
+	}
+
+	close(release)
+	if err := <-submitted; err != nil {
+		t.Fatalf("blocked Submit = %v once room freed up", err)
+	}
+	pool.Close()
+}
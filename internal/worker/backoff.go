@@ -0,0 +1,74 @@
+package worker
+
+import (
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+// BackoffStrategy decides how long a failed task waits before its next
+// attempt. attempt is the number of attempts made so far, so the first
+// retry is computed with attempt 1.
+type BackoffStrategy interface {
+	Delay(attempt int) time.Duration
+}
+
+// BackoffFunc adapts a function to BackoffStrategy.
+type BackoffFunc func(attempt int) time.Duration
+
+// Delay returns f(attempt).
+func (f BackoffFunc) Delay(attempt int) time.Duration { return f(attempt) }
+
+// ConstantBackoff waits d before every retry.
+func ConstantBackoff(d time.Duration) BackoffStrategy {
+	return BackoffFunc(func(int) time.Duration { return d })
+}
+
+// LinearBackoff waits step before the first retry, 2*step before the
+// second, and so on.
+func LinearBackoff(step time.Duration) BackoffStrategy {
+	return BackoffFunc(func(attempt int) time.Duration {
+		if attempt > 0 && step > math.MaxInt64/time.Duration(attempt) {
+			return math.MaxInt64
+		}
+		return step * time.Duration(attempt)
+	})
+}
+
+// ExponentialJitterBackoff doubles base on every retry and waits a random
+// duration between zero and that ceiling ("full jitter"). Spreading the
+// delay out keeps tasks that failed together, say during an outage, from
+// all retrying at the same instant. Pair it with Config.MaxDelay, since
+// the ceiling grows without bound.
+func ExponentialJitterBackoff(base time.Duration) BackoffStrategy {
+	return BackoffFunc(func(attempt int) time.Duration {
+		ceiling := exponential(base, attempt)
+		if ceiling <= 0 {
+			return 0
+		}
+		return rand.N(ceiling)
+	})
+}
+
+// exponential returns base << (attempt-1), saturating instead of
+// overflowing.
+func exponential(base time.Duration, attempt int) time.Duration {
+	shift := max(attempt-1, 0)
+	if base <= 0 {
+		return 0
+	}
+	if shift >= 63 || base > math.MaxInt64>>shift {
+		return math.MaxInt64
+	}
+	return base << shift
+}
+
+// backoff returns how long to wait before the retry that follows the given
+// attempt number, capped at Config.MaxDelay.
+func (p *TypedPool[In, Out]) backoff(attempt int) time.Duration {
+	d := max(p.cfg.Backoff.Delay(attempt), 0)
+	if p.cfg.MaxDelay > 0 {
+		d = min(d, p.cfg.MaxDelay)
+	}
+	return d
+}
@@ -0,0 +1,49 @@
+package worker_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rajatx185/golang-scalable-background-job-system/internal/worker"
+)
+
+func TestBackoffStaysWithinMaxDelay(t *testing.T) {
+	const maxDelay = time.Second
+	strategies := map[string]worker.BackoffStrategy{
+		"constant":    worker.ConstantBackoff(3 * time.Second),
+		"linear":      worker.LinearBackoff(100 * time.Millisecond),
+		"exponential": worker.ExponentialJitterBackoff(10 * time.Millisecond),
+		"default":     nil,
+	}
+	noop := func(ctx context.Context, t worker.Task) (string, error) { return "", nil }
+	for name, strategy := range strategies {
+		t.Run(name, func(t *testing.T) {
+			pool := worker.NewPool(worker.Config{RetryBackoff: 10 * time.Millisecond, Backoff: strategy, MaxDelay: maxDelay}, noop)
+			for attempt := 1; attempt <= 200; attempt++ {
+				if d := worker.RetryDelay(pool, attempt); d < 0 || d > maxDelay {
+					t.Fatalf("attempt %d: delay %s outside [0, %s]", attempt, d, maxDelay)
+				}
+			}
+		})
+	}
+}
+
+func TestExponentialJitterBackoff(t *testing.T) {
+	backoff := worker.ExponentialJitterBackoff(10 * time.Millisecond)
+	for attempt := 1; attempt <= 200; attempt++ {
+		ceiling := 10 * time.Millisecond << (attempt - 1)
+		d := backoff.Delay(attempt)
+		if d < 0 || (attempt <= 20 && d >= ceiling) {
+			t.Fatalf("attempt %d: delay %s outside [0, %s)", attempt, d, ceiling)
+		}
+	}
+
+	seen := map[time.Duration]bool{}
+	for range 20 {
+		seen[backoff.Delay(10)] = true
+	}
+	if len(seen) < 2 {
+		t.Fatal("jittered delays are all identical")
+	}
+}
@@ -0,0 +1,64 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrBatchRolledBack is returned by ProcessAtomic when any task failed to
+// prepare and the prepared ones were rolled back.
+var ErrBatchRolledBack = errors.New("worker: batch rolled back")
+
+// TxHandler is the handler contract for all-or-nothing batches.
+//
+// Prepare does the work for one task without making it visible and returns
+// whatever Commit or Rollback needs to finish or undo it. Prepare can run
+// more than once for the same task (retries, redelivery), so it must be
+// idempotent. Commit and Rollback are only called for tasks whose Prepare
+// succeeded.
+type TxHandler interface {
+	Prepare(ctx context.Context, t Task) (prepared string, err error)
+	Commit(ctx context.Context, t Task, prepared string) error
+	Rollback(ctx context.Context, t Task, prepared string) error
+}
+
+// ProcessAtomic prepares every task on a pool built from cfg, then commits
+// them all if every Prepare succeeded, or rolls back the prepared ones if
+// any failed. The returned results are the Prepare outcomes.
+//
+// The error wraps ErrBatchRolledBack on rollback. Commit failures are
+// joined into the error too; by then other tasks may already be committed,
+// so a commit error leaves the batch partially applied.
+func ProcessAtomic(ctx context.Context, cfg Config, tasks []Task, h TxHandler) ([]Result, error) {
+	pool := NewPool(cfg, h.Prepare)
+	results := pool.Process(ctx, tasks)
+	pool.Close()
+	byID := make(map[string]Task, len(tasks))
+	for _, t := range tasks {
+		byID[t.ID] = t
+	}
+
+	var failed error
+	for _, r := range results {
+		if r.Err != nil {
+			failed = fmt.Errorf("%w: task %s: %w", ErrBatchRolledBack, r.ID, r.Err)
+			break
+		}
+	}
+
+	var errs []error
+	for _, r := range results {
+		switch t := byID[r.ID]; {
+		case failed == nil:
+			if err := h.Commit(ctx, t, r.Value); err != nil {
+				errs = append(errs, fmt.Errorf("commit %s: %w", r.ID, err))
+			}
+		case r.Err == nil:
+			if err := h.Rollback(ctx, t, r.Value); err != nil {
+				errs = append(errs, fmt.Errorf("rollback %s: %w", r.ID, err))
+			}
+		}
+	}
+	return results, errors.Join(append([]error{failed}, errs...)...)
+}
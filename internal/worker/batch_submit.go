@@ -0,0 +1,29 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rajatx185/golang-scalable-background-job-system/internal/ids"
+)
+
+// SubmitBatch submits tasks in order, giving each task without an ID a
+// freshly generated job ID, and returns the IDs of the tasks it enqueued.
+//
+// It stops at the first task Submit rejects (a full queue under
+// OverflowError, a cancelled ctx while blocking, a closed pool). In that case
+// the returned IDs cover the tasks enqueued before it, and the error reports
+// how many were rejected and wraps the cause.
+func (p *TypedPool[In, Out]) SubmitBatch(ctx context.Context, tasks []TypedTask[In]) ([]string, error) {
+	out := make([]string, 0, len(tasks))
+	for i, t := range tasks {
+		if t.ID == "" {
+			t.ID = ids.From(p.cfg.IDGenerator, "job")
+		}
+		if err := p.Submit(ctx, t); err != nil {
+			return out, fmt.Errorf("worker: %d of %d tasks rejected: %w", len(tasks)-i, len(tasks), err)
+		}
+		out = append(out, t.ID)
+	}
+	return out, nil
+}
@@ -0,0 +1,62 @@
+package worker_test
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/rajatx185/golang-scalable-background-job-system/internal/worker"
+)
+
+type recordingTx struct {
+	mu         sync.Mutex
+	committed  []string
+	rolledBack []string
+}
+
+func (r *recordingTx) Prepare(ctx context.Context, t worker.Task) (string, error) {
+	if t.Data == "bad" {
+		return "", errors.New("validation failed")
+	}
+	return "prepared-" + t.ID, nil
+}
+
+func (r *recordingTx) Commit(ctx context.Context, t worker.Task, prepared string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.committed = append(r.committed, prepared)
+	return nil
+}
+
+func (r *recordingTx) Rollback(ctx context.Context, t worker.Task, prepared string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.rolledBack = append(r.rolledBack, prepared)
+	return nil
+}
+
+func TestProcessAtomic(t *testing.T) {
+	cfg := worker.Config{Workers: 2}
+
+	tx := &recordingTx{}
+	_, err := worker.ProcessAtomic(context.Background(), cfg, []worker.Task{{ID: "a"}, {ID: "b"}}, tx)
+	if err != nil {
+		t.Fatalf("all-good batch: %v", err)
+	}
+	sort.Strings(tx.committed)
+	if len(tx.committed) != 2 || len(tx.rolledBack) != 0 {
+		t.Fatalf("all-good batch committed %v, rolled back %v", tx.committed, tx.rolledBack)
+	}
+
+	tx = &recordingTx{}
+	_, err = worker.ProcessAtomic(context.Background(), cfg, []worker.Task{{ID: "a"}, {ID: "b"}, {ID: "c", Data: "bad"}}, tx)
+	if !errors.Is(err, worker.ErrBatchRolledBack) {
+		t.Fatalf("failing batch: err = %v, want ErrBatchRolledBack", err)
+	}
+	sort.Strings(tx.rolledBack)
+	if len(tx.committed) != 0 || len(tx.rolledBack) != 2 || tx.rolledBack[0] != "prepared-a" {
+		t.Fatalf("failing batch committed %v, rolled back %v", tx.committed, tx.rolledBack)
+	}
+}
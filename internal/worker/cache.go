@@ -0,0 +1,90 @@
+package worker
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/rajatx185/golang-scalable-background-job-system/internal/clock"
+	"github.com/rajatx185/golang-scalable-background-job-system/internal/store"
+)
+
+// resultCache remembers successful values by IdempotencyKey for
+// Config.ResultCacheTTL.
+type resultCache[Out any] struct {
+	values       *store.Store[Out]
+	hits, misses atomic.Int64
+}
+
+func newResultCache[Out any](clk clock.Clock) *resultCache[Out] {
+	return &resultCache[Out]{values: store.NewStoreWithClock[Out](clk)}
+}
+
+func (c *resultCache[Out]) get(key string) (Out, bool) {
+	v, ok := c.values.Get(key)
+	if ok {
+		c.hits.Add(1)
+	} else {
+		c.misses.Add(1)
+	}
+	return v, ok
+}
+
+// hitRate is the share of lookups the cache answered, or zero before the
+// first lookup.
+func (c *resultCache[Out]) hitRate() float64 {
+	hits, misses := c.hits.Load(), c.misses.Load()
+	if hits+misses == 0 {
+		return 0
+	}
+	return float64(hits) / float64(hits+misses)
+}
+
+// cached looks t up in the result cache, if caching applies to it.
+func (p *TypedPool[In, Out]) cached(t TypedTask[In]) (Out, bool) {
+	if p.cfg.ResultCacheTTL <= 0 || t.IdempotencyKey == "" {
+		var zero Out
+		return zero, false
+	}
+	return p.cache.get(t.IdempotencyKey)
+}
+
+// remember stores a successful value for t's IdempotencyKey.
+func (p *TypedPool[In, Out]) remember(t TypedTask[In], value Out) {
+	if p.cfg.ResultCacheTTL <= 0 || t.IdempotencyKey == "" {
+		return
+	}
+	p.cache.values.SetWithTTL(t.IdempotencyKey, value, p.cfg.ResultCacheTTL)
+}
+
+// startCacheSweep reclaims expired cache entries every ResultCacheTTL, if
+// caching is on, and returns a func that stops it.
+func (p *TypedPool[In, Out]) startCacheSweep() (stop func()) {
+	return p.startSweep(p.cfg.ResultCacheTTL, func() { p.cache.values.Sweep() })
+}
+
+// startSweep calls sweep every interval on Clock, unless interval is
+// zero, and returns a func that stops it.
+func (p *TypedPool[In, Out]) startSweep(interval time.Duration, sweep func()) (stop func()) {
+	if interval <= 0 {
+		return func() {}
+	}
+	ticker := p.cfg.Clock.NewTicker(interval)
+	done := make(chan struct{})
+	exited := make(chan struct{})
+	go func() {
+		defer close(exited)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C():
+				sweep()
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-exited
+	}
+}
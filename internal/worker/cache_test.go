@@ -0,0 +1,55 @@
+package worker_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rajatx185/golang-scalable-background-job-system/internal/clock"
+	"github.com/rajatx185/golang-scalable-background-job-system/internal/worker"
+)
+
+func TestResultCache(t *testing.T) {
+	clk := clock.NewManual(time.Unix(0, 0))
+	var runs atomic.Int32
+	pool := worker.NewPool(worker.Config{ResultCacheTTL: time.Minute, Clock: clk}, func(ctx context.Context, task worker.Task) (string, error) {
+		runs.Add(1)
+		return "report for " + task.ID, nil
+	})
+	pool.Start(context.Background())
+	got := make(chan worker.Result, 4)
+	go func() {
+		for r := range pool.Results() {
+			got <- r
+		}
+	}()
+	run := func(id string) worker.Result {
+		t.Helper()
+		if err := pool.Submit(context.Background(), worker.Task{ID: id, IdempotencyKey: "monthly-report"}); err != nil {
+			t.Fatal(err)
+		}
+		return <-got
+	}
+
+	if r := run("first"); r.Cached || r.Value != "report for first" {
+		t.Fatalf("first = %+v, want a fresh run", r)
+	}
+	r := run("second")
+	if !r.Cached || r.ID != "second" || r.Value != "report for first" || r.Err != nil {
+		t.Fatalf("second = %+v, want first's value reused under its own ID", r)
+	}
+	clk.Advance(time.Minute)
+	if r := run("third"); r.Cached || r.Value != "report for third" {
+		t.Fatalf("third = %+v, want a fresh run once the TTL passed", r)
+	}
+	pool.Close()
+
+	if n := runs.Load(); n != 2 {
+		t.Fatalf("handler ran %d times, want 2", n)
+	}
+	s := pool.Stats()
+	if s.CacheHits != 1 || s.CacheMisses != 2 || s.CacheHitRate < 0.33 || s.CacheHitRate > 0.34 {
+		t.Fatalf("cache stats = %d hits, %d misses, rate %v; want 1, 2, 1/3", s.CacheHits, s.CacheMisses, s.CacheHitRate)
+	}
+}
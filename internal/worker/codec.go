@@ -0,0 +1,139 @@
+package worker
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"maps"
+	"slices"
+	"time"
+)
+
+// TypedCodec turns tasks into bytes and back wherever the pool stores or
+// ships them: the snapshots of Export and Persist, and the message bodies
+// of Enqueue and Consume. See Config.Codec.
+type TypedCodec[In any] interface {
+	Encode(TypedTask[In]) ([]byte, error)
+	Decode([]byte) (TypedTask[In], error)
+}
+
+// Codec is the TypedCodec for string tasks.
+type Codec = TypedCodec[string]
+
+// JSONCodec encodes tasks as JSON. It is the default: readable, and
+// tolerant of fields being added or removed between versions.
+type JSONCodec[In any] struct{}
+
+func (JSONCodec[In]) Encode(t TypedTask[In]) ([]byte, error) { return json.Marshal(t) }
+
+func (JSONCodec[In]) Decode(data []byte) (TypedTask[In], error) {
+	var t TypedTask[In]
+	err := json.Unmarshal(data, &t)
+	return t, err
+}
+
+// GobCodec encodes tasks with encoding/gob. Each task carries its own type
+// description, so it pays off for large payloads rather than small ones.
+type GobCodec[In any] struct{}
+
+func (GobCodec[In]) Encode(t TypedTask[In]) ([]byte, error) {
+	var buf bytes.Buffer
+	err := gob.NewEncoder(&buf).Encode(t)
+	return buf.Bytes(), err
+}
+
+func (GobCodec[In]) Decode(data []byte) (TypedTask[In], error) {
+	var t TypedTask[In]
+	err := gob.NewDecoder(bytes.NewReader(data)).Decode(&t)
+	return t, err
+}
+
+// BinaryCodec is a compact, length-prefixed encoding of string tasks for
+// high-throughput backends: each string is a uvarint length followed by
+// its bytes, each number a varint, and Metadata a uvarint count of
+// key/value string pairs in key order. It is positional, so data written
+// by one version of Task can only be read by the same version.
+type BinaryCodec struct{}
+
+// ErrCorruptTask is returned by BinaryCodec.Decode for input it did not
+// produce.
+var ErrCorruptTask = errors.New("worker: corrupt binary task")
+
+func (BinaryCodec) Encode(t Task) ([]byte, error) {
+	buf := make([]byte, 0, 64+len(t.ID)+len(t.Data))
+	for _, s := range []string{t.ID, t.Data, t.Group, t.AffinityKey, t.ResourceKey, t.ParentID, t.IdempotencyKey, t.Type} {
+		buf = binary.AppendUvarint(buf, uint64(len(s)))
+		buf = append(buf, s...)
+	}
+	var enqueued int64
+	if !t.EnqueuedAt.IsZero() {
+		enqueued = t.EnqueuedAt.UnixNano()
+	}
+	for _, n := range []int64{int64(t.Timeout), t.ResourceWeight, int64(t.Weight), enqueued, int64(t.Kind)} {
+		buf = binary.AppendVarint(buf, n)
+	}
+	buf = binary.AppendUvarint(buf, uint64(len(t.Metadata)))
+	for _, k := range slices.Sorted(maps.Keys(t.Metadata)) {
+		for _, s := range []string{k, t.Metadata[k]} {
+			buf = binary.AppendUvarint(buf, uint64(len(s)))
+			buf = append(buf, s...)
+		}
+	}
+	return buf, nil
+}
+
+func (BinaryCodec) Decode(data []byte) (Task, error) {
+	var t Task
+	readString := func() (string, bool) {
+		n, k := binary.Uvarint(data)
+		if k <= 0 || n > uint64(len(data)-k) {
+			return "", false
+		}
+		s := string(data[k : k+int(n)])
+		data = data[k+int(n):]
+		return s, true
+	}
+	for _, s := range []*string{&t.ID, &t.Data, &t.Group, &t.AffinityKey, &t.ResourceKey, &t.ParentID, &t.IdempotencyKey, &t.Type} {
+		var ok bool
+		if *s, ok = readString(); !ok {
+			return Task{}, ErrCorruptTask
+		}
+	}
+	var nums [5]int64
+	for i := range nums {
+		n, k := binary.Varint(data)
+		if k <= 0 {
+			return Task{}, ErrCorruptTask
+		}
+		nums[i] = n
+		data = data[k:]
+	}
+	pairs, k := binary.Uvarint(data)
+	// Each pair takes at least two bytes, which bounds the map to the input.
+	if k <= 0 || pairs > uint64(len(data)-k)/2 {
+		return Task{}, ErrCorruptTask
+	}
+	data = data[k:]
+	if pairs > 0 {
+		t.Metadata = make(map[string]string, pairs)
+	}
+	for range pairs {
+		key, ok := readString()
+		if !ok {
+			return Task{}, ErrCorruptTask
+		}
+		if t.Metadata[key], ok = readString(); !ok {
+			return Task{}, ErrCorruptTask
+		}
+	}
+	if len(data) != 0 {
+		return Task{}, ErrCorruptTask
+	}
+	t.Timeout, t.ResourceWeight, t.Weight, t.Kind = time.Duration(nums[0]), nums[1], int(nums[2]), TaskKind(nums[4])
+	if nums[3] != 0 {
+		t.EnqueuedAt = time.Unix(0, nums[3])
+	}
+	return t, nil
+}
@@ -0,0 +1,118 @@
+package worker_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rajatx185/golang-scalable-background-job-system/internal/worker"
+)
+
+// sampleTask is a task the size of a typical webhook-delivery job.
+func sampleTask() worker.Task {
+	return worker.Task{
+		ID:             "job-3f9c2a7e1b4d",
+		Type:           "webhook",
+		Kind:           worker.KindIO,
+		Data:           `{"url":"https://example.com/hooks/orders","event":"order.created","order":{"id":48213,"items":[{"sku":"A-100","qty":2},{"sku":"B-220","qty":1}],"total":"129.90"}}`,
+		Timeout:        30 * time.Second,
+		Group:          "tenant-42",
+		ResourceKey:    "example.com",
+		ResourceWeight: 1,
+		Weight:         2,
+		IdempotencyKey: "order-48213-created",
+		Metadata:       map[string]string{"source": "checkout", "correlation_id": "c-77a1"},
+		EnqueuedAt:     time.Unix(1700000000, 123456789),
+	}
+}
+
+var codecs = []struct {
+	name  string
+	codec worker.Codec
+}{
+	{"json", worker.JSONCodec[string]{}},
+	{"gob", worker.GobCodec[string]{}},
+	{"binary", worker.BinaryCodec{}},
+}
+
+func TestCodecRoundTrip(t *testing.T) {
+	for _, c := range codecs {
+		t.Run(c.name, func(t *testing.T) {
+			want := sampleTask()
+			data, err := c.codec.Encode(want)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := c.codec.Decode(data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !got.EnqueuedAt.Equal(want.EnqueuedAt) {
+				t.Fatalf("EnqueuedAt = %v, want %v", got.EnqueuedAt, want.EnqueuedAt)
+			}
+			got.EnqueuedAt, want.EnqueuedAt = time.Time{}, time.Time{}
+			if !reflect.DeepEqual(got, want) {
+				t.Fatalf("decoded %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestBinaryCodecRejectsCorruptInput(t *testing.T) {
+	data, _ := worker.BinaryCodec{}.Encode(sampleTask())
+	for _, bad := range [][]byte{nil, data[:len(data)/2], append(data, 0)} {
+		if _, err := (worker.BinaryCodec{}).Decode(bad); !errors.Is(err, worker.ErrCorruptTask) {
+			t.Fatalf("Decode(%d bytes) = %v, want ErrCorruptTask", len(bad), err)
+		}
+	}
+}
+
+func TestExportWithCodec(t *testing.T) {
+	noop := func(context.Context, worker.Task) (string, error) { return "", nil }
+	src := worker.NewPool(worker.Config{QueueSize: 2, Codec: worker.BinaryCodec{}}, noop)
+	if err := src.Submit(context.Background(), sampleTask()); err != nil {
+		t.Fatal(err)
+	}
+	data, err := src.Export()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "tenant-42") {
+		t.Fatalf("snapshot holds the task as JSON under BinaryCodec: %s", data)
+	}
+
+	dst := worker.NewPool(worker.Config{QueueSize: 2, Codec: worker.BinaryCodec{}}, noop)
+	if err := dst.Import(data); err != nil {
+		t.Fatal(err)
+	}
+	dst.Start(context.Background())
+	go dst.Close()
+	var results []worker.Result
+	for r := range dst.Results() {
+		results = append(results, r)
+	}
+	if len(results) != 1 || results[0].ID != sampleTask().ID {
+		t.Fatalf("imported pool produced %+v, want the exported task", results)
+	}
+}
+
+func BenchmarkCodecs(b *testing.B) {
+	task := sampleTask()
+	for _, c := range codecs {
+		data, _ := c.codec.Encode(task)
+		b.Run(c.name+"/encode", func(b *testing.B) {
+			for b.Loop() {
+				c.codec.Encode(task)
+			}
+			b.ReportMetric(float64(len(data)), "bytes/task")
+		})
+		b.Run(c.name+"/decode", func(b *testing.B) {
+			for b.Loop() {
+				c.codec.Decode(data)
+			}
+		})
+	}
+}
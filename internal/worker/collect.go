@@ -0,0 +1,74 @@
+package worker
+
+import (
+	"runtime"
+	"sync"
+	"time"
+)
+
+// Collect ranges over Results on n goroutines, calling fn for each result,
+// and returns once Results ends: after Close, or when the context passed
+// to Start is done. One collector is the same as ranging over Results
+// yourself; more keep up when many fast workers produce results faster
+// than fn handles them one at a time. n below 1 means 1.
+//
+// With more than one collector fn runs concurrently, so whatever it
+// writes to must be safe for concurrent use, and results reach it in no
+// particular order: anything that relies on completion order, like
+// appending to a slice to read back in sequence, needs n == 1.
+func (p *TypedPool[In, Out]) Collect(n int, fn func(TypedResult[Out])) {
+	var wg sync.WaitGroup
+	for range max(n, 1) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for r := range p.Results() {
+				fn(r)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// deliver sends res on Results. When the buffer is full it times the wait,
+// adding it to Stats.ResultSendBlockedTotal, and logs once the wait passes
+// Config.ResultBlockWarning. The wait is measured in real time, like
+// Result.Duration, since it is time the worker really spent idle.
+func (p *TypedPool[In, Out]) deliver(res TypedResult[Out]) {
+	defer p.recoverLateSend(res)
+	select {
+	case p.results <- res:
+		return
+	default:
+	}
+	start := time.Now()
+	defer func() { p.resultsBlocked.Add(int64(time.Since(start))) }()
+	if p.cfg.ResultBlockWarning < 0 {
+		p.results <- res
+		return
+	}
+	timer := time.NewTimer(p.cfg.ResultBlockWarning)
+	defer timer.Stop()
+	select {
+	case p.results <- res:
+	case <-timer.C:
+		p.cfg.Logger.Warn("result send blocked on a slow collector", "task", res.ID, "waited", p.cfg.ResultBlockWarning, "buffer", cap(p.results))
+		p.results <- res
+	}
+}
+
+// recoverLateSend drops res if deliver panicked because Close had already
+// closed Results. Close waits for every pending task to be delivered
+// first, so this means a result went out that was never counted as
+// pending; logging it keeps that bug from taking the process down with
+// it. Any other panic is re-raised.
+func (p *TypedPool[In, Out]) recoverLateSend(res TypedResult[Out]) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	if err, ok := r.(runtime.Error); !ok || err.Error() != "send on closed channel" {
+		panic(r)
+	}
+	p.cfg.Logger.Error("result delivered after Results was closed, dropping it", "task", res.ID, "attempt", res.Attempt, "err", res.Err)
+}
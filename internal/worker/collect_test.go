@@ -0,0 +1,159 @@
+package worker_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"log/slog"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rajatx185/golang-scalable-background-job-system/internal/worker"
+)
+
+func TestCollectSharesResults(t *testing.T) {
+	pool := worker.NewPool(worker.Config{Workers: 4}, func(ctx context.Context, t worker.Task) (string, error) {
+		return t.ID, nil
+	})
+	pool.Start(context.Background())
+
+	var mu sync.Mutex
+	seen := map[string]int{}
+	collected := make(chan struct{})
+	go func() {
+		defer close(collected)
+		pool.Collect(3, func(r worker.Result) {
+			mu.Lock()
+			defer mu.Unlock()
+			seen[r.Value]++
+		})
+	}()
+	for i := range 100 {
+		if err := pool.Submit(context.Background(), worker.Task{ID: fmt.Sprint(i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	pool.Close()
+	<-collected
+
+	if len(seen) != 100 {
+		t.Fatalf("collected %d distinct results, want 100", len(seen))
+	}
+	for id, n := range seen {
+		if n != 1 {
+			t.Fatalf("result %s collected %d times", id, n)
+		}
+	}
+}
+
+// BenchmarkCollectors feeds many instant tasks through a result callback
+// that does a little work per result, the case where one collector holds
+// the workers up.
+func BenchmarkCollectors(b *testing.B) {
+	for _, n := range []int{1, 4} {
+		b.Run(fmt.Sprintf("collectors=%d", n), func(b *testing.B) {
+			pool := worker.NewPool(worker.Config{Workers: 8, QueueSize: 1024}, func(ctx context.Context, t worker.Task) (string, error) {
+				return "", nil
+			})
+			pool.Start(context.Background())
+			var mu sync.Mutex
+			var total uint64
+			collected := make(chan struct{})
+			go func() {
+				defer close(collected)
+				pool.Collect(n, func(r worker.Result) {
+					h := fnv.New64a()
+					for range 200 {
+						h.Write([]byte(r.ID))
+					}
+					mu.Lock()
+					total += h.Sum64()
+					mu.Unlock()
+				})
+			}()
+			i := 0
+			for b.Loop() {
+				i++
+				if err := pool.Submit(context.Background(), worker.Task{ID: strconv.Itoa(i)}); err != nil {
+					b.Fatal(err)
+				}
+			}
+			pool.Close()
+			<-collected
+		})
+	}
+}
+
+func TestRangeResultsStopsOnCancel(t *testing.T) {
+	before := runtime.NumGoroutine()
+	pool := worker.NewPool(worker.Config{Workers: 2, QueueSize: 4}, func(ctx context.Context, t worker.Task) (string, error) {
+		return "ok", nil
+	})
+	pool.Start(context.Background())
+	for i := range 4 {
+		if err := pool.Submit(context.Background(), worker.Task{ID: fmt.Sprint(i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	n := 0
+	err := pool.RangeResults(ctx, func(worker.Result) bool {
+		n++
+		cancel()
+		return true
+	})
+	if !errors.Is(err, context.Canceled) || n != 1 {
+		t.Fatalf("RangeResults = %v after %d results, want context.Canceled after 1", err, n)
+	}
+
+	// The rest fit in the results buffer, so the workers finish and Close
+	// returns without anyone collecting them.
+	pool.Close()
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if got := runtime.NumGoroutine(); got > before {
+		t.Fatalf("%d goroutines after Close, want %d", got, before)
+	}
+}
+
+func TestSlowCollectorBlocksResultSend(t *testing.T) {
+	var logs syncBuffer
+	pool := worker.NewPool(worker.Config{
+		Workers:            4,
+		QueueSize:          1,
+		ResultBlockWarning: 10 * time.Millisecond,
+		Logger:             slog.New(slog.NewTextHandler(&logs, nil)),
+	}, func(ctx context.Context, t worker.Task) (string, error) {
+		return "", nil
+	})
+	pool.Start(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for range pool.Results() {
+			time.Sleep(30 * time.Millisecond)
+		}
+	}()
+	for i := range 4 {
+		if err := pool.Submit(context.Background(), worker.Task{ID: fmt.Sprint(i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	pool.Close()
+	<-done
+
+	if blocked := pool.Stats().ResultSendBlockedTotal; blocked < 10*time.Millisecond {
+		t.Fatalf("ResultSendBlockedTotal = %v with a slow collector, want at least the warning threshold", blocked)
+	}
+	if !strings.Contains(logs.String(), "result send blocked on a slow collector") {
+		t.Fatalf("no slow-collector warning:\n%s", logs.String())
+	}
+}
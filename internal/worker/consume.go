@@ -0,0 +1,107 @@
+package worker
+
+import (
+	"context"
+	"sync"
+
+	queue1 "github.com/rajatx185/golang-scalable-background-job-system/internal/queue"
+)
+
+// Enqueue encodes t as JSON and publishes it to b for a pool using the
+// default Config.Codec to Consume.
+func Enqueue[In any](ctx context.Context, b queue1.QueueBackend, t TypedTask[In]) error {
+	return EnqueueWith(ctx, b, JSONCodec[In]{}, t)
+}
+
+// EnqueueWith is Enqueue for a pool with a different Config.Codec; codec
+// must match it.
+func EnqueueWith[In any](ctx context.Context, b queue1.QueueBackend, codec TypedCodec[In], t TypedTask[In]) error {
+	body, err := codec.Encode(t)
+	if err != nil {
+		return err
+	}
+	return b.Enqueue(ctx, queue1.Message{ID: t.ID, Body: body})
+}
+
+// Consume runs tasks from b on Config.Workers goroutines until ctx is done.
+//
+// Delivery is at-least-once: a task is acked only after the handler returns
+// nil. If the handler fails, or the process dies before the ack, the backend
+// redelivers the task once its visibility timeout expires. A task can
+// therefore be processed more than once, and handlers with side effects
+// must be idempotent. A task delivered more than MaxRetries+1 times, or
+// failing with an error Config.IsRetryable rejects, is dead-lettered and
+// acked so it stops coming back. An ack that fails, say because the
+// handler outran the visibility timeout, is logged and leaves the task to
+// be redelivered; the worker moves on.
+func (p *TypedPool[In, Out]) Consume(ctx context.Context, b queue1.QueueBackend) error {
+	if p.process == nil {
+		return ErrNoHandler
+	}
+	states, err := p.warmAll(p.cfg.Workers)
+	if err != nil {
+		return err
+	}
+	var wg sync.WaitGroup
+	errs := make(chan error, p.cfg.Workers)
+	stopMonitor := p.startMonitor()
+	defer stopMonitor()
+	for i := 1; i <= p.cfg.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p.goroutines.add()
+			defer p.goroutines.done()
+			defer p.teardown(i, states[i-1])
+			if err := p.consume(p.withWorkerState(ctx, states[i-1]), i, b); err != nil {
+				errs <- err
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	return <-errs
+}
+
+func (p *TypedPool[In, Out]) consume(ctx context.Context, id int, b queue1.QueueBackend) error {
+	for {
+		msg, err := b.Dequeue(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+
+		t, err := p.cfg.Codec.Decode(msg.Body)
+		if err != nil {
+			// Undecodable bodies can never succeed.
+			p.deadLetter(job[In]{task: TypedTask[In]{ID: msg.ID}, attempts: msg.Deliveries}, err, ReasonPoison)
+		} else if err := p.consumeOne(ctx, id, t, msg.Deliveries).Err; err != nil {
+			if !p.cfg.IsRetryable(err) {
+				p.deadLetter(job[In]{task: t, attempts: msg.Deliveries}, err, ReasonFatal)
+			} else if msg.Deliveries <= p.cfg.MaxRetries {
+				// Leave it unacked; the backend hands it out again.
+				continue
+			} else {
+				p.deadLetter(job[In]{task: t, attempts: msg.Deliveries}, err, ReasonExhausted)
+			}
+		}
+
+		if err := b.Ack(ctx, msg.ID); err != nil {
+			// Most likely the handler overran the visibility timeout and
+			// the message is due again, which at-least-once allows for.
+			// Either way the message is the backend's to redeliver, and
+			// this consumer carries on with the next one.
+			p.cfg.Logger.Warn("ack failed, the task may run again", "task", msg.ID, "deliveries", msg.Deliveries, "err", err)
+		}
+	}
+}
+
+func (p *TypedPool[In, Out]) consumeOne(ctx context.Context, id int, t TypedTask[In], attempt int) TypedResult[Out] {
+	p.heartbeats.beat(id, t.ID)
+	defer p.heartbeats.beat(id, "")
+	p.active.Add(1)
+	defer p.active.Add(-1)
+	return p.processOne(p.withTask(p.heartbeats.withBeat(ctx, id, t.ID), t, attempt, id), t)
+}
@@ -0,0 +1,116 @@
+package worker_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	queue1 "github.com/rajatx185/golang-scalable-background-job-system/internal/queue"
+	"github.com/rajatx185/golang-scalable-background-job-system/internal/worker"
+)
+
+func TestConsumeRedeliversUntilSuccess(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	backend := queue1.NewMemoryBackend(20 * time.Millisecond)
+
+	var calls atomic.Int32
+	done := make(chan struct{})
+	pool := worker.NewPool(worker.Config{MaxRetries: 2, IsRetryable: retryAll}, func(ctx context.Context, t worker.Task) (string, error) {
+		if calls.Add(1) == 1 {
+			// Crash before ack: the task must come back.
+			return "", errors.New("worker crashed")
+		}
+		close(done)
+		return "ok", nil
+	})
+
+	if err := worker.Enqueue(ctx, backend, worker.Task{ID: "job-1"}); err != nil {
+		t.Fatal(err)
+	}
+	consumed := make(chan error)
+	go func() { consumed <- pool.Consume(ctx, backend) }()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		t.Fatal("task was not redelivered")
+	}
+	// Give the consumer a moment to ack the second delivery.
+	deadline := time.Now().Add(500 * time.Millisecond)
+	for backend.Len() != 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	cancel()
+	if err := <-consumed; err != nil {
+		t.Fatalf("Consume: %v", err)
+	}
+	if n := backend.Len(); n != 0 {
+		t.Fatalf("backend still holds %d messages after success", n)
+	}
+	if n := calls.Load(); n != 2 {
+		t.Fatalf("handler ran %d times, want 2", n)
+	}
+}
+
+func TestConsumeSurvivesAckAfterVisibilityTimeout(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	backend := queue1.NewMemoryBackend(20 * time.Millisecond)
+	var logs syncBuffer
+	var slowCalls atomic.Int32
+	arrived, release := make(chan string, 2), make(chan struct{})
+	pool := worker.NewPool(worker.Config{Workers: 2, Logger: slog.New(slog.NewTextHandler(&logs, nil))},
+		func(ctx context.Context, t worker.Task) (string, error) {
+			if t.ID == "slow" {
+				if slowCalls.Add(1) == 1 {
+					// Outrun the visibility timeout, so the other worker gets
+					// the redelivered copy and this ack fails.
+					time.Sleep(80 * time.Millisecond)
+				}
+				return "ok", nil
+			}
+			arrived <- t.ID
+			<-release
+			return "ok", nil
+		})
+	if err := worker.Enqueue(ctx, backend, worker.Task{ID: "slow"}); err != nil {
+		t.Fatal(err)
+	}
+	consumed := make(chan error)
+	go func() { consumed <- pool.Consume(ctx, backend) }()
+
+	deadline := time.Now().Add(time.Second)
+	for !strings.Contains(logs.String(), "ack failed") && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if !strings.Contains(logs.String(), "task=slow") {
+		t.Fatalf("logs = %q, want the failed ack of the overrun delivery", logs.String())
+	}
+	// Both workers must still be consuming: these two only finish together.
+	for _, id := range []string{"a", "b"} {
+		if err := worker.Enqueue(ctx, backend, worker.Task{ID: id}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for range 2 {
+		select {
+		case <-arrived:
+		case <-ctx.Done():
+			t.Fatal("a worker stopped consuming after its ack failed")
+		}
+	}
+	close(release)
+	deadline = time.Now().Add(time.Second)
+	for backend.Len() != 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	cancel()
+	if err := <-consumed; err != nil {
+		t.Fatalf("Consume = %v, want nil", err)
+	}
+}
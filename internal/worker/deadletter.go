@@ -0,0 +1,101 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// Reasons a task ends up in the dead-letter channel.
+const (
+	// ReasonExhausted means the task failed on every attempt it was given.
+	ReasonExhausted = "exhausted"
+	// ReasonPoison means the task kept failing with the same error and its
+	// remaining retries were skipped; see Config.PoisonThreshold.
+	ReasonPoison = "poison"
+	// ReasonFatal means Config.IsRetryable rejected the error, so the task
+	// was not retried at all.
+	ReasonFatal = "fatal"
+)
+
+// DefaultIsRetryable is the classifier used when Config.IsRetryable is
+// nil. Timeouts are treated as transient; anything else — a validation
+// error, say — is assumed to fail the same way every time.
+func DefaultIsRetryable(err error) bool {
+	return errors.Is(err, context.DeadlineExceeded) || errors.Is(err, ErrHardTimeout)
+}
+
+// TypedDeadLetter is a task that failed for good, with the last error it
+// saw.
+type TypedDeadLetter[In any] struct {
+	Task     TypedTask[In]
+	Err      error
+	Reason   string
+	Attempts int
+}
+
+// DeadLetter is the TypedDeadLetter for string tasks.
+type DeadLetter = TypedDeadLetter[string]
+
+// deadLetterStore keeps the latest dead letters, oldest first, for
+// ReplayDeadLetters.
+type deadLetterStore[In any] struct {
+	mu      sync.Mutex
+	letters []TypedDeadLetter[In]
+	retain  int
+}
+
+func (s *deadLetterStore[In]) add(d TypedDeadLetter[In]) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.letters = append(s.letters, d)
+	if over := len(s.letters) - s.retain; over > 0 {
+		s.letters = slices.Delete(s.letters, 0, over)
+	}
+}
+
+// take removes and returns the dead letters whose task matches filter, or
+// all of them if filter is nil.
+func (s *deadLetterStore[In]) take(filter func(TypedTask[In]) bool) []TypedDeadLetter[In] {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var taken, kept []TypedDeadLetter[In]
+	for _, d := range s.letters {
+		if filter == nil || filter(d.Task) {
+			taken = append(taken, d)
+		} else {
+			kept = append(kept, d)
+		}
+	}
+	s.letters = kept
+	return taken
+}
+
+func (s *deadLetterStore[In]) len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.letters)
+}
+
+// ReplayDeadLetters resubmits the retained dead letters whose task matches
+// filter (all of them if filter is nil), for once the bug that failed
+// them is fixed, and reports how many it resubmitted. Each goes through
+// Submit again as a fresh task under its original ID, with its attempts
+// starting over from zero, and leaves the dead-letter store. It is safe
+// to call while the pool is running. If Submit rejects a task, that task
+// and the ones after it are put back and the error is returned; see
+// Config.DeadLetterRetention.
+func (p *TypedPool[In, Out]) ReplayDeadLetters(ctx context.Context, filter func(TypedTask[In]) bool) (int, error) {
+	letters := p.deadLetters.take(filter)
+	for i, d := range letters {
+		if err := p.Submit(ctx, d.Task); err != nil {
+			for _, d := range letters[i:] {
+				p.deadLetters.add(d)
+			}
+			return i, fmt.Errorf("worker: replay %s: %w", d.Task.ID, err)
+		}
+	}
+	return len(letters), nil
+}
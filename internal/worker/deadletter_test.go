@@ -0,0 +1,72 @@
+package worker_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync/atomic"
+	"testing"
+
+	"github.com/rajatx185/golang-scalable-background-job-system/internal/worker"
+)
+
+func TestReplayDeadLetters(t *testing.T) {
+	var fixed atomic.Bool
+	var calls atomic.Int64
+	pool := worker.NewPool(worker.Config{
+		MaxRetries: 1,
+		Logger:     slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}, func(ctx context.Context, t worker.Task) (string, error) {
+		calls.Add(1)
+		if !fixed.Load() {
+			return "", errors.New("bug")
+		}
+		return "ok", nil
+	})
+	pool.Start(context.Background())
+	got := make(chan worker.Result, 3)
+	go func() {
+		for r := range pool.Results() {
+			got <- r
+		}
+	}()
+	for _, id := range []string{"a", "b", "keep"} {
+		if err := pool.Submit(context.Background(), worker.Task{ID: id}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for range 3 {
+		if r := <-got; r.Err == nil {
+			t.Fatalf("%s succeeded before the fix", r.ID)
+		}
+	}
+	if n := pool.Stats().DeadLetters; n != 3 {
+		t.Fatalf("DeadLetters = %d, want 3", n)
+	}
+
+	fixed.Store(true)
+	calls.Store(0)
+	n, err := pool.ReplayDeadLetters(context.Background(), func(t worker.Task) bool { return t.ID != "keep" })
+	if err != nil || n != 2 {
+		t.Fatalf("ReplayDeadLetters = %d, %v, want 2, nil", n, err)
+	}
+	for range 2 {
+		if r := <-got; r.Err != nil {
+			t.Fatalf("replayed %s = %+v, want success", r.ID, r)
+		}
+	}
+	pool.Close()
+	if c := calls.Load(); c != 2 {
+		t.Fatalf("handler ran %d times after the fix, want 2", c)
+	}
+	if n := pool.Stats().DeadLetters; n != 1 {
+		t.Fatalf("DeadLetters = %d after replay, want 1 left", n)
+	}
+	if _, err := pool.ReplayDeadLetters(context.Background(), nil); !errors.Is(err, worker.ErrClosed) {
+		t.Fatalf("replay on a closed pool: err = %v, want ErrClosed", err)
+	}
+	if n := pool.Stats().DeadLetters; n != 1 {
+		t.Fatalf("DeadLetters = %d after a failed replay, want it put back", n)
+	}
+}
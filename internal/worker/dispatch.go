@@ -0,0 +1,138 @@
+package worker
+
+import (
+	"container/heap"
+	"sort"
+	"time"
+)
+
+// DispatchOrder decides which queued task a free worker takes next.
+type DispatchOrder int
+
+const (
+	// DispatchFIFO runs tasks in the order they were queued.
+	DispatchFIFO DispatchOrder = iota
+	// DispatchEarliestDeadline runs the task whose deadline is soonest:
+	// its EnqueuedAt plus its timeout (see Config.DefaultTimeout), or the
+	// deadline of the context it was submitted with if that is sooner.
+	// Tasks without a deadline run after every task with one, in FIFO
+	// order. A task whose deadline has already passed when a worker
+	// first takes it fails with ErrExpiredBeforeStart instead of running;
+	// see Stats.ExpiredBeforeStart. Retries always run.
+	DispatchEarliestDeadline
+)
+
+// deadlineEntry is a queued job's place in a deadlineHeap. at is zero for
+// a job without a deadline.
+type deadlineEntry struct {
+	at  time.Time
+	seq uint64
+}
+
+// deadlineHeap is a min-heap of queued jobs by deadline, ties and jobs
+// without one going in queue order. It indexes the queue rather than
+// holding the jobs: an entry whose job has left the queue some other way,
+// say by DropOldest, is stale and skipped when it surfaces.
+type deadlineHeap []deadlineEntry
+
+func (h deadlineHeap) Len() int { return len(h) }
+
+func (h deadlineHeap) Less(i, j int) bool {
+	a, b := h[i], h[j]
+	if a.at.IsZero() != b.at.IsZero() {
+		return b.at.IsZero()
+	}
+	if !a.at.Equal(b.at) {
+		return a.at.Before(b.at)
+	}
+	return a.seq < b.seq
+}
+
+func (h deadlineHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+func (h *deadlineHeap) Push(x any)   { *h = append(*h, x.(deadlineEntry)) }
+
+func (h *deadlineHeap) Pop() any {
+	old := *h
+	e := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return e
+}
+
+// deadlineOf is a job's deadline for DispatchEarliestDeadline, or zero if
+// it has none.
+func (p *TypedPool[In, Out]) deadlineOf(j job[In]) time.Time {
+	var at time.Time
+	if timeout := p.timeoutFor(j.task); timeout > 0 {
+		at = j.task.EnqueuedAt.Add(timeout)
+	}
+	if !j.deadline.IsZero() && (at.IsZero() || j.deadline.Before(at)) {
+		at = j.deadline
+	}
+	return at
+}
+
+// expired reports whether j should fail with ErrExpiredBeforeStart rather
+// than run. A retry has started already, so it never has.
+func (p *TypedPool[In, Out]) expired(j job[In]) bool {
+	if p.cfg.Dispatch != DispatchEarliestDeadline || j.attempts > 0 {
+		return false
+	}
+	at := p.deadlineOf(j)
+	return !at.IsZero() && !p.cfg.Clock.Now().Before(at)
+}
+
+// orderByDeadline switches the queue to DispatchEarliestDeadline, with
+// deadlineOf giving each job's deadline as it joins. It must be called
+// before the first push.
+func (q *taskQueue[In]) orderByDeadline(deadlineOf func(job[In]) time.Time) {
+	q.edf, q.deadlineOf = &deadlineHeap{}, deadlineOf
+}
+
+// index adds j, which pushBack just stamped, to the deadline heap. The heap
+// is rebuilt once stale entries outnumber live ones, so it stays bounded
+// by the queue. It requires q.mu.
+func (q *taskQueue[In]) index(j job[In]) {
+	if len(*q.edf) > 2*q.n+64 {
+		rebuilt := make(deadlineHeap, 0, q.n)
+		for i := 0; i < q.n; i++ {
+			k := q.buf[(q.head+i)%len(q.buf)]
+			rebuilt = append(rebuilt, deadlineEntry{q.deadlineOf(k), k.seq})
+		}
+		heap.Init(&rebuilt)
+		*q.edf = rebuilt
+		return // j is in the queue already, so it was just indexed
+	}
+	heap.Push(q.edf, deadlineEntry{q.deadlineOf(j), j.seq})
+}
+
+// nextByDeadline is nextFor under DispatchEarliestDeadline. It takes the
+// returned job's entry off the heap, so the caller must remove the job.
+func (q *taskQueue[In]) nextByDeadline(worker int) int {
+	var held []deadlineEntry // pinned to other workers; put back after
+	defer func() {
+		for _, e := range held {
+			heap.Push(q.edf, e)
+		}
+	}()
+	for q.edf.Len() > 0 {
+		e := heap.Pop(q.edf).(deadlineEntry)
+		i, ok := q.find(e.seq)
+		if !ok {
+			continue // stale
+		}
+		if j := &q.buf[(q.head+i)%len(q.buf)]; worker == 0 || j.task.AffinityKey == "" || q.owner(j.task.AffinityKey) == worker {
+			return i
+		}
+		held = append(held, e)
+	}
+	return -1
+}
+
+// find returns the position of the queued job stamped seq. Jobs join at
+// the back with ever larger stamps and leave without reordering the rest,
+// so the ring is sorted by seq.
+func (q *taskQueue[In]) find(seq uint64) (int, bool) {
+	at := func(k int) uint64 { return q.buf[(q.head+k)%len(q.buf)].seq }
+	i := sort.Search(q.n, func(k int) bool { return at(k) >= seq })
+	return i, i < q.n && at(i) == seq
+}
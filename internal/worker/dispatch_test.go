@@ -0,0 +1,114 @@
+package worker_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rajatx185/golang-scalable-background-job-system/internal/clock"
+	"github.com/rajatx185/golang-scalable-background-job-system/internal/worker"
+)
+
+func TestEarliestDeadlineFirst(t *testing.T) {
+	pool := worker.NewPool(worker.Config{Workers: 1, QueueSize: 8, Dispatch: worker.DispatchEarliestDeadline},
+		func(ctx context.Context, t worker.Task) (string, error) { return t.ID, nil })
+	// Queue everything before the single worker starts, so dispatch order
+	// is all that decides the result order.
+	for _, task := range []worker.Task{
+		{ID: "late", Timeout: time.Hour},
+		{ID: "none"},
+		{ID: "soon", Timeout: time.Minute},
+		{ID: "none-2"},
+		{ID: "mid", Timeout: 10 * time.Minute},
+	} {
+		if err := pool.Submit(context.Background(), task); err != nil {
+			t.Fatal(err)
+		}
+	}
+	pool.Start(context.Background())
+	var order []string
+	for r := range pool.Results() {
+		order = append(order, r.ID)
+		if len(order) == 5 {
+			break
+		}
+	}
+	pool.Close()
+	if want := []string{"soon", "mid", "late", "none", "none-2"}; !slices.Equal(order, want) {
+		t.Fatalf("ran %v, want %v", order, want)
+	}
+}
+
+func TestEarliestDeadlineFailsExpiredTasks(t *testing.T) {
+	clk := clock.NewManual(time.Unix(0, 0))
+	pool := worker.NewPool(worker.Config{Workers: 1, QueueSize: 8, Dispatch: worker.DispatchEarliestDeadline, Clock: clk},
+		func(ctx context.Context, t worker.Task) (string, error) { return "ran", nil })
+	defer pool.Close()
+	for _, task := range []worker.Task{{ID: "expired", Timeout: time.Second}, {ID: "fresh", Timeout: time.Hour}} {
+		if err := pool.Submit(context.Background(), task); err != nil {
+			t.Fatal(err)
+		}
+	}
+	clk.Advance(2 * time.Second)
+	pool.Start(context.Background())
+
+	seen := 0
+	for r := range pool.Results() {
+		switch r.ID {
+		case "expired":
+			if !errors.Is(r.Err, worker.ErrExpiredBeforeStart) || r.Value != "" {
+				t.Errorf("expired: %q, %v, want ErrExpiredBeforeStart without running", r.Value, r.Err)
+			}
+		case "fresh":
+			if r.Err != nil {
+				t.Errorf("fresh: %v, want it to run", r.Err)
+			}
+		}
+		if seen++; seen == 2 {
+			break
+		}
+	}
+	if n := pool.Stats().ExpiredBeforeStart; n != 1 {
+		t.Fatalf("ExpiredBeforeStart = %d, want 1", n)
+	}
+}
+
+func TestEarliestDeadlineRunsLateRetries(t *testing.T) {
+	clk := clock.NewManual(time.Unix(0, 0))
+	var calls atomic.Int32
+	pool := worker.NewPool(worker.Config{
+		Workers:      1,
+		Dispatch:     worker.DispatchEarliestDeadline,
+		Clock:        clk,
+		MaxRetries:   1,
+		RetryBackoff: time.Minute,
+		IsRetryable:  retryAll,
+	}, func(ctx context.Context, t worker.Task) (string, error) {
+		if calls.Add(1) == 1 {
+			return "", errors.New("try again")
+		}
+		return "ran", nil
+	})
+	ctx := context.Background()
+	pool.Start(ctx)
+	if err := pool.Submit(ctx, worker.Task{ID: "a", Timeout: time.Second}); err != nil {
+		t.Fatal(err)
+	}
+	// The deadline passes while the retry backs off: the retry loop waits
+	// on the clock alongside the rate window's ticker.
+	clk.BlockUntil(2)
+	clk.Advance(time.Minute)
+
+	go pool.Close()
+	for r := range pool.Results() {
+		if r.Err != nil || r.Attempt != 2 {
+			t.Errorf("result = %v after %d attempts, want the retry to run", r.Err, r.Attempt)
+		}
+	}
+	if n := pool.Stats().ExpiredBeforeStart; n != 0 {
+		t.Errorf("ExpiredBeforeStart = %d, want 0 for a task that ran", n)
+	}
+}
@@ -0,0 +1,66 @@
+package worker
+
+import "errors"
+
+var (
+	// ErrClosed is returned by Submit once Close has been called.
+	ErrClosed = errors.New("worker: pool closed")
+	// ErrDraining is returned by Submit once Drain has been called.
+	ErrDraining = errors.New("worker: pool draining")
+	// ErrNotFresh is returned by Import on a pool that has been started
+	// or already holds tasks.
+	ErrNotFresh = errors.New("worker: import requires a fresh pool")
+	// ErrUnknownJob is returned by Wait for a task ID the pool has no
+	// record of.
+	ErrUnknownJob = errors.New("worker: unknown job")
+	// ErrNoWorkers is returned by Submit when the queue is full and the
+	// pool was never started, so blocking would wait forever, and by
+	// SubmitSync whenever the pool was never started.
+	ErrNoWorkers = errors.New("worker: no workers started")
+	// ErrNoHandler is returned by Submit, Process and Consume on a pool
+	// made with a nil process func, which has nothing to run tasks with.
+	ErrNoHandler = errors.New("worker: pool has no handler")
+	// ErrWarmup wraps a Config.OnWorkerStart failure. A pool that failed
+	// to warm up returns it from Submit and as the Err of the tasks it
+	// could not run.
+	ErrWarmup = errors.New("worker: worker warmup failed")
+	// ErrQueueFull is returned by Submit under OverflowError when the
+	// queue has no room, and by SubmitWithTimeout when none frees up in
+	// time.
+	ErrQueueFull = errors.New("worker: queue full")
+	// ErrDropped is the Result.Err of a task discarded by the DropNewest
+	// or DropOldest overflow policy.
+	ErrDropped = errors.New("worker: task dropped by overflow policy")
+	// ErrResultTooLarge is the error of an attempt whose value exceeded
+	// Config.MaxResultSize under OversizeFail.
+	ErrResultTooLarge = errors.New("worker: result exceeds max size")
+	// ErrHardTimeout means the worker gave up on a handler that ignored
+	// its cancelled context; see Config.HardTimeoutGrace.
+	ErrHardTimeout = errors.New("worker: task abandoned at hard timeout")
+	// ErrOverResourceLimit means a task's ResourceWeight exceeds the whole
+	// limit for its ResourceKey, or its Weight exceeds Config.MaxWeight,
+	// so it could never be admitted.
+	ErrOverResourceLimit = errors.New("worker: task weight exceeds resource limit")
+	// ErrExpiredBeforeStart is the Err of a task that was still queued at
+	// its deadline under DispatchEarliestDeadline, and so never ran.
+	ErrExpiredBeforeStart = errors.New("worker: deadline exceeded before start")
+	// ErrThrottled is the Err of the EventRetried published when a task is
+	// parked because its ResourceKey is cooling down; see
+	// Config.ThrottleAfter.
+	ErrThrottled = errors.New("worker: resource throttled")
+	// ErrInvalidTask is returned by Validate, and so by Submit, for a task
+	// that could never run as given; the error lists what is wrong.
+	ErrInvalidTask = errors.New("worker: invalid task")
+	// ErrInvalidResult wraps the error of a TypedValidateFunc that
+	// rejected a handler's value; see Validated.
+	ErrInvalidResult = errors.New("worker: invalid result")
+	// ErrPanic wraps the value a handler panicked with.
+	ErrPanic = errors.New("worker: handler panicked")
+
+	// ErrTaskTimeout, ErrTaskCancelled and ErrMaxRetriesExceeded classify
+	// the Err of a failed Result, which is a *TaskError; see TaskError for
+	// which apply when.
+	ErrTaskTimeout        = errors.New("worker: task timed out")
+	ErrTaskCancelled      = errors.New("worker: task cancelled")
+	ErrMaxRetriesExceeded = errors.New("worker: max retries exceeded")
+)
@@ -0,0 +1,168 @@
+package worker
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// EventKind identifies a step in a task's lifecycle.
+type EventKind int
+
+const (
+	// EventEnqueued is published when Submit accepts a task.
+	EventEnqueued EventKind = iota
+	// EventStarted is published before every attempt.
+	EventStarted
+	// EventSucceeded is published when a task's final result has no error.
+	EventSucceeded
+	// EventFailed is published when a task's final result has an error,
+	// including cancelled and dropped tasks.
+	EventFailed
+	// EventRetried is published when a failed attempt is scheduled to run
+	// again.
+	EventRetried
+	// EventDeadLettered is published when a task fails for good; see
+	// Config.DeadLetter.
+	EventDeadLettered
+	// EventQueueAgeExceeded is published when the task at the head of the
+	// queue has waited longer than Config.MaxQueueAge.
+	EventQueueAgeExceeded
+)
+
+var eventKindNames = [...]string{"enqueued", "started", "succeeded", "failed", "retried", "dead_lettered", "queue_age_exceeded"}
+
+func (k EventKind) String() string {
+	if k < 0 || int(k) >= len(eventKindNames) {
+		return "unknown"
+	}
+	return eventKindNames[k]
+}
+
+// TypedEvent describes something that happened to a task.
+type TypedEvent[In, Out any] struct {
+	Kind EventKind
+	Task TypedTask[In]
+	// Attempt is how many attempts the task had made when the event fired.
+	Attempt int
+	// Err is the attempt's error for EventFailed, EventRetried and
+	// EventDeadLettered.
+	Err error
+	// Value is the task's final result for EventSucceeded.
+	Value Out
+	Time  time.Time
+}
+
+// Event is the TypedEvent published by Pool.
+type Event = TypedEvent[string, string]
+
+// eventBus fans events out to subscribers from a single goroutine, so a
+// slow subscriber delays other subscribers but never a worker. Publishing
+// never blocks: once the buffer is full, events are dropped and counted.
+// The goroutine only starts with the first subscriber. Subscribers are
+// called with ctx, which cancel ends when the pool abandons its work.
+type eventBus[In, Out any] struct {
+	mu      sync.Mutex
+	subs    map[int]func(context.Context, TypedEvent[In, Out])
+	nextID  int
+	started bool
+	closed  bool
+	events  chan TypedEvent[In, Out]
+	done    chan struct{} // closed when the dispatcher has exited
+	ctx     context.Context
+	cancel  context.CancelFunc
+	// closeOnce closes events; publish checks closed before sending.
+	closeOnce sync.Once
+
+	dropped atomic.Int64
+}
+
+func newEventBus[In, Out any](buffer int) *eventBus[In, Out] {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &eventBus[In, Out]{
+		subs:   make(map[int]func(context.Context, TypedEvent[In, Out])),
+		events: make(chan TypedEvent[In, Out], max(buffer, 1)),
+		done:   make(chan struct{}),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+func (b *eventBus[In, Out]) subscribe(fn func(context.Context, TypedEvent[In, Out])) (unsubscribe func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.started && !b.closed {
+		b.started = true
+		go b.dispatch()
+	}
+	id := b.nextID
+	b.nextID++
+	b.subs[id] = fn
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		delete(b.subs, id)
+	}
+}
+
+func (b *eventBus[In, Out]) publish(e TypedEvent[In, Out]) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed || len(b.subs) == 0 {
+		return
+	}
+	select {
+	case b.events <- e:
+	default:
+		b.dropped.Add(1)
+	}
+}
+
+func (b *eventBus[In, Out]) dispatch() {
+	defer close(b.done)
+	var subs []func(context.Context, TypedEvent[In, Out])
+	for e := range b.events {
+		b.mu.Lock()
+		subs = subs[:0]
+		for _, fn := range b.subs {
+			subs = append(subs, fn)
+		}
+		b.mu.Unlock()
+		for _, fn := range subs {
+			fn(b.ctx, e)
+		}
+	}
+}
+
+// close stops accepting events and waits for the buffered ones to be
+// delivered. It leaves ctx alone, so subscribers finish normally.
+func (b *eventBus[In, Out]) close() {
+	b.mu.Lock()
+	started := b.started
+	b.closed = true
+	safeClose(&b.closeOnce, b.events)
+	b.mu.Unlock()
+	if started {
+		<-b.done
+	}
+}
+
+// Subscribe registers fn to be called with every task lifecycle event the
+// pool publishes from now on, and returns a function that removes it.
+// Callbacks run one at a time on the pool's event goroutine, never on a
+// worker; events that arrive while Config.EventBuffer is full are dropped
+// and counted in Stats().EventsDropped. Close delivers the events still
+// buffered before it returns.
+//
+// ctx is cancelled when the pool abandons its work, by Shutdown or by a
+// Drain whose deadline passed. Callbacks that do slow work, such as an HTTP
+// request, must bound it by ctx: Close waits for them, and events
+// delivered after the cancellation come with ctx already done.
+func (p *TypedPool[In, Out]) Subscribe(fn func(ctx context.Context, e TypedEvent[In, Out])) (unsubscribe func()) {
+	return p.events.subscribe(fn)
+}
+
+func (p *TypedPool[In, Out]) publish(kind EventKind, j job[In], err error) {
+	p.events.publish(TypedEvent[In, Out]{Kind: kind, Task: j.task, Attempt: j.attempts, Err: err, Time: time.Now()})
+}
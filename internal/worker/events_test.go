@@ -0,0 +1,124 @@
+package worker_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rajatx185/golang-scalable-background-job-system/internal/worker"
+)
+
+func TestLifecycleEvents(t *testing.T) {
+	var mu sync.Mutex
+	calls := 0
+	pool := worker.NewPool(worker.Config{MaxRetries: 1, IsRetryable: retryAll},
+		func(ctx context.Context, t worker.Task) (string, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			if calls++; calls == 1 {
+				return "", errors.New("try again")
+			}
+			return "ok", nil
+		})
+
+	var kinds []worker.EventKind
+	pool.Subscribe(func(_ context.Context, e worker.Event) {
+		if e.Task.ID == "a" {
+			kinds = append(kinds, e.Kind)
+		}
+	})
+	var unsubscribedCalls int
+	unsubscribe := pool.Subscribe(func(context.Context, worker.Event) { unsubscribedCalls++ })
+	unsubscribe()
+
+	pool.Process(context.Background(), []worker.Task{{ID: "a"}})
+	pool.Close()
+
+	// Close has delivered everything, so kinds is safe to read.
+	want := []worker.EventKind{worker.EventEnqueued, worker.EventStarted, worker.EventRetried, worker.EventStarted, worker.EventSucceeded}
+	if !slices.Equal(kinds, want) {
+		t.Fatalf("events = %v, want %v", kinds, want)
+	}
+	if unsubscribedCalls != 0 {
+		t.Fatalf("unsubscribed callback ran %d times", unsubscribedCalls)
+	}
+}
+
+func TestEnqueuedComesFirst(t *testing.T) {
+	// Submit publishes Enqueued from the queue's callback; a worker that
+	// could take the job before it ran could publish Started first.
+	for range 20 {
+		if !worker.QueuedBeforeTaken() {
+			t.Fatal("worker took a job before its queued callback ran")
+		}
+	}
+}
+
+func TestSlowSubscriberDropsEvents(t *testing.T) {
+	block := make(chan struct{})
+	pool := worker.NewPool(worker.Config{Workers: 2, QueueSize: 8, EventBuffer: 1},
+		func(ctx context.Context, t worker.Task) (string, error) { return "", nil })
+	pool.Subscribe(func(context.Context, worker.Event) { <-block })
+
+	ctx := context.Background()
+	pool.Start(ctx)
+	for i := range 8 {
+		if err := pool.Submit(ctx, worker.Task{ID: fmt.Sprint(i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// The workers finish everything even though the subscriber is stuck.
+	n := 0
+	for range pool.Results() {
+		if n++; n == 8 {
+			break
+		}
+	}
+	if dropped := pool.Stats().EventsDropped; dropped == 0 {
+		t.Fatal("no events dropped with a stuck subscriber and a one-event buffer")
+	}
+	close(block)
+	pool.Close()
+}
+
+func TestShutdownCancelsSubscriber(t *testing.T) {
+	pool := worker.NewPool(worker.Config{}, func(ctx context.Context, t worker.Task) (string, error) { return "", nil })
+	entered := make(chan struct{}, 1)
+	var subErr error
+	pool.Subscribe(func(ctx context.Context, e worker.Event) {
+		if e.Kind != worker.EventEnqueued {
+			return
+		}
+		entered <- struct{}{}
+		// Stands in for a webhook POST to an endpoint that never answers.
+		<-ctx.Done()
+		subErr = ctx.Err()
+	})
+	pool.Start(context.Background())
+	go func() {
+		for range pool.Results() {
+		}
+	}()
+	if err := pool.Submit(context.Background(), worker.Task{ID: "a"}); err != nil {
+		t.Fatal(err)
+	}
+	<-entered
+
+	done := make(chan struct{})
+	go func() {
+		pool.Shutdown(context.Background())
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Shutdown waited on a subscriber instead of cancelling it")
+	}
+	if !errors.Is(subErr, context.Canceled) {
+		t.Fatalf("subscriber ctx err = %v, want context.Canceled", subErr)
+	}
+}
@@ -0,0 +1,138 @@
+package worker
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rajatx185/golang-scalable-background-job-system/internal/clock"
+)
+
+// ProcessOne exposes processOne to the external tests.
+func ProcessOne(p *Pool, ctx context.Context, t Task) Result {
+	return p.processOne(ctx, t)
+}
+
+// RetryDelay exposes the capped backoff the pool applies after attempt.
+func RetryDelay(p *Pool, attempt int) time.Duration {
+	return p.backoff(attempt)
+}
+
+// RelayRetries schedules n retries, the i-th due delay(i) from now, and
+// returns once every one is back on the main queue, with how many more
+// goroutines were running once all were scheduled.
+func RelayRetries(n int, delay func(i int) time.Duration) (goroutines int) {
+	before := runtime.NumGoroutine()
+	q := newRetryQueue[string](clock.Real())
+	out := newTaskQueue[string](n, 1, 1)
+	stop, exited := make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(exited)
+		q.run(context.Background(), out, stop)
+	}()
+	now := time.Now()
+	for i := range n {
+		q.push(job[string]{}, now.Add(delay(i)))
+	}
+	goroutines = runtime.NumGoroutine() - before
+	for range n {
+		out.pop(0)
+	}
+	close(stop)
+	<-exited
+	return goroutines
+}
+
+// RelayRetriesNaive is RelayRetries with a goroutine sleeping out each
+// retry's delay, the baseline retryQueue improves on.
+func RelayRetriesNaive(n int, delay func(i int) time.Duration) (goroutines int) {
+	before := runtime.NumGoroutine()
+	out := newTaskQueue[string](n, 1, 1)
+	for i := range n {
+		go func() {
+			time.Sleep(delay(i))
+			out.pushRetry(job[string]{})
+		}()
+	}
+	goroutines = runtime.NumGoroutine() - before
+	for range n {
+		out.pop(0)
+	}
+	return goroutines
+}
+
+// LatencyEMA folds samples, in order, into a moving average weighted by
+// alpha and returns it.
+func LatencyEMA(alpha float64, samples ...time.Duration) (time.Duration, bool) {
+	e := newLatencyEMA(alpha)
+	for _, d := range samples {
+		e.observe(d)
+	}
+	return e.value()
+}
+
+// Summarize is the Summary Run would return for results.
+func Summarize(results []Result, wall time.Duration) Summary {
+	return summarize(results, wall)
+}
+
+// Deliver hands r to p's Results the way a finished task does.
+func Deliver(p *Pool, r Result) {
+	p.deliver(r)
+}
+
+// TakeRetriesWhilePushing pushes n due retries from n goroutines while
+// taking everything waiting over and over, and returns how many jobs were
+// taken in all.
+func TakeRetriesWhilePushing(n int) (taken int) {
+	q := newRetryQueue[string](clock.Real())
+	var pushed sync.WaitGroup
+	for range n {
+		pushed.Go(func() { q.push(job[string]{}, time.Now()) })
+	}
+	done := make(chan struct{})
+	go func() {
+		pushed.Wait()
+		close(done)
+	}()
+	for {
+		select {
+		case <-done:
+			return taken + len(q.takeAll())
+		default:
+			taken += len(q.takeAll())
+		}
+	}
+}
+
+// QueuedBeforeTaken pushes a job onto a queue a worker is already waiting
+// on, and reports whether the push's queued callback had run by the time
+// the worker took the job.
+func QueuedBeforeTaken() bool {
+	q := newTaskQueue[string](1, 1, 1)
+	var queued atomic.Bool
+	taken := make(chan bool)
+	go func() {
+		q.pop(1)
+		taken <- queued.Load()
+	}()
+	for {
+		q.mu.Lock()
+		idle := q.idlers > 0
+		q.mu.Unlock()
+		if idle {
+			break
+		}
+		runtime.Gosched()
+	}
+	if _, err := q.push(context.Background(), job[string]{}, OverflowBlock, func() {
+		// Give the worker every chance to take the job first.
+		time.Sleep(time.Millisecond)
+		queued.Store(true)
+	}); err != nil {
+		panic(err)
+	}
+	return <-taken
+}
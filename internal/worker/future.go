@@ -0,0 +1,70 @@
+package worker
+
+import "context"
+
+// TypedFuture is the pending result of one task submitted with
+// SubmitFuture. It is safe for concurrent use: every Get returns the same
+// result.
+type TypedFuture[Out any] struct {
+	id string
+	c  *completion[Out]
+}
+
+// Future is the TypedFuture returned by Pool.
+type Future = TypedFuture[string]
+
+// ID is the ID of the task the future is for.
+func (f *TypedFuture[Out]) ID() string { return f.id }
+
+// Done is closed once the task has its final result.
+func (f *TypedFuture[Out]) Done() <-chan struct{} { return f.c.done }
+
+// Get blocks until the task has its final result and returns it, or
+// returns ctx.Err() if ctx is done first. It returns ErrClosed if the task
+// was handed off with Persist.
+func (f *TypedFuture[Out]) Get(ctx context.Context) (TypedResult[Out], error) {
+	select {
+	case <-f.c.done:
+		return f.c.res, f.c.err
+	case <-ctx.Done():
+		return TypedResult[Out]{}, ctx.Err()
+	}
+}
+
+// SubmitFuture is Submit that also returns a future for the task's
+// result, so the caller can wait for that one task without picking it out
+// of Results by ID. The result is still delivered on Results as well,
+// which must be ranged over as usual. The future holds on to the result
+// for as long as the caller keeps it, regardless of Config.WaitRetention.
+// The task is never spilled to Config.OverflowPool.
+func (p *TypedPool[In, Out]) SubmitFuture(ctx context.Context, t TypedTask[In]) (*TypedFuture[Out], error) {
+	c, err := p.submit(ctx, t, submitOpts{noSpill: true})
+	if err != nil {
+		return nil, err
+	}
+	return &TypedFuture[Out]{id: t.ID, c: c}, nil
+}
+
+// SubmitWithCancel is Submit that also returns a func cancelling this one
+// task, for a caller that holds the submission rather than the ID. If the
+// task is still queued, cancel takes it out of the queue and reports it
+// with context.Canceled without running it. If it is running, cancel
+// cancels its context and it is not retried; if it is backing off, it is
+// reported once the backoff ends. Calling cancel after the task has its
+// final result does nothing, even if its ID has been submitted again
+// since. Like SubmitFuture, the task is never spilled to
+// Config.OverflowPool.
+func (p *TypedPool[In, Out]) SubmitWithCancel(ctx context.Context, t TypedTask[In]) (cancel context.CancelFunc, err error) {
+	c, err := p.submit(ctx, t, submitOpts{noSpill: true})
+	if err != nil {
+		return nil, err
+	}
+	return func() {
+		if current, ok := p.waiters.get(t.ID); !ok || current != c || c.finished() {
+			return
+		}
+		if p.tracker.cancel(t.ID) {
+			p.dropCancelled(map[string]bool{t.ID: true})
+		}
+	}, nil
+}
@@ -0,0 +1,110 @@
+package worker_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/rajatx185/golang-scalable-background-job-system/internal/worker"
+)
+
+func TestSubmitFuture(t *testing.T) {
+	release := make(chan struct{})
+	pool := worker.NewPool(worker.Config{Workers: 2}, func(ctx context.Context, task worker.Task) (string, error) {
+		<-release
+		return "hello " + task.Data, nil
+	})
+	pool.Start(context.Background())
+	go func() {
+		for range pool.Results() {
+		}
+	}()
+
+	future, err := pool.SubmitFuture(context.Background(), worker.Task{ID: "a", Data: "world"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-future.Done():
+		t.Fatal("Done closed before the task ran")
+	default:
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := future.Get(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Get with a cancelled ctx: %v, want Canceled", err)
+	}
+
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res, err := future.Get(context.Background())
+			if err != nil || res.ID != "a" || res.Value != "hello world" {
+				t.Errorf("Get = %+v, %v", res, err)
+			}
+		}()
+	}
+	close(release)
+	wg.Wait()
+	<-future.Done()
+	pool.Close()
+
+	if _, err := pool.SubmitFuture(context.Background(), worker.Task{ID: "late"}); !errors.Is(err, worker.ErrClosed) {
+		t.Fatalf("SubmitFuture after Close: %v, want ErrClosed", err)
+	}
+}
+
+func TestSubmitWithCancel(t *testing.T) {
+	started := make(chan struct{})
+	var ran atomic.Int64
+	pool := worker.NewPool(worker.Config{Workers: 1, QueueSize: 4}, func(ctx context.Context, t worker.Task) (string, error) {
+		ran.Add(1)
+		if t.ID == "running" {
+			close(started)
+			<-ctx.Done()
+			return "", ctx.Err()
+		}
+		return "ok", nil
+	})
+	pool.Start(context.Background())
+	defer pool.Close()
+	go func() {
+		for range pool.Results() {
+		}
+	}()
+
+	cancelRunning, err := pool.SubmitWithCancel(context.Background(), worker.Task{ID: "running"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cancelQueued, err := pool.SubmitWithCancel(context.Background(), worker.Task{ID: "queued"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-started
+	// The one worker is busy, so this one is still waiting in the queue.
+	cancelQueued()
+	if r, err := pool.Wait(context.Background(), "queued"); err != nil || !errors.Is(r.Err, context.Canceled) {
+		t.Fatalf("queued: %v, %v, want context.Canceled while the worker is still busy", r.Err, err)
+	}
+	cancelRunning()
+	if r, err := pool.Wait(context.Background(), "running"); err != nil || !errors.Is(r.Err, context.Canceled) {
+		t.Fatalf("running: %v, %v, want context.Canceled", r.Err, err)
+	}
+	if n := ran.Load(); n != 1 {
+		t.Fatalf("handler ran %d times, want once: the queued task never runs", n)
+	}
+
+	// A stale cancel leaves a later task with the same ID alone.
+	if err := pool.Submit(context.Background(), worker.Task{ID: "queued"}); err != nil {
+		t.Fatal(err)
+	}
+	cancelQueued()
+	if r, err := pool.Wait(context.Background(), "queued"); err != nil || r.Err != nil {
+		t.Fatalf("resubmitted: %v, %v, want it to run despite the old cancel", r.Err, err)
+	}
+}
@@ -0,0 +1,51 @@
+package worker
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/rajatx185/golang-scalable-background-job-system/internal/semaphore"
+)
+
+// goroutineBudget counts the goroutines that run tasks — workers, plus the
+// handler goroutines HardTimeoutGrace spawns — against Config.MaxGoroutines.
+// Workers are always admitted, since the pool runs a fixed number of them;
+// handler goroutines wait for one of the slots the workers leave.
+type goroutineBudget struct {
+	n        atomic.Int64
+	handlers *semaphore.Semaphore // nil means unlimited
+}
+
+func newGoroutineBudget(limit, workers int) *goroutineBudget {
+	b := &goroutineBudget{}
+	if limit > 0 {
+		b.handlers = semaphore.New(limit - workers)
+	}
+	return b
+}
+
+// add counts a worker, which may not be refused; done uncounts it.
+func (b *goroutineBudget) add()  { b.n.Add(1) }
+func (b *goroutineBudget) done() { b.n.Add(-1) }
+
+// acquire waits for room for one more handler goroutine, or for ctx.
+func (b *goroutineBudget) acquire(ctx context.Context) error {
+	if b.handlers != nil && !b.handlers.TryAcquire() {
+		if err := b.handlers.Acquire(ctx); err != nil {
+			return err
+		}
+	}
+	b.n.Add(1)
+	return nil
+}
+
+func (b *goroutineBudget) release() {
+	b.n.Add(-1)
+	if b.handlers != nil {
+		b.handlers.Release()
+	}
+}
+
+func (b *goroutineBudget) count() int {
+	return int(b.n.Load())
+}
@@ -0,0 +1,73 @@
+package worker
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/rajatx185/golang-scalable-background-job-system/internal/store"
+)
+
+// HandlerStats describes the attempts made for one Task.Type.
+type HandlerStats struct {
+	// InFlight is how many attempts are in the handler right now.
+	InFlight int64
+	// Processed counts finished attempts, failed ones included.
+	Processed int64
+	// Failed counts finished attempts that returned an error.
+	Failed int64
+	// ErrorRate is Failed over Processed, or zero before the first
+	// attempt finishes.
+	ErrorRate float64
+	// AvgLatency is the mean time a finished attempt spent in the
+	// handler.
+	AvgLatency time.Duration
+}
+
+// handlerCounters are the running totals behind one HandlerStats.
+type handlerCounters struct {
+	inFlight, processed, failed atomic.Int64
+	latency                     atomic.Int64 // summed, in nanoseconds
+}
+
+// handlerStats keeps a handlerCounters per Task.Type, made on first use.
+type handlerStats struct {
+	byType *store.Store[*handlerCounters]
+}
+
+func newHandlerStats() *handlerStats {
+	return &handlerStats{byType: store.NewStore[*handlerCounters]()}
+}
+
+// begin counts an attempt of a taskType task entering the handler, and
+// returns the func that counts it out again.
+func (h *handlerStats) begin(taskType string) (end func(elapsed time.Duration, err error)) {
+	c, ok := h.byType.Get(taskType)
+	if !ok {
+		c, _ = h.byType.GetOrSet(taskType, &handlerCounters{})
+	}
+	c.inFlight.Add(1)
+	return func(elapsed time.Duration, err error) {
+		c.latency.Add(int64(elapsed))
+		if err != nil {
+			c.failed.Add(1)
+		}
+		c.processed.Add(1)
+		c.inFlight.Add(-1)
+	}
+}
+
+// snapshot returns the stats of every type seen so far. The counters of a
+// type are read one at a time, so under load they may be an attempt apart.
+func (h *handlerStats) snapshot() map[string]HandlerStats {
+	out := make(map[string]HandlerStats, h.byType.Len())
+	h.byType.Range(func(taskType string, c *handlerCounters) bool {
+		s := HandlerStats{InFlight: c.inFlight.Load(), Processed: c.processed.Load(), Failed: c.failed.Load()}
+		if s.Processed > 0 {
+			s.ErrorRate = float64(s.Failed) / float64(s.Processed)
+			s.AvgLatency = time.Duration(c.latency.Load() / s.Processed)
+		}
+		out[taskType] = s
+		return true
+	})
+	return out
+}
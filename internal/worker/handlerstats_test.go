@@ -0,0 +1,62 @@
+package worker_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rajatx185/golang-scalable-background-job-system/internal/worker"
+)
+
+func TestStatsPerHandlerType(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{})
+	pool := worker.NewPool(worker.Config{Workers: 4}, func(ctx context.Context, t worker.Task) (string, error) {
+		switch t.Type {
+		case "resize":
+			time.Sleep(10 * time.Millisecond)
+			if t.ID == "r2" {
+				return "", errors.New("corrupt image")
+			}
+		case "report":
+			close(started)
+			<-release
+		}
+		return "ok", nil
+	})
+	pool.Start(context.Background())
+	defer pool.Close()
+	go func() {
+		for range pool.Results() {
+		}
+	}()
+	for _, task := range []worker.Task{
+		{ID: "r1", Type: "resize"},
+		{ID: "r2", Type: "resize"},
+		{ID: "rep", Type: "report"},
+		{ID: "plain"},
+	} {
+		if err := pool.Submit(context.Background(), task); err != nil {
+			t.Fatal(err)
+		}
+	}
+	<-started
+	for _, id := range []string{"r1", "r2", "plain"} {
+		if _, err := pool.Wait(context.Background(), id); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	stats := pool.Stats().Handlers
+	if s := stats["resize"]; s.Processed != 2 || s.Failed != 1 || s.ErrorRate != 0.5 || s.AvgLatency < 10*time.Millisecond {
+		t.Errorf("resize: %+v, want 2 processed at a 0.5 error rate, averaging 10ms or more", s)
+	}
+	if s := stats["report"]; s.InFlight != 1 || s.Processed != 0 {
+		t.Errorf("report: %+v, want one in flight and none processed", s)
+	}
+	if s := stats[""]; s.Processed != 1 || s.Failed != 0 {
+		t.Errorf("untyped: %+v, want one processed", s)
+	}
+	close(release)
+}
@@ -0,0 +1,92 @@
+package worker
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// Persist hands the pool's waiting work over to a successor process: it
+// stops accepting tasks, removes every task still queued or backing off
+// and writes them to path in the Export format. The removed tasks produce
+// no Result here; the successor runs them after Load. Tasks already
+// running are left alone — Close the pool afterwards to let them finish —
+// and one that fails is retried in this process, since it was not handed
+// off.
+//
+// The file is written to a temporary name and renamed into place, so a
+// crash mid-write leaves either the previous file or none, never a torn
+// one. If the write fails the tasks are lost with it; the error says so.
+func (p *TypedPool[In, Out]) Persist(path string) error {
+	p.mu.Lock()
+	if p.closed || p.handedOff.Load() {
+		p.mu.Unlock()
+		return ErrClosed
+	}
+	p.handedOff.Store(true)
+	queued, retrying := p.queue.takeAll(), p.retries.takeAll()
+	data, err := p.encodeSnapshot(queued, retrying)
+	p.mu.Unlock()
+
+	for _, j := range queued {
+		p.handOff(j)
+	}
+	for _, item := range retrying {
+		p.handOff(item.job)
+	}
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, data)
+}
+
+// handOff settles a task that Persist moved out of the pool.
+func (p *TypedPool[In, Out]) handOff(j job[In]) {
+	p.tracker.finish(j.task.ID)
+	p.subtrees.finish(j.task.ID)
+	p.waiters.resolve(j.task.ID, TypedResult[Out]{}, ErrClosed)
+	p.batches.settle(j.task.ID, nil)
+	p.pending.Done()
+}
+
+// Load imports the tasks a predecessor handed off with Persist and removes
+// the file, so a later restart does not run them twice. A missing file
+// means there is nothing to hand over and is not an error. Like Import, it
+// only works on a fresh pool.
+func (p *TypedPool[In, Out]) Load(path string) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := p.Import(data); err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
+
+func writeFileAtomic(path string, data []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	defer os.Remove(tmp) // no-op once renamed
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	// Flush to disk before the rename makes the file visible.
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,59 @@
+package worker_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rajatx185/golang-scalable-background-job-system/internal/worker"
+)
+
+func TestPersistLoadHandsOffQueue(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queue.json")
+	echo := func(ctx context.Context, t worker.Task) (string, error) { return t.Data, nil }
+	ctx := context.Background()
+
+	old := worker.NewPool(worker.Config{QueueSize: 3}, echo)
+	for _, id := range []string{"a", "b", "c"} {
+		if err := old.Submit(ctx, worker.Task{ID: id, Data: id}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := old.Persist(path); err != nil {
+		t.Fatalf("Persist: %v", err)
+	}
+	if err := old.Submit(ctx, worker.Task{ID: "late"}); !errors.Is(err, worker.ErrClosed) {
+		t.Fatalf("Submit after Persist: err = %v, want ErrClosed", err)
+	}
+	old.Start(ctx)
+	old.Close() // nothing left to run
+	if matches, _ := filepath.Glob(path + ".tmp-*"); len(matches) > 0 {
+		t.Fatalf("temporary files left behind: %v", matches)
+	}
+
+	next := worker.NewPool(worker.Config{QueueSize: 3}, echo)
+	if err := next.Load(path); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("handoff file still present after Load: %v", err)
+	}
+	next.Start(ctx)
+	go next.Close()
+	got := map[string]string{}
+	for r := range next.Results() {
+		got[r.ID] = r.Value
+	}
+	if len(got) != 3 || got["a"] != "a" || got["b"] != "b" || got["c"] != "c" {
+		t.Fatalf("results after handoff = %v", got)
+	}
+}
+
+func TestLoadWithoutHandoffFile(t *testing.T) {
+	pool := worker.NewPool(worker.Config{}, func(ctx context.Context, t worker.Task) (string, error) { return "", nil })
+	if err := pool.Load(filepath.Join(t.TempDir(), "missing.json")); err != nil {
+		t.Fatalf("Load of missing file: %v", err)
+	}
+}
@@ -0,0 +1,108 @@
+package worker
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/rajatx185/golang-scalable-background-job-system/internal/store"
+)
+
+// StuckWorker describes a worker whose current task has not shown a sign of
+// life for longer than Config.StuckAfter.
+type StuckWorker struct {
+	WorkerID      int
+	TaskID        string
+	LastHeartbeat time.Time
+}
+
+type heartbeatKey struct{}
+
+// Heartbeat tells the pool that the task running under ctx is still making
+// progress. Handlers that can legitimately run longer than
+// Config.StuckAfter should call it periodically; it is a no-op outside a
+// pool-managed context.
+func Heartbeat(ctx context.Context) {
+	if beat, ok := ctx.Value(heartbeatKey{}).(func()); ok {
+		beat()
+	}
+}
+
+type heartbeat struct {
+	taskID string // empty while the worker is idle
+	at     time.Time
+}
+
+// heartbeats keeps each running worker's latest heartbeat in a store keyed
+// by worker ID and the set of workers the monitor last found stuck.
+type heartbeats struct {
+	beats *store.Store[heartbeat]
+
+	mu    sync.Mutex
+	stuck []StuckWorker
+}
+
+func newHeartbeats() *heartbeats {
+	return &heartbeats{beats: store.NewStore[heartbeat]()}
+}
+
+// beat records that workerID is alive and working on taskID ("" for idle).
+func (h *heartbeats) beat(workerID int, taskID string) {
+	h.beats.Set(strconv.Itoa(workerID), heartbeat{taskID: taskID, at: time.Now()})
+}
+
+// withBeat returns ctx carrying the callback Heartbeat invokes.
+func (h *heartbeats) withBeat(ctx context.Context, workerID int, taskID string) context.Context {
+	return context.WithValue(ctx, heartbeatKey{}, func() { h.beat(workerID, taskID) })
+}
+
+// monitor flags busy workers whose heartbeat is older than stuckAfter,
+// checking every interval until stop is closed.
+func (h *heartbeats) monitor(stuckAfter, interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-ticker.C:
+			var stuck []StuckWorker
+			h.beats.Range(func(key string, hb heartbeat) bool {
+				if hb.taskID != "" && now.Sub(hb.at) > stuckAfter {
+					id, _ := strconv.Atoi(key)
+					stuck = append(stuck, StuckWorker{WorkerID: id, TaskID: hb.taskID, LastHeartbeat: hb.at})
+				}
+				return true
+			})
+			// Range visits keys in no particular order.
+			slices.SortFunc(stuck, func(a, b StuckWorker) int { return cmp.Compare(a.WorkerID, b.WorkerID) })
+			h.mu.Lock()
+			h.stuck = stuck
+			h.mu.Unlock()
+		}
+	}
+}
+
+func (h *heartbeats) stuckWorkers() []StuckWorker {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]StuckWorker(nil), h.stuck...)
+}
+
+// startMonitor runs the stuck-worker monitor if Config.StuckAfter is set and
+// returns a func that stops it.
+func (p *TypedPool[In, Out]) startMonitor() (stop func()) {
+	if p.cfg.StuckAfter <= 0 {
+		return func() {}
+	}
+	interval := p.cfg.HeartbeatInterval
+	if interval <= 0 {
+		interval = p.cfg.StuckAfter / 2
+	}
+	done := make(chan struct{})
+	go p.heartbeats.monitor(p.cfg.StuckAfter, interval, done)
+	return func() { close(done) }
+}
@@ -0,0 +1,47 @@
+package worker_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rajatx185/golang-scalable-background-job-system/internal/worker"
+)
+
+func TestStuckWorkerReportedInStats(t *testing.T) {
+	release := make(chan struct{})
+	pool := worker.NewPool(worker.Config{Workers: 2, StuckAfter: 30 * time.Millisecond, HeartbeatInterval: 5 * time.Millisecond},
+		func(ctx context.Context, t worker.Task) (string, error) {
+			if t.ID == "hung" {
+				<-release // never heartbeats
+				return "", nil
+			}
+			// Long-running but healthy: keeps beating.
+			for i := 0; i < 20; i++ {
+				worker.Heartbeat(ctx)
+				time.Sleep(5 * time.Millisecond)
+			}
+			return "", nil
+		})
+
+	done := make(chan struct{})
+	go func() {
+		pool.Process(context.Background(), []worker.Task{{ID: "hung"}, {ID: "busy"}})
+		close(done)
+	}()
+
+	deadline := time.Now().Add(time.Second)
+	var stuck []worker.StuckWorker
+	for time.Now().Before(deadline) {
+		if stuck = pool.Stats().StuckWorkers; len(stuck) > 0 {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	close(release)
+	<-done
+
+	if len(stuck) != 1 || stuck[0].TaskID != "hung" {
+		t.Fatalf("StuckWorkers = %+v, want only the hung task", stuck)
+	}
+}
@@ -0,0 +1,117 @@
+package worker
+
+import (
+	"context"
+	"runtime"
+	"strconv"
+	"sync"
+)
+
+// TaskKind says what a task spends its time on, for a TypedKindPool to
+// route it to workers sized for that.
+type TaskKind int
+
+const (
+	// KindCPU tasks keep a core busy for as long as they run, so more of
+	// them at once than GOMAXPROCS only adds contention. It is the zero
+	// Kind.
+	KindCPU TaskKind = iota
+	// KindIO tasks spend most of their time waiting on the network or
+	// disk, so many can run at once on few cores.
+	KindIO
+)
+
+func (k TaskKind) String() string {
+	switch k {
+	case KindCPU:
+		return "cpu"
+	case KindIO:
+		return "io"
+	}
+	return "TaskKind(" + strconv.Itoa(int(k)) + ")"
+}
+
+// IOWorkersPerCPU is how many I/O workers NewKindPool starts per
+// GOMAXPROCS when it isn't told how many.
+const IOWorkersPerCPU = 16
+
+// TypedKindPool runs CPU-bound and I/O-bound tasks on two separate
+// TypedPools, routed by Task.Kind, so a burst of slow I/O can't take every
+// worker from the CPU-bound tasks, nor CPU-bound ones leave I/O workers
+// nobody to wait with. Each group has its own queue, Results and Stats;
+// reach them through Group. Children a task submits through its Submitter
+// stay in its group whatever their Kind.
+type TypedKindPool[In, Out any] struct {
+	groups [2]*TypedPool[In, Out] // indexed by TaskKind
+}
+
+// KindPool is the TypedKindPool for string tasks and results.
+type KindPool = TypedKindPool[string, string]
+
+// NewKindPool returns a TypedKindPool whose groups both run process and
+// are configured by cfg, except for Workers: the CPU group gets
+// runtime.GOMAXPROCS(0) of them and the I/O group ioWorkers, or
+// IOWorkersPerCPU times as many as the CPU group if that is zero or less.
+// A QueueSize of zero sizes each group's queue to its own workers.
+func NewKindPool[In, Out any](cfg TypedConfig[In], ioWorkers int, process TypedProcessFunc[In, Out]) *TypedKindPool[In, Out] {
+	cpus := runtime.GOMAXPROCS(0)
+	if ioWorkers <= 0 {
+		ioWorkers = IOWorkersPerCPU * cpus
+	}
+	var p TypedKindPool[In, Out]
+	cfg.Workers = cpus
+	p.groups[KindCPU] = NewPool(cfg, process)
+	cfg.Workers = ioWorkers
+	p.groups[KindIO] = NewPool(cfg, process)
+	return &p
+}
+
+// Group returns the pool that runs tasks of kind k. It panics for a kind
+// other than KindCPU and KindIO.
+func (p *TypedKindPool[In, Out]) Group(k TaskKind) *TypedPool[In, Out] {
+	if k != KindCPU && k != KindIO {
+		panic("worker: no group for " + k.String())
+	}
+	return p.groups[k]
+}
+
+// Start starts both groups under ctx; see TypedPool.Start.
+func (p *TypedKindPool[In, Out]) Start(ctx context.Context) {
+	for _, g := range p.groups {
+		g.Start(ctx)
+	}
+}
+
+// Submit queues t on the group for t.Kind. A Kind that is neither
+// KindCPU nor KindIO fails validation with ErrInvalidTask.
+func (p *TypedKindPool[In, Out]) Submit(ctx context.Context, t TypedTask[In]) error {
+	g := p.groups[KindCPU]
+	if t.Kind == KindIO {
+		g = p.groups[KindIO]
+	}
+	return g.Submit(ctx, t)
+}
+
+// Close closes both groups at once, since each waits for its own Results
+// to be collected; see TypedPool.Close.
+func (p *TypedKindPool[In, Out]) Close() {
+	var wg sync.WaitGroup
+	for _, g := range p.groups {
+		wg.Go(g.Close)
+	}
+	wg.Wait()
+}
+
+// Utilization returns, for each group, the share of its online workers
+// running a task right now, from 0 to 1. A group with no workers online
+// yet reads 0.
+func (p *TypedKindPool[In, Out]) Utilization() map[TaskKind]float64 {
+	out := make(map[TaskKind]float64, len(p.groups))
+	for k, g := range p.groups {
+		out[TaskKind(k)] = 0
+		if s := g.Stats(); s.Workers > 0 {
+			out[TaskKind(k)] = min(float64(s.ActiveWorkers)/float64(s.Workers), 1)
+		}
+	}
+	return out
+}
@@ -0,0 +1,58 @@
+package worker_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/rajatx185/golang-scalable-background-job-system/internal/worker"
+)
+
+func TestKindPoolRoutesByKind(t *testing.T) {
+	release := make(chan struct{})
+	pool := worker.NewKindPool(worker.Config{QueueSize: 16}, 4, func(ctx context.Context, t worker.Task) (string, error) {
+		if t.Kind == worker.KindIO {
+			<-release
+		}
+		return t.Kind.String(), nil
+	})
+	pool.Start(context.Background())
+	waitForWorkers(t, pool.Group(worker.KindCPU), runtime.GOMAXPROCS(0))
+	waitForWorkers(t, pool.Group(worker.KindIO), 4)
+
+	// Every I/O worker is stuck on its task; CPU tasks run regardless.
+	for i := range 4 {
+		if err := pool.Submit(context.Background(), worker.Task{ID: fmt.Sprint("io", i), Kind: worker.KindIO}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := pool.Submit(context.Background(), worker.Task{ID: "cpu"}); err != nil {
+		t.Fatal(err)
+	}
+	for r := range pool.Group(worker.KindCPU).Results() {
+		if r.ID != "cpu" || r.Value != "cpu" {
+			t.Fatalf("CPU group delivered %+v, want the CPU task", r)
+		}
+		break
+	}
+	deadline := time.Now().Add(time.Second)
+	for pool.Utilization()[worker.KindIO] < 1 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if u := pool.Utilization(); u[worker.KindIO] != 1 || u[worker.KindCPU] != 0 {
+		t.Fatalf("Utilization = %v, want the I/O group full and the CPU group idle", u)
+	}
+
+	if err := pool.Submit(context.Background(), worker.Task{ID: "odd", Kind: worker.KindIO + 1}); !errors.Is(err, worker.ErrInvalidTask) {
+		t.Fatalf("Submit with an unknown Kind = %v, want ErrInvalidTask", err)
+	}
+	close(release)
+	go func() {
+		for range pool.Group(worker.KindIO).Results() {
+		}
+	}()
+	pool.Close()
+}
@@ -0,0 +1,101 @@
+package worker
+
+import (
+	"math"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// latencyEMA is an exponential moving average of how long tasks take to
+// process. It is stored as the bits of a float64 count of nanoseconds, NaN
+// until the first sample, so observing one never takes a lock.
+type latencyEMA struct {
+	alpha float64
+	bits  atomic.Uint64
+}
+
+func newLatencyEMA(alpha float64) *latencyEMA {
+	e := &latencyEMA{alpha: alpha}
+	e.bits.Store(math.Float64bits(math.NaN()))
+	return e
+}
+
+// observe folds d into the average; the first sample becomes it outright.
+func (e *latencyEMA) observe(d time.Duration) {
+	for {
+		old := e.bits.Load()
+		avg := math.Float64frombits(old)
+		if math.IsNaN(avg) {
+			avg = float64(d)
+		} else {
+			avg += e.alpha * (float64(d) - avg)
+		}
+		if e.bits.CompareAndSwap(old, math.Float64bits(avg)) {
+			return
+		}
+	}
+}
+
+// value returns the average, or false before the first sample.
+func (e *latencyEMA) value() (time.Duration, bool) {
+	avg := math.Float64frombits(e.bits.Load())
+	if math.IsNaN(avg) {
+		return 0, false
+	}
+	return time.Duration(avg), true
+}
+
+// EMALatency returns the exponential moving average of how long a task's
+// final attempt spent processing, weighted by Config.LatencyEMAAlpha. Cached
+// results and tasks that never ran don't count. It reports false until a
+// task has finished.
+func (p *TypedPool[In, Out]) EMALatency() (time.Duration, bool) {
+	return p.latency.value()
+}
+
+// latencySamples is how many of the latest durations a latencyWindow
+// summarizes.
+const latencySamples = 256
+
+// LatencySummary describes a set of recent durations.
+type LatencySummary struct {
+	// Samples is how many durations the summary is taken over, up to the
+	// latest 256.
+	Samples int
+	Avg     time.Duration
+	P95     time.Duration
+}
+
+// latencyWindow keeps the latest durations in a ring, for Stats to
+// summarize.
+type latencyWindow struct {
+	mu      sync.Mutex
+	samples [latencySamples]time.Duration
+	next    int // slot the next sample goes in
+	n       int
+}
+
+func (w *latencyWindow) record(d time.Duration) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.samples[w.next] = d
+	w.next = (w.next + 1) % len(w.samples)
+	w.n = min(w.n+1, len(w.samples))
+}
+
+func (w *latencyWindow) summary() LatencySummary {
+	w.mu.Lock()
+	sorted := slices.Clone(w.samples[:w.n])
+	w.mu.Unlock()
+	if len(sorted) == 0 {
+		return LatencySummary{}
+	}
+	slices.Sort(sorted)
+	var total time.Duration
+	for _, d := range sorted {
+		total += d
+	}
+	return LatencySummary{Samples: len(sorted), Avg: total / time.Duration(len(sorted)), P95: percentile(sorted, 0.95)}
+}
@@ -0,0 +1,117 @@
+package worker_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rajatx185/golang-scalable-background-job-system/internal/clock"
+	"github.com/rajatx185/golang-scalable-background-job-system/internal/worker"
+)
+
+func TestLatencyEMAWeighsRecentSamples(t *testing.T) {
+	if _, ok := worker.LatencyEMA(0.5); ok {
+		t.Fatal("LatencyEMA reported an average before any sample")
+	}
+	got, ok := worker.LatencyEMA(0.5, 100*time.Millisecond, 200*time.Millisecond, 200*time.Millisecond)
+	if want := 175 * time.Millisecond; !ok || got != want {
+		t.Fatalf("LatencyEMA(0.5, 100ms, 200ms, 200ms) = %v, %v, want %v", got, ok, want)
+	}
+}
+
+func TestEMALatencyTracksFinishedTasks(t *testing.T) {
+	pool := worker.NewPool(worker.Config{Workers: 1, LatencyEMAAlpha: 1},
+		func(ctx context.Context, t worker.Task) (string, error) {
+			time.Sleep(20 * time.Millisecond)
+			return "ok", nil
+		})
+	defer pool.Close()
+	if _, ok := pool.EMALatency(); ok {
+		t.Fatal("EMALatency reported an average before any task finished")
+	}
+	if _, ok := pool.DrainEstimate(); ok {
+		t.Fatal("DrainEstimate reported an estimate with no history at all")
+	}
+
+	pool.Process(context.Background(), []worker.Task{{ID: "a"}})
+	if avg, ok := pool.EMALatency(); !ok || avg < 20*time.Millisecond {
+		t.Fatalf("EMALatency = %v, %v, want at least the 20ms the task took", avg, ok)
+	}
+	// One completion is too few for a throughput, so this is the EMA's.
+	if _, ok := pool.DrainEstimate(); !ok {
+		t.Fatal("DrainEstimate found nothing to go on after a task finished")
+	}
+}
+
+func TestEMALatencyLeavesOutBackoff(t *testing.T) {
+	var calls atomic.Int32
+	pool := worker.NewPool(worker.Config{Workers: 1, LatencyEMAAlpha: 1, MaxRetries: 1, RetryBackoff: 300 * time.Millisecond, IsRetryable: retryAll},
+		func(ctx context.Context, t worker.Task) (string, error) {
+			if calls.Add(1) == 1 {
+				return "", errors.New("flaky")
+			}
+			return "ok", nil
+		})
+	defer pool.Close()
+
+	if res := pool.Process(context.Background(), []worker.Task{{ID: "a"}}); res[0].Err != nil || res[0].Duration < 300*time.Millisecond {
+		t.Fatalf("result = %+v, want a success after the 300ms backoff", res[0])
+	}
+	if avg, ok := pool.EMALatency(); !ok || avg >= 100*time.Millisecond {
+		t.Fatalf("EMALatency = %v, %v, want the instant final attempt, not the backoff", avg, ok)
+
+	}
+}
+
+func TestEMALatencyLeavesOutCachedResults(t *testing.T) {
+	pool := worker.NewPool(worker.Config{Workers: 1, LatencyEMAAlpha: 1, ResultCacheTTL: time.Minute},
+		func(ctx context.Context, t worker.Task) (string, error) {
+			time.Sleep(50 * time.Millisecond)
+			return "ok", nil
+		})
+	defer pool.Close()
+
+	pool.Process(context.Background(), []worker.Task{{ID: "a", IdempotencyKey: "k"}})
+	if res := pool.Process(context.Background(), []worker.Task{{ID: "b", IdempotencyKey: "k"}}); !res[0].Cached {
+		t.Fatalf("second result = %+v, want it served from the cache", res[0])
+	}
+	if avg, ok := pool.EMALatency(); !ok || avg < 50*time.Millisecond {
+		t.Fatalf("EMALatency = %v, %v, want the 50ms attempt untouched by the cache hit", avg, ok)
+	}
+}
+
+func TestQueueLatencyIsSeparateFromProcessTime(t *testing.T) {
+	clk := clock.NewManual(time.Unix(0, 0))
+	pool := worker.NewPool(worker.Config{Workers: 1, QueueSize: 4, Clock: clk},
+		func(ctx context.Context, t worker.Task) (string, error) { return "ok", nil })
+	for _, id := range []string{"a", "b"} {
+		if err := pool.Submit(context.Background(), worker.Task{ID: id}); err != nil {
+			t.Fatal(err)
+		}
+		clk.Advance(time.Second)
+	}
+	// a has waited 5s and b 4s by the time the worker gets to them.
+	clk.Advance(3 * time.Second)
+	pool.Start(context.Background())
+	waited := map[string]time.Duration{}
+	for r := range pool.Results() {
+		if waited[r.ID] = r.QueueLatency; len(waited) == 2 {
+			break
+		}
+	}
+	pool.Close()
+	if waited["a"] != 5*time.Second || waited["b"] != 4*time.Second {
+		t.Fatalf("QueueLatency = %v, want a 5s and b 4s", waited)
+	}
+
+	s := pool.Stats()
+	if want := (worker.LatencySummary{Samples: 2, Avg: 4500 * time.Millisecond, P95: 5 * time.Second}); s.QueueLatency != want {
+		t.Fatalf("Stats.QueueLatency = %+v, want %+v", s.QueueLatency, want)
+	}
+	// Processing is timed in real time, and the handler returned at once.
+	if s.ProcessTime.Samples != 2 || s.ProcessTime.P95 >= time.Second {
+		t.Fatalf("Stats.ProcessTime = %+v, want two quick attempts", s.ProcessTime)
+	}
+}
@@ -0,0 +1,34 @@
+package worker
+
+import "unicode/utf8"
+
+// OversizePolicy decides what happens to a value over Config.MaxResultSize.
+type OversizePolicy int
+
+const (
+	// OversizeTruncate cuts the value to the limit and sets
+	// Result.Truncated.
+	OversizeTruncate OversizePolicy = iota
+	// OversizeFail turns the attempt into a failure with
+	// ErrResultTooLarge, which is retried like any other error.
+	OversizeFail
+)
+
+// limitResult applies Config.MaxResultSize to a successful attempt's value.
+// Only string values have a size; any other Out passes through untouched.
+func (p *TypedPool[In, Out]) limitResult(value Out, err error) (Out, bool, error) {
+	limit := p.cfg.MaxResultSize
+	s, ok := any(value).(string)
+	if err != nil || !ok || limit <= 0 || len(s) <= limit {
+		return value, false, err
+	}
+	if p.cfg.OversizeResult == OversizeFail {
+		var zero Out
+		return zero, false, ErrResultTooLarge
+	}
+	// Back up to a rune boundary so the truncated value stays valid UTF-8.
+	for limit > 0 && !utf8.RuneStart(s[limit]) {
+		limit--
+	}
+	return any(s[:limit]).(Out), true, nil
+}
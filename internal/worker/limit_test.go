@@ -0,0 +1,38 @@
+package worker_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/rajatx185/golang-scalable-background-job-system/internal/worker"
+)
+
+func TestMaxResultSize(t *testing.T) {
+	big := func(ctx context.Context, t worker.Task) (string, error) {
+		return t.Data, nil
+	}
+
+	pool := worker.NewPool(worker.Config{MaxResultSize: 2}, big)
+	results := pool.Process(context.Background(), []worker.Task{{ID: "small", Data: "ok"}, {ID: "big", Data: "héllo"}})
+	for _, r := range results {
+		switch r.ID {
+		case "small":
+			if r.Value != "ok" || r.Truncated {
+				t.Errorf("small result = %+v, want untouched", r)
+			}
+		case "big":
+			// The limit falls inside the two-byte "é", which is dropped
+			// whole.
+			if r.Value != "h" || !r.Truncated || r.Err != nil {
+				t.Errorf("big result = %+v, want truncated to \"h\"", r)
+			}
+		}
+	}
+
+	pool = worker.NewPool(worker.Config{MaxResultSize: 4, OversizeResult: worker.OversizeFail}, big)
+	results = pool.Process(context.Background(), []worker.Task{{ID: "big", Data: "héllo"}})
+	if !errors.Is(results[0].Err, worker.ErrResultTooLarge) {
+		t.Errorf("err = %v, want ErrResultTooLarge", results[0].Err)
+	}
+}
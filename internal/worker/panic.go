@@ -0,0 +1,46 @@
+package worker
+
+import "context"
+
+// PanicPolicy decides what a panicking handler does to its worker.
+type PanicPolicy int
+
+const (
+	// PanicRecover turns the panic into an ErrPanic failure and the worker
+	// carries on with its next task.
+	PanicRecover PanicPolicy = iota
+	// PanicCrash lets the panic propagate, taking the whole program down
+	// with the original stack trace. For deployments that would rather
+	// fail fast and be restarted than run on in an unknown state.
+	PanicCrash
+	// PanicRestart reports the task as an ErrPanic failure like
+	// PanicRecover, then retires the worker goroutine and starts a fresh
+	// one under the same ID, so the pool keeps its configured concurrency
+	// without reusing whatever state the panic left behind. Consume
+	// workers are not restarted; there it behaves like PanicRecover.
+	PanicRestart
+)
+
+// replaceWorker starts a successor for worker id, which must be on its way
+// out.
+func (p *TypedPool[In, Out]) replaceWorker(ctx, runCtx context.Context, id int) {
+	p.restarts.Add(1)
+	p.cfg.Logger.Warn("restarting worker after panic", "worker", id)
+	p.succeed(ctx, runCtx, id)
+}
+
+// succeed warms up and starts worker id: in the place of one on its way
+// out, whose own Done is still pending, or for Reconfigure, which holds
+// off Close; either way Close can't miss the new worker. If the warmup
+// fails, the tasks that would have been the worker's fail with its error
+// instead, so none of them wait forever.
+func (p *TypedPool[In, Out]) succeed(ctx, runCtx context.Context, id int) {
+	p.workers.Add(1)
+	state, err := p.warm(id)
+	if err != nil {
+		p.cfg.Logger.Error("worker warmup failed", "worker", id, "err", err)
+		go p.reap(ctx, runCtx, id, err)
+		return
+	}
+	go p.worker(ctx, runCtx, id, state)
+}
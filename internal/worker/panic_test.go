@@ -0,0 +1,69 @@
+package worker_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/rajatx185/golang-scalable-background-job-system/internal/worker"
+)
+
+func panicky(ctx context.Context, t worker.Task) (string, error) {
+	if t.ID == "boom" {
+		panic("kaboom")
+	}
+	return "ok", nil
+}
+
+func TestPanicPolicyRecoverAndRestart(t *testing.T) {
+	tests := []struct {
+		policy       worker.PanicPolicy
+		wantRestarts int64
+	}{
+		{worker.PanicRecover, 0},
+		{worker.PanicRestart, 1},
+	}
+	for _, tt := range tests {
+		// One worker, so the tasks after boom only run if the pool still
+		// has a worker once it panicked.
+		pool := worker.NewPool(worker.Config{
+			Workers: 1,
+			OnPanic: tt.policy,
+			Logger:  slog.New(slog.NewTextHandler(io.Discard, nil)),
+		}, panicky)
+		results := pool.Process(context.Background(), []worker.Task{{ID: "boom"}, {ID: "a"}, {ID: "b"}})
+
+		for _, r := range results {
+			if r.ID == "boom" && !errors.Is(r.Err, worker.ErrPanic) {
+				t.Errorf("policy %d: panicking task err = %v, want ErrPanic", tt.policy, r.Err)
+			}
+			if r.ID != "boom" && r.Err != nil {
+				t.Errorf("policy %d: task %s err = %v", tt.policy, r.ID, r.Err)
+			}
+		}
+		if n := pool.Stats().WorkerRestarts; n != tt.wantRestarts {
+			t.Errorf("policy %d: WorkerRestarts = %d, want %d", tt.policy, n, tt.wantRestarts)
+		}
+	}
+}
+
+func TestPanicPolicyCrash(t *testing.T) {
+	if os.Getenv("WORKER_PANIC_CRASH") == "1" {
+		pool := worker.NewPool(worker.Config{OnPanic: worker.PanicCrash}, panicky)
+		pool.Process(context.Background(), []worker.Task{{ID: "boom"}})
+		return // unreachable if the policy works
+	}
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestPanicPolicyCrash$")
+	cmd.Env = append(os.Environ(), "WORKER_PANIC_CRASH=1")
+	out, err := cmd.CombinedOutput()
+	var exit *exec.ExitError
+	if !errors.As(err, &exit) || !strings.Contains(string(out), "panic: kaboom") {
+		t.Fatalf("want the process to crash with the handler's panic, got err %v:\n%s", err, out)
+	}
+}
@@ -0,0 +1,1342 @@
+// Package worker runs Tasks on a fixed-size pool of goroutines.
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"log/slog"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rajatx185/golang-scalable-background-job-system/internal/clock"
+	"github.com/rajatx185/golang-scalable-background-job-system/internal/ctxutil"
+	"github.com/rajatx185/golang-scalable-background-job-system/internal/ids"
+)
+
+// TypedProcessFunc handles a single task, turning its In payload into an
+// Out value. ctx is the attempt's own context: it carries the task's
+// timeout as its deadline, so handlers doing I/O should pass it down, to
+// QueryRowContext and the like, and return when it is done. A handler
+// that ignores it is abandoned once Config.HardTimeoutGrace runs out.
+type TypedProcessFunc[In, Out any] func(ctx context.Context, t TypedTask[In]) (Out, error)
+
+// ProcessFunc is the TypedProcessFunc for string tasks and results.
+type ProcessFunc = TypedProcessFunc[string, string]
+
+// TypedConfig controls the size and limits of a TypedPool. It is generic
+// only so that DeadLetter can deliver typed tasks.
+type TypedConfig[In any] struct {
+	// Workers is the number of goroutines processing tasks. Defaults to 1.
+	Workers int
+	// StartupStagger spaces out the workers Start launches: the first
+	// comes online at once and each next one StartupStagger later, on
+	// Clock, so a cold start doesn't hit a downstream with every worker at
+	// the same moment. Stats.Workers shows the ramp-up. If the pool is
+	// shut down first, the rest are launched at once so the tasks pinned
+	// to them by AffinityKey still get a result. Zero launches them all
+	// together.
+	StartupStagger time.Duration
+	// QueueSize is the capacity of the task queue and the buffer of the
+	// results channel. The queue defaults to Workers slots when zero.
+	QueueSize int
+	// Overflow decides what Submit does when the queue is full. The zero
+	// value blocks, except on a pool that hasn't been started, where
+	// nothing would ever make room: Submit returns ErrNoWorkers instead.
+	Overflow OverflowPolicy
+	// OverflowPool, if set, takes the tasks submitted while the queue is
+	// full, ahead of Overflow: Submit hands them to its Submit instead, so
+	// a second, perhaps lower-priority, pool absorbs bursts. A spilled
+	// task belongs to that pool from then on — its Result, Wait and
+	// events are there, not here. SubmitFuture never spills, since the
+	// future couldn't follow the task. See Stats.Spilled.
+	OverflowPool TypedSubmitter[In]
+	// Dispatch decides which queued task runs next. The zero value,
+	// DispatchFIFO, runs them in order; DispatchEarliestDeadline runs the
+	// one due soonest, for work with a latency target.
+	Dispatch DispatchOrder
+	// SubmitBlockWarning is how long a Submit may block on a full queue
+	// before a warning is logged, so a stalled pool shows up in the logs
+	// rather than as a silent hang. It is measured on Clock. Defaults to
+	// 5s; negative disables it.
+	SubmitBlockWarning time.Duration
+	// ResultBlockWarning is how long a worker may block handing a result
+	// to a full Results channel before a warning is logged: workers
+	// stalled on a slow collector otherwise just look like low throughput.
+	// Defaults to 5s; negative disables it. See
+	// Stats.ResultSendBlockedTotal.
+	ResultBlockWarning time.Duration
+	// DepthStep is how far the queue length must move before QueueDepth
+	// reports it again. Defaults to 1, i.e. every change.
+	DepthStep int
+	// DefaultTimeout bounds every task that does not set its own Timeout.
+	//
+	// Precedence: Task.Timeout if non-zero, else DefaultTimeout if
+	// non-zero, else the task runs with no timeout at all. Whichever
+	// applies is cut short by the deadline of the context the task was
+	// submitted with, so a task never outlives the request that queued
+	// it. Children submitted through a Submitter are not bound by their
+	// parent's deadline.
+	DefaultTimeout time.Duration
+	// SlowTaskThreshold flags attempts that take longer than this without
+	// timing out: they are logged with the task and how long they took
+	// and counted in Stats.SlowTasks, but otherwise left alone, so
+	// creeping latency shows up before it turns into timeouts. Zero
+	// disables it.
+	SlowTaskThreshold time.Duration
+	// MaxRetries is how many extra attempts a failing task gets before its
+	// error is reported.
+	MaxRetries int
+	// AttemptHistory is how many of a task's latest attempts
+	// Result.Attempts keeps. Defaults to 10.
+	AttemptHistory int
+	// IsRetryable decides whether a failure is worth another attempt.
+	// Errors it rejects go straight to the dead-letter channel with
+	// ReasonFatal, whatever retries remain. Defaults to
+	// DefaultIsRetryable, which only retries timeouts.
+	IsRetryable func(error) bool
+	// RetryBackoff is the delay before the first retry; it doubles on each
+	// subsequent one. Retries wait off to the side, not on a worker.
+	// Ignored when Backoff is set.
+	RetryBackoff time.Duration
+	// Backoff replaces the RetryBackoff doubling with another schedule,
+	// such as ExponentialJitterBackoff.
+	Backoff BackoffStrategy
+	// MaxDelay caps every retry delay, whichever strategy computed it.
+	// Zero means no cap.
+	MaxDelay time.Duration
+	// RetryBudget caps retries across the whole pool, in retries per
+	// second, so a widespread failure can't spend every worker on retries.
+	// It is a token bucket holding a second's worth: a retry that finds it
+	// empty is held back, past its backoff if need be, until a token has
+	// come in. Stats.RetryBudgetUtilization shows how much is in use. Zero
+	// means no budget.
+	RetryBudget float64
+	// PoisonThreshold stops retrying a task once it has failed this many
+	// attempts in a row with the same error text, on the theory that the
+	// rest of the backoff schedule won't change the outcome. Zero disables
+	// the check.
+	PoisonThreshold int
+	// DeadLetter, if set, receives every task that fails for good.
+	// Cancelled tasks are not dead-lettered. Sends block the worker, so
+	// the channel must be drained or buffered generously.
+	DeadLetter chan<- TypedDeadLetter[In]
+	// DeadLetterRetention is how many of the latest dead letters the pool
+	// keeps, whether or not DeadLetter is set, for ReplayDeadLetters.
+	// Defaults to 1024.
+	DeadLetterRetention int
+	// StuckAfter flags a worker as stuck in Stats once its current task
+	// has gone this long without a heartbeat. Workers beat when they start
+	// a task; long-running handlers keep beating with Heartbeat. Zero
+	// disables the monitor.
+	StuckAfter time.Duration
+	// HeartbeatInterval is how often the monitor checks for stale
+	// heartbeats. Defaults to StuckAfter/2.
+	HeartbeatInterval time.Duration
+	// ShutdownGrace is how long a task already running when the Start
+	// context is cancelled may keep going before its own context is
+	// cancelled too. No new tasks start once shutdown begins. Zero cancels
+	// running tasks immediately.
+	ShutdownGrace time.Duration
+	// MaxResultSize caps the length in bytes of a handler's returned
+	// value so one pathological task can't bloat the results channel.
+	// It only applies to string results. Zero means no limit.
+	MaxResultSize int
+	// OversizeResult decides what happens to a value over MaxResultSize.
+	OversizeResult OversizePolicy
+	// HardTimeoutGrace turns a task's timeout into two stages. At the
+	// timeout (the soft stage) the handler's context is cancelled so it
+	// can clean up; if it still hasn't returned HardTimeoutGrace later,
+	// the worker records ErrHardTimeout and moves on. The abandoned
+	// handler goroutine is not killed — Go can't do that — and leaks
+	// until it honours the cancellation; Stats().Abandoned counts them.
+	// Zero disables the hard stage. It has no effect on tasks without a
+	// timeout.
+	HardTimeoutGrace time.Duration
+	// MaxGoroutines caps the goroutines the pool runs tasks on: its
+	// workers plus the handler goroutines HardTimeoutGrace spawns, which
+	// pile up when abandoned handlers don't return. At the ceiling, a
+	// worker waits for one of them to exit before starting its next
+	// attempt, rather than spawning another. It is raised to Workers+1
+	// if lower, so every worker can make progress. Zero means no
+	// ceiling; see Stats.Goroutines.
+	MaxGoroutines int
+	// ResourceLimits caps how many attempts may run at once against each
+	// Task.ResourceKey, weighted by Task.ResourceWeight. Workers wait for
+	// capacity before calling the handler, independently of how many
+	// workers are idle. An attempt abandoned at its hard timeout gives its
+	// share back even though its handler may still be running.
+	ResourceLimits map[string]int64
+	// MaxWeight caps the total Task.Weight of the attempts running at
+	// once across the pool, so a few heavy tasks can't swamp the machine
+	// just because workers are free. Workers wait for room in FIFO order,
+	// so a heavy task isn't starved by light ones queued behind it. Zero
+	// means no cap; see Stats.InFlightWeight.
+	MaxWeight int
+	// ThrottleAfter and ThrottleCooldown pause a whole ResourceKey when its
+	// downstream looks down: after ThrottleAfter retryable failures in a
+	// row (see IsRetryable), tasks for that key are parked for
+	// ThrottleCooldown, or for EMALatency if that is longer, rather than
+	// run, then re-queued. Parked tasks don't use up attempts. Both must
+	// be set to enable throttling; see Stats.Throttled.
+	ThrottleAfter    int
+	ThrottleCooldown time.Duration
+	// LatencyEMAAlpha weights each finished task's processing time in
+	// EMALatency: closer to 1 follows a change in latency sooner, closer
+	// to 0 smooths over outliers. The average stretches a ResourceKey's
+	// throttle cooldown and stands in for DrainEstimate before there is
+	// throughput to go on. Defaults to 0.2; values over 1 count as 1.
+	LatencyEMAAlpha float64
+	// EventBuffer is how many lifecycle events may wait for subscribers
+	// before new ones are dropped; see Pool.Subscribe. Defaults to 256.
+	EventBuffer int
+	// MaxQueueAge is the longest a task should wait in the queue before a
+	// worker picks it up. Once the task at the head of the queue has
+	// waited longer, an EventQueueAgeExceeded is published for it and a
+	// warning logged; the alert re-arms when the age drops back below.
+	// The age is checked every MaxQueueAge/4 on Clock. Zero disables it;
+	// see also OldestQueuedAge.
+	MaxQueueAge time.Duration
+	// ResultCacheTTL is how long a successful value is reused for other
+	// tasks with the same IdempotencyKey. A task that finds a cached
+	// value doesn't run: it completes at once with that value and
+	// Result.Cached set. Only successes are cached. Zero disables the
+	// cache; see Stats.CacheHitRate.
+	ResultCacheTTL time.Duration
+	// Codec encodes tasks for Export, Persist and Consume. Defaults to
+	// JSONCodec; Enqueue must use the same codec via EnqueueWith.
+	Codec TypedCodec[In]
+	// WaitRetention is how many finished tasks Wait still remembers the
+	// results of. Defaults to 1024.
+	WaitRetention int
+	// ResultRetention is how long Wait still remembers a finished task's
+	// result; expired results are swept every ResultRetention on Clock.
+	// Tasks still queued or running are never evicted. Zero keeps results
+	// until WaitRetention newer ones push them out; see
+	// Stats.RetainedResults.
+	ResultRetention time.Duration
+	// Clock times retry backoff. Defaults to clock.Real; tests can pass a
+	// clock.Manual to release retries without waiting.
+	Clock clock.Clock
+	// IDGenerator makes the IDs SubmitBatch and child submissions give
+	// tasks that have none. Defaults to ids.Random; tests can pass an
+	// ids.Sequence for reproducible IDs.
+	IDGenerator ids.Generator
+	// OnPanic decides what happens to a worker whose handler panics.
+	// Defaults to PanicRecover.
+	OnPanic PanicPolicy
+	// OnWorkerStart, if set, runs once for each worker before it takes
+	// its first task, to do expensive per-worker setup; the state it
+	// returns reaches the handler through WorkerStateFromContext. Start
+	// runs it for every worker, in ID order, before launching any. If one
+	// fails the pool doesn't start: Submit returns the error, which wraps
+	// ErrWarmup, and tasks already queued finish with it. A worker
+	// restarted under PanicRestart warms up again, and one failing then
+	// fails its share of tasks instead. Consume returns the error.
+	OnWorkerStart func(workerID int) (WorkerState, error)
+	// OnWorkerStop, if set, runs once for each worker that warmed up, with
+	// the state OnWorkerStart returned, when that worker exits: at
+	// shutdown, before a PanicRestart successor replaces it, or as a
+	// PanicCrash panic unwinds it. It also tears down the workers that did
+	// warm up when another's warmup fails. Use it to close connections
+	// and flush buffers.
+	OnWorkerStop func(workerID int, state WorkerState)
+	// Logger receives the pool's operational logs. Defaults to
+	// slog.Default(), though LoggerFromContext discards unless it is set.
+	Logger *slog.Logger
+}
+
+// Config is the TypedConfig for string tasks.
+type Config = TypedConfig[string]
+
+// TypedPool fans tasks out to Config.Workers goroutines. In and Out are the
+// payload and result types its handler works with; Pool is the string
+// flavour.
+//
+// Start a pool, Submit tasks while ranging over Results, then Close it;
+// once closed it can't be restarted. Process runs a fixed batch on the
+// pool's workers and can be called any number of times before Close.
+type TypedPool[In, Out any] struct {
+	cfg         TypedConfig[In]
+	process     TypedProcessFunc[In, Out]
+	tracker     tracker
+	heartbeats  *heartbeats
+	handlers    *handlerStats
+	resources   resources
+	weights     *weightedSemaphore
+	events      *eventBus[In, Out]
+	throttle    *throttle
+	subtrees    *subtrees
+	waiters     *waiters[Out]
+	goroutines  *goroutineBudget
+	cache       *resultCache[Out]
+	deadLetters *deadLetterStore[In]
+	retryBudget *retryBudget
+	tunables    tunables
+
+	abandoned atomic.Int64 // handler goroutines left behind by hard timeouts
+	restarts  atomic.Int64 // workers replaced under PanicRestart
+	active    atomic.Int64 // workers running a task
+	online    atomic.Int64 // workers launched and not yet exited
+	succeeded atomic.Int64
+	failed    atomic.Int64
+	// firstAttemptSuccess and eventualSuccess split succeeded by whether
+	// it took a retry.
+	firstAttemptSuccess atomic.Int64
+	eventualSuccess     atomic.Int64
+	resultsBlocked      atomic.Int64 // nanoseconds workers spent on full Results
+	spilled             atomic.Int64 // tasks handed to Config.OverflowPool
+	slowTasks           atomic.Int64 // attempts over Config.SlowTaskThreshold
+	expiredBeforeStart  atomic.Int64 // failed at dispatch under DispatchEarliestDeadline
+
+	queue   *taskQueue[In]
+	retries *retryQueue[In]
+	results chan TypedResult[Out]
+	workers sync.WaitGroup
+	// pending counts submitted tasks that have no final result yet.
+	pending sync.WaitGroup
+
+	ctx    context.Context    // from Start
+	cancel context.CancelFunc // cancels the workers' copy of ctx; see abandon
+	mu     sync.RWMutex
+	closed bool
+	// wound is closed once Close has finished, for the Close calls that
+	// lost the race to it.
+	wound       chan struct{}
+	resultsOnce sync.Once
+	// draining is set by Drain; Submit reports it as ErrDraining rather
+	// than ErrClosed.
+	draining bool
+	// handedOff is set by Persist. It is atomic so child submits can read
+	// it without mu.
+	handedOff atomic.Bool
+	// warmupErr is set by Start if Config.OnWorkerStart failed.
+	warmupErr error
+	// workerCtx and runCtx are what Start runs the workers under, for the
+	// ones Reconfigure adds later; set under mu.
+	workerCtx context.Context
+	runCtx    context.Context
+	stop      func() // stops the goroutines Start launched besides the workers
+	startOnce sync.Once
+	started   atomic.Bool
+	batches   *batches[Out]
+	// shutdownLog collects final dispositions once Shutdown is called.
+	shutdownLog atomic.Pointer[shutdownLog]
+	// sink receives every tracked final result; see WriteResultsTo.
+	sink       atomic.Pointer[TypedResultSink[Out]]
+	throughput throughput
+	rates      rateWindow
+	latency    *latencyEMA
+	// taskLogger is what LoggerFromContext builds on: Config.Logger as
+	// given, or one that discards if it was nil.
+	taskLogger *slog.Logger
+	// queueLatency and processTime sample how long the latest tasks
+	// waited to start and how long their attempts ran.
+	queueLatency latencyWindow
+	processTime  latencyWindow
+}
+
+// Pool is the TypedPool for string tasks and results.
+type Pool = TypedPool[string, string]
+
+// NewPool returns a pool that runs process for every task. A nil process
+// makes a pool that refuses tasks with ErrNoHandler.
+func NewPool[In, Out any](cfg TypedConfig[In], process TypedProcessFunc[In, Out]) *TypedPool[In, Out] {
+	taskLogger := cfg.Logger
+	if taskLogger == nil {
+		taskLogger = slog.New(slog.DiscardHandler)
+	}
+	cfg = cfg.withDefaults()
+	capacity := cfg.QueueSize
+	if capacity <= 0 {
+		capacity = cfg.Workers
+	}
+	latency := newLatencyEMA(cfg.LatencyEMAAlpha)
+	p := &TypedPool[In, Out]{
+		cfg:         cfg,
+		process:     process,
+		tracker:     newTracker(),
+		heartbeats:  newHeartbeats(),
+		handlers:    newHandlerStats(),
+		resources:   newResources(cfg.ResourceLimits),
+		weights:     newWeightLimit(cfg.MaxWeight),
+		events:      newEventBus[In, Out](cfg.EventBuffer),
+		throttle:    newThrottle(cfg.ThrottleAfter, cfg.ThrottleCooldown, cfg.Clock, latency),
+		latency:     latency,
+		taskLogger:  taskLogger,
+		subtrees:    newSubtrees(),
+		waiters:     newWaiters[Out](cfg.WaitRetention, cfg.ResultRetention, cfg.Clock),
+		batches:     newBatches[Out](),
+		goroutines:  newGoroutineBudget(cfg.MaxGoroutines, cfg.Workers),
+		cache:       newResultCache[Out](cfg.Clock),
+		deadLetters: &deadLetterStore[In]{retain: cfg.DeadLetterRetention},
+		queue:       newTaskQueue[In](capacity, cfg.DepthStep, cfg.Workers),
+		retries:     newRetryQueue[In](cfg.Clock),
+		retryBudget: newRetryBudget(cfg.RetryBudget, cfg.Clock),
+		results:     make(chan TypedResult[Out], cfg.QueueSize),
+		wound:       make(chan struct{}),
+		stop:        func() {},
+	}
+	p.tunables.set(cfg.DefaultTimeout, cfg.HardTimeoutGrace, cfg.SlowTaskThreshold)
+	if cfg.Dispatch == DispatchEarliestDeadline {
+		p.queue.orderByDeadline(p.deadlineOf)
+	}
+	return p
+}
+
+// withDefaults returns cfg with every unset field given its default.
+func (cfg TypedConfig[In]) withDefaults() TypedConfig[In] {
+	if cfg.Workers <= 0 {
+		cfg.Workers = 1
+	}
+	if cfg.IsRetryable == nil {
+		cfg.IsRetryable = DefaultIsRetryable
+	}
+	if cfg.Backoff == nil {
+		base := cfg.RetryBackoff
+		cfg.Backoff = BackoffFunc(func(attempt int) time.Duration { return exponential(base, attempt) })
+	}
+	if cfg.LatencyEMAAlpha <= 0 {
+		cfg.LatencyEMAAlpha = 0.2
+	}
+	cfg.LatencyEMAAlpha = min(cfg.LatencyEMAAlpha, 1)
+	if cfg.EventBuffer <= 0 {
+		cfg.EventBuffer = 256
+	}
+	if cfg.MaxGoroutines > 0 {
+		cfg.MaxGoroutines = max(cfg.MaxGoroutines, cfg.Workers+1)
+	}
+	if cfg.AttemptHistory <= 0 {
+		cfg.AttemptHistory = 10
+	}
+	if cfg.SubmitBlockWarning == 0 {
+		cfg.SubmitBlockWarning = 5 * time.Second
+	}
+	if cfg.ResultBlockWarning == 0 {
+		cfg.ResultBlockWarning = 5 * time.Second
+	}
+	if cfg.DeadLetterRetention <= 0 {
+		cfg.DeadLetterRetention = 1024
+	}
+	if cfg.Codec == nil {
+		cfg.Codec = JSONCodec[In]{}
+	}
+	if cfg.WaitRetention <= 0 {
+		cfg.WaitRetention = 1024
+	}
+	if cfg.Clock == nil {
+		cfg.Clock = clock.Real()
+	}
+	if cfg.IDGenerator == nil {
+		cfg.IDGenerator = ids.Random{}
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = slog.Default()
+	}
+	return cfg
+}
+
+// Start launches the workers, which run until ctx is done or Close is
+// called. Calls after the first do nothing.
+//
+// Cancelling ctx begins a graceful shutdown: workers stop picking up tasks,
+// and tasks already running get Config.ShutdownGrace to finish before their
+// contexts are cancelled.
+func (p *TypedPool[In, Out]) Start(ctx context.Context) {
+	p.startOnce.Do(func() { p.start(ctx) })
+}
+
+func (p *TypedPool[In, Out]) start(ctx context.Context) {
+	p.ctx = ctx
+	ctx, p.cancel = context.WithCancel(ctx)
+	// Tasks run under runCtx, which keeps ctx's values but is only
+	// cancelled once the grace period after ctx is done has elapsed.
+	runCtx, cancelRun := context.WithCancel(context.WithoutCancel(ctx))
+
+	// Claim the workers under mu, so a concurrent Reconfigure either sees
+	// them claimed or leaves the count for this to pick up.
+	p.mu.Lock()
+	p.workerCtx, p.runCtx = ctx, runCtx
+	n := len(p.queue.claim())
+	p.mu.Unlock()
+	states, warmupErr := p.warmAll(n)
+	if warmupErr != nil {
+		p.cfg.Logger.Error("worker warmup failed, not starting the pool", "err", warmupErr)
+		p.mu.Lock()
+		p.warmupErr = warmupErr
+		p.mu.Unlock()
+	}
+	p.started.Store(true)
+	stopGrace := context.AfterFunc(ctx, func() {
+		time.AfterFunc(p.cfg.ShutdownGrace, cancelRun)
+	})
+
+	stopMonitor := p.startMonitor()
+	stopAgeWatch := p.startAgeWatch()
+	stopCacheSweep := p.startCacheSweep()
+	stopResultSweep := p.startSweep(p.cfg.ResultRetention, p.waiters.sweep)
+	stopRateWindow := p.startRateWindow()
+	stopRetries := make(chan struct{})
+	retriesDone := make(chan struct{})
+	go func() {
+		defer close(retriesDone)
+		p.retries.run(ctx, p.queue, stopRetries)
+	}()
+	stopLaunch := func() {}
+	if warmupErr == nil {
+		stopLaunch = p.launchWorkers(ctx, runCtx, states)
+	}
+	p.stop = func() {
+		stopLaunch()
+		stopMonitor()
+		stopAgeWatch()
+		stopCacheSweep()
+		stopResultSweep()
+		stopRateWindow()
+		close(stopRetries)
+		<-retriesDone
+		stopGrace()
+		cancelRun()
+	}
+
+	if warmupErr != nil {
+		p.workers.Add(1)
+		go p.reap(ctx, runCtx, 0, warmupErr)
+	}
+}
+
+// Submit queues t. If the queue is full it hands t to Config.OverflowPool,
+// if set, or else applies Config.Overflow. Every accepted task, including
+// one later dropped by the overflow policy, produces exactly one Result.
+// Context values whitelisted with WithPropagatedValues are copied from ctx
+// to the task.
+func (p *TypedPool[In, Out]) Submit(ctx context.Context, t TypedTask[In]) error {
+	_, err := p.submit(ctx, t, submitOpts{})
+	return err
+}
+
+// SubmitWithTimeout queues t like Submit with a background context, but
+// gives up with ErrQueueFull if the queue has no room within d, so a
+// producer gets a bounded wait without building a context for it. On
+// timeout nothing of t is left in the pool. d bounds only the wait for a
+// slot, not the task's run; the overflow policies other than
+// OverflowBlock never wait, so it changes nothing for them.
+func (p *TypedPool[In, Out]) SubmitWithTimeout(t TypedTask[In], d time.Duration) error {
+	_, err := p.submit(context.Background(), t, submitOpts{wait: d})
+	return err
+}
+
+// SubmitSync hands t to a worker that is free to start it straight away,
+// blocking until one is, so producers are held to the rate the workers
+// get through tasks rather than to the room left in the queue. A task
+// with an AffinityKey waits for its own worker. Config.Overflow and
+// Config.OverflowPool never come into it, since t doesn't queue. It
+// returns ctx.Err() if ctx is done first, leaving nothing of t in the
+// pool, and ErrNoWorkers if the pool hasn't been started. The wait doesn't
+// hold up Close, Drain or Persist, though t still counts as submitted: a
+// task that gets its worker after Persist ran runs here.
+func (p *TypedPool[In, Out]) SubmitSync(ctx context.Context, t TypedTask[In]) error {
+	_, err := p.submit(ctx, t, submitOpts{handOff: true})
+	return err
+}
+
+// SubmitAndForget queues t like Submit, for high-volume work whose
+// individual outcomes nobody looks at. The task runs, retries and
+// dead-letters as usual, and counts towards Stats and events, but its
+// result is never delivered on Results or kept for Wait, which reports
+// ErrUnknownJob for it: such a task can't be polled by ID. A failure is
+// logged instead.
+func (p *TypedPool[In, Out]) SubmitAndForget(ctx context.Context, t TypedTask[In]) error {
+	_, err := p.submit(ctx, t, submitOpts{untracked: true})
+	return err
+}
+
+// submitOpts are the knobs the Submit variants turn.
+type submitOpts struct {
+	untracked bool          // see SubmitAndForget
+	wait      time.Duration // see SubmitWithTimeout; zero waits as long as ctx
+	noSpill   bool          // see SubmitFuture
+	handOff   bool          // see SubmitSync
+}
+
+// submit is Submit, returning the completion the task's result will
+// resolve, or nil for an untracked task or one spilled to
+// Config.OverflowPool.
+func (p *TypedPool[In, Out]) submit(ctx context.Context, t TypedTask[In], opts submitOpts) (*completion[Out], error) {
+	if err := p.Validate(t); err != nil {
+		return nil, err
+	}
+	p.mu.RLock()
+	if err := p.admitLocked(opts); err != nil {
+		p.mu.RUnlock()
+		return nil, err
+	}
+	if p.cfg.OverflowPool != nil && !opts.noSpill && !opts.handOff && p.queue.full() {
+		p.mu.RUnlock()
+		if err := p.cfg.OverflowPool.Submit(ctx, t); err != nil {
+			return nil, err
+		}
+		p.spilled.Add(1)
+		return nil, nil
+	}
+	blocking := p.cfg.Overflow == OverflowBlock && !opts.handOff && p.queue.full()
+	if blocking && !p.started.Load() {
+		p.mu.RUnlock()
+		return nil, ErrNoWorkers
+	}
+
+	t.EnqueuedAt = p.cfg.Clock.Now()
+	p.tracker.queue(t.ID, t.Group)
+	var c *completion[Out]
+	if !opts.untracked {
+		c = p.waiters.register(t.ID)
+	}
+	p.pending.Add(1)
+	// Close waits for the task from here on, so mu can go before the push
+	// blocks: held through it, a waiting Close would stop every other
+	// reader of mu, a running task's child submits included, and the
+	// queue would never get the room the push waits for. A push that
+	// races Close or Drain still lands, and Close waits it out; one that
+	// races Persist runs here rather than being handed off.
+	p.mu.RUnlock()
+	j := job[In]{task: t, values: captureValues(ctx), submitter: cancelSource(ctx), untracked: opts.untracked}
+	j.deadline, _ = ctx.Deadline()
+	// The wait for a slot gets its own deadline, so it doesn't also cap
+	// the task's timeout the way ctx's does.
+	pushCtx := ctx
+	if opts.wait > 0 {
+		var cancel context.CancelFunc
+		pushCtx, cancel = context.WithTimeout(ctx, opts.wait)
+		defer cancel()
+	}
+	stopWatch := func() {}
+	if blocking {
+		stopWatch = p.watchBlockedSubmit(t.ID)
+	}
+	queued := func() { p.publish(EventEnqueued, j, nil) }
+	var victim *job[In]
+	var err error
+	if opts.handOff {
+		err = p.queue.handOff(pushCtx, j, queued)
+	} else {
+		victim, err = p.queue.push(pushCtx, j, p.cfg.Overflow, queued)
+	}
+	stopWatch()
+	if err != nil {
+		p.tracker.finish(t.ID)
+		if !opts.untracked {
+			p.waiters.forget(t.ID)
+		}
+		p.pending.Done()
+		if ctx.Err() == nil && errors.Is(err, context.DeadlineExceeded) {
+			err = ErrQueueFull
+		}
+		return nil, err
+	}
+	if victim != nil {
+		res := p.result(*victim)
+		res.Err = ErrDropped
+		p.complete(*victim, res)
+	}
+	return c, nil
+}
+
+// admitLocked reports why the pool can't take a task submitted with opts
+// right now, if it can't. It requires p.mu.
+func (p *TypedPool[In, Out]) admitLocked(opts submitOpts) error {
+	switch {
+	case p.draining:
+		return ErrDraining
+	case p.closed || p.handedOff.Load():
+		return ErrClosed
+	case p.process == nil:
+		return ErrNoHandler
+	case p.warmupErr != nil:
+		return p.warmupErr
+	case opts.handOff && !p.started.Load():
+		return ErrNoWorkers
+	}
+	return nil
+}
+
+// Results yields each Result as soon as a worker finishes with it. The
+// sequence ends once Close has delivered the last result or the context
+// passed to Start is done; breaking out of the loop early stops collection.
+// Workers block while results go uncollected, so keep ranging until the
+// pool is closed unless it is being abandoned.
+func (p *TypedPool[In, Out]) Results() iter.Seq[TypedResult[Out]] {
+	return func(yield func(TypedResult[Out]) bool) {
+		var done <-chan struct{}
+		if p.ctx != nil {
+			done = p.ctx.Done()
+		}
+		for {
+			select {
+			case <-done:
+				return
+			case r, ok := <-p.results:
+				if !ok || !yield(r) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// RangeResults calls fn with each Result like ranging over Results, until
+// fn returns false, the pool is closed or abandoned, or ctx is done, in
+// which case it returns ctx.Err(). It runs on the caller's goroutine and
+// starts none of its own, so stopping early leaks nothing. Results not yet
+// collected stay in the results buffer, sized by Config.QueueSize, for the
+// next reader; workers only block on them once it is full.
+func (p *TypedPool[In, Out]) RangeResults(ctx context.Context, fn func(TypedResult[Out]) bool) error {
+	var abandoned <-chan struct{}
+	if p.ctx != nil {
+		abandoned = p.ctx.Done()
+	}
+	for {
+		// select picks at random among ready cases; check ctx first so a
+		// done ctx wins over a results buffer that still has values.
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-abandoned:
+			return nil
+		case r, ok := <-p.results:
+			if !ok || !fn(r) {
+				return nil
+			}
+		}
+	}
+}
+
+// QueueDepth returns a channel that receives the queue length whenever it
+// has changed by at least Config.DepthStep, as tasks are submitted and
+// picked up. Only the latest value is kept: a subscriber that falls behind
+// skips intermediate depths rather than slowing the queue down. Every call
+// returns the same channel.
+func (p *TypedPool[In, Out]) QueueDepth() <-chan int {
+	return p.queue.depth
+}
+
+// Close stops accepting tasks, waits for every submitted task to reach a
+// final result, then stops the workers, closes Results and the result
+// sink, and delivers the lifecycle events still buffered for subscribers.
+// It is safe to call from several goroutines at once, directly or through
+// Drain and Shutdown: the first call does the work and the others wait for
+// it to finish.
+func (p *TypedPool[In, Out]) Close() {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		<-p.wound
+		return
+	}
+	p.closed = true
+	p.mu.Unlock()
+	defer close(p.wound)
+
+	// Retries re-enter the queue, so it can only be closed once nothing
+	// is left waiting on a backoff. complete delivers a task's result
+	// before marking it done, so once the wait is over nothing is left to
+	// send on Results either, and it is safe to close after the workers.
+	p.pending.Wait()
+	p.stop()
+	p.queue.close()
+	p.workers.Wait()
+	safeClose(&p.resultsOnce, p.results)
+	p.closeSink()
+	p.events.close()
+	// Nothing is left to abandon, so only release the workers' ctx. The
+	// subscribers' ctx stays live: a subscriber that hands events off, as
+	// webhook.Notifier does, may still be delivering them.
+	if p.cancel != nil {
+		p.cancel()
+	}
+}
+
+// Drain stops accepting tasks and lets the workers finish everything
+// already submitted, queued or backing off, before closing the pool like
+// Close. Submit returns ErrDraining from then on.
+//
+// ctx is a hard deadline: if it is done first, Drain gives up on the
+// backlog the way Shutdown does and returns ctx.Err() without waiting for
+// the pool to wind down. Results keeps delivering what finishes either way.
+func (p *TypedPool[In, Out]) Drain(ctx context.Context) error {
+	p.mu.Lock()
+	p.draining = true
+	p.mu.Unlock()
+
+	closed := make(chan struct{})
+	go func() {
+		p.Close()
+		close(closed)
+	}()
+	select {
+	case <-closed:
+		return nil
+	case <-ctx.Done():
+		p.abandon()
+		return ctx.Err()
+	}
+}
+
+// Flush blocks until every task queued, backing off or running when it is
+// called has had its final result delivered, which needs Results to be
+// ranged over, and leaves the pool open. Tasks submitted after Flush is
+// called, children included, don't extend the wait, unless they reuse the
+// ID of a task Flush is waiting for. Flush returns ctx.Err() if ctx is
+// done first. On a pool that hasn't been started, queued tasks can only
+// finish once it is.
+func (p *TypedPool[In, Out]) Flush(ctx context.Context) error {
+	ids := p.tracker.ids()
+	for {
+		var changed <-chan struct{}
+		ids, changed = p.tracker.stillTracked(ids)
+		if len(ids) == 0 {
+			return nil
+		}
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Shutdown abandons queued work: tasks still waiting for a worker or a
+// retry finish with context.Canceled without running, and tasks already
+// running get Config.ShutdownGrace before their contexts are cancelled.
+// It then closes the pool like Close and returns a ShutdownReport of how
+// each task that was unfinished came to an end. Use Drain to finish the
+// backlog instead.
+//
+// ctx bounds the wait: if it is done before the pool has wound down,
+// Shutdown returns at once, reporting the tasks still without a result by
+// the state they were in at that moment.
+func (p *TypedPool[In, Out]) Shutdown(ctx context.Context) ShutdownReport {
+	log := &shutdownLog{}
+	p.shutdownLog.Store(log)
+	p.abandon()
+
+	closed := make(chan struct{})
+	go func() {
+		p.Close()
+		close(closed)
+	}()
+	select {
+	case <-closed:
+		return log.seal(nil, nil)
+	case <-ctx.Done():
+		return log.seal(p.tracker.unfinished())
+	}
+}
+
+// safeClose closes ch the first time it is called with once and does
+// nothing after, so the shutdown paths that reach a shared channel can't
+// close it twice. Each channel needs its own once.
+func safeClose[T any](once *sync.Once, ch chan T) {
+	once.Do(func() { close(ch) })
+}
+
+// abandon cancels the workers' context, which for them is the same as the
+// caller cancelling the one passed to Start, and the context subscribers
+// are called with. Results keeps going, since it watches the caller's
+// context.
+func (p *TypedPool[In, Out]) abandon() {
+	if p.cancel != nil {
+		p.cancel()
+	}
+	p.events.cancel()
+}
+
+// Process runs every task on the pool's workers and returns the results in
+// completion order, including those of any children the tasks submit. It
+// starts the pool with ctx if it isn't running yet, and leaves it running
+// for the next batch until Close; calls may overlap. Cancelling the ctx
+// that started the pool abandons its work like Shutdown, while a later
+// call's deadline caps the timeouts of its own tasks. The batch's results
+// are not delivered on Results.
+func (p *TypedPool[In, Out]) Process(ctx context.Context, tasks []TypedTask[In]) []TypedResult[Out] {
+	b := &batch[Out]{results: make([]TypedResult[Out], 0, len(tasks)), done: make(chan struct{})}
+	if len(tasks) == 0 {
+		close(b.done)
+	}
+	// Track everything before the first submit so CancelGroup can see
+	// tasks still waiting their turn.
+	for _, t := range tasks {
+		p.tracker.queue(t.ID, t.Group)
+		p.batches.add(t.ID, b)
+	}
+	p.Start(ctx)
+	for _, t := range tasks {
+		if err := p.Submit(ctx, t); err != nil {
+			p.tracker.finish(t.ID)
+			p.batches.settle(t.ID, &TypedResult[Out]{ID: t.ID, Err: err, Metadata: t.Metadata})
+		}
+	}
+	// Children can arrive until the last task is done, so only the batch
+	// knows when its results are complete.
+	<-b.done
+	return b.results
+}
+
+// job is a Task plus the bookkeeping that follows it between attempts.
+type job[In any] struct {
+	task     TypedTask[In]
+	attempts int
+	started  time.Time
+	// waited is how long the job sat in the queue before a worker first
+	// took it; see Result.QueueLatency.
+	waited time.Duration
+	// lastErr and sameErrs track the run of identical failures used for
+	// poison detection.
+	lastErr  string
+	sameErrs int
+	// values are the context values Submit captured; see
+	// WithPropagatedValues.
+	values []ctxValue
+	// deadline is the deadline of the context Submit was called with, if
+	// any; no attempt runs past it.
+	deadline time.Time
+	// untracked is set by SubmitAndForget: the job's result goes nowhere.
+	untracked bool
+	// submitter is the context Submit was called with, if the task should
+	// be cancelled along with it; see WithPropagatedCancel.
+	submitter context.Context
+	// exhausted is set once the job has failed with a retryable error and
+	// no retries left; see ErrMaxRetriesExceeded.
+	exhausted bool
+	// seq is stamped by the queue as the job joins it.
+	seq uint64
+	// history holds the latest attempts, oldest first; see
+	// Config.AttemptHistory.
+	history []AttemptInfo
+}
+
+// record adds a to the job's history, dropping the oldest entries past
+// limit.
+func (j *job[In]) record(a AttemptInfo, limit int) {
+	if len(j.history) >= limit {
+		// Copy rather than reslice, so the backing array can't grow
+		// without bound across retries.
+		j.history = append(j.history[:0:0], j.history[len(j.history)-limit+1:]...)
+	}
+	j.history = append(j.history, a)
+}
+
+// recordFailure updates the identical-failure run with err.
+func (j *job[In]) recordFailure(err error) {
+	if msg := err.Error(); msg == j.lastErr {
+		j.sameErrs++
+	} else {
+		j.lastErr, j.sameErrs = msg, 1
+	}
+}
+
+// result starts j's Result. A job no worker ever took, such as an
+// overflow victim, has no start time and gets a zero Duration.
+func (p *TypedPool[In, Out]) result(j job[In]) TypedResult[Out] {
+	res := TypedResult[Out]{
+		ID:           j.task.ID,
+		Metadata:     j.task.Metadata,
+		Attempt:      j.attempts,
+		Attempts:     j.history,
+		StartedAt:    j.started,
+		QueueLatency: j.waited,
+	}
+	if !j.started.IsZero() {
+		res.Duration = time.Since(j.started)
+	}
+	return res
+}
+
+// take starts the clock on a job a worker has just taken off the queue for
+// the first time, counting how long it waited there towards
+// Stats.QueueLatency.
+func (p *TypedPool[In, Out]) take(j *job[In]) {
+	if j.attempts > 0 {
+		return
+	}
+	j.started = time.Now()
+	if !j.task.EnqueuedAt.IsZero() {
+		j.waited = max(p.cfg.Clock.Now().Sub(j.task.EnqueuedAt), 0)
+		p.queueLatency.record(j.waited)
+	}
+}
+
+// worker stops taking tasks once ctx is done; running tasks derive from
+// runCtx so they can outlive ctx by the shutdown grace period.
+func (p *TypedPool[In, Out]) worker(ctx, runCtx context.Context, id int, state WorkerState) {
+	defer p.workers.Done()
+	p.online.Add(1)
+	defer p.online.Add(-1)
+	p.goroutines.add()
+	defer p.goroutines.done()
+	// Tear down before a successor warms up in this worker's place. As a
+	// defer this also runs when a panic takes the worker down.
+	replace := false
+	defer func() {
+		p.teardown(id, state)
+		if replace {
+			p.replaceWorker(ctx, runCtx, id)
+		} else {
+			p.vacate(ctx, runCtx, id)
+		}
+	}()
+	for {
+		j, ok := p.queue.pop(id)
+		if !ok {
+			return
+		}
+		p.take(&j)
+		jobCtx, ok := p.tracker.start(runCtx, j.task.ID, j.task.Group)
+		if !ok {
+			// Cancelled while queued: report it without running.
+			res := p.result(j)
+			res.Err = context.Canceled
+			p.complete(j, res)
+			continue
+		}
+		if j.submitter != nil && j.submitter.Err() != nil {
+			// Its submitter gave up while it was queued.
+			res := p.result(j)
+			res.Err = context.Canceled
+			p.complete(j, res)
+			continue
+		}
+		if p.expired(j) {
+			p.expiredBeforeStart.Add(1)
+			res := p.result(j)
+			res.Err = ErrExpiredBeforeStart
+			p.complete(j, res)
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			// The pool is stopping; don't start new work.
+			p.tracker.requeue(j.task.ID)
+			res := p.result(j)
+			res.Err = err
+			p.complete(j, res)
+			continue
+		}
+		if until, ok := p.throttle.blockedUntil(j.task.ResourceKey); ok {
+			// Park it with the retries until the resource's cooldown ends.
+			p.tracker.requeue(j.task.ID)
+			p.publish(EventRetried, j, ErrThrottled)
+			p.retries.push(j, until)
+			continue
+		}
+
+		j.attempts++
+		p.publish(EventStarted, j, nil)
+		p.heartbeats.beat(id, j.task.ID)
+		taskCtx := injectValues(p.heartbeats.withBeat(jobCtx, id, j.task.ID), j.values)
+		taskCtx = p.withTask(p.withWorkerState(taskCtx, state), j.task, j.attempts, id)
+		cancelDeadline := context.CancelFunc(func() {})
+		if !j.deadline.IsZero() {
+			taskCtx, cancelDeadline = context.WithDeadline(taskCtx, j.deadline)
+		}
+		taskCtx = context.WithValue(taskCtx, submitterKey{}, TypedSubmitter[In](childSubmitter[In, Out]{pool: p, parent: j.task}))
+		cancelMerge := context.CancelFunc(func() {})
+		if j.submitter != nil {
+			// Done with whichever goes first: the task's own context, which
+			// the pool's shutdown cancels, or the submitter's.
+			taskCtx, cancelMerge = ctxutil.MergeContexts(taskCtx, j.submitter)
+		}
+		p.active.Add(1)
+		out := p.processOne(taskCtx, j.task)
+		p.active.Add(-1)
+		cancelMerge()
+		cancelDeadline()
+		p.heartbeats.beat(id, "")
+		j.record(AttemptInfo{Attempt: j.attempts, WorkerID: id, Err: out.Err, StartedAt: out.StartedAt, Duration: out.Duration}, p.cfg.AttemptHistory)
+		err := out.Err
+		restart := p.cfg.OnPanic == PanicRestart && errors.Is(err, ErrPanic)
+		if ctx.Err() != nil {
+			p.logShutdownOutcome(id, j.task, runCtx)
+		}
+		if err == nil {
+			p.throttle.record(j.task.ResourceKey, false)
+		}
+		submitterGone := j.submitter != nil && j.submitter.Err() != nil
+		if err != nil && jobCtx.Err() == nil && ctx.Err() == nil && !submitterGone {
+			j.recordFailure(err)
+			retryable := p.cfg.IsRetryable(err)
+			if retryable && p.throttle.record(j.task.ResourceKey, true) {
+				p.cfg.Logger.Warn("throttling resource after repeated failures", "resource", j.task.ResourceKey, "cooldown", p.throttle.currentCooldown(), "err", err)
+			}
+			poisoned := p.cfg.PoisonThreshold > 0 && j.sameErrs >= p.cfg.PoisonThreshold
+			if retryable && !poisoned && j.attempts <= p.cfg.MaxRetries {
+				p.tracker.requeue(j.task.ID)
+				p.publish(EventRetried, j, err)
+				p.retries.push(j, p.retryAt(j.attempts))
+				if restart {
+					replace = true
+					return
+				}
+				continue
+			}
+			reason := ReasonExhausted
+			switch {
+			case !retryable:
+				reason = ReasonFatal
+			case poisoned:
+				reason = ReasonPoison
+			default:
+				j.exhausted = true
+			}
+			p.deadLetter(j, err, reason)
+		}
+
+		if !out.Cached && out.Duration > 0 {
+			p.latency.observe(out.Duration)
+		}
+		res := p.result(j)
+		res.Value, res.Err, res.Truncated, res.Cached = out.Value, out.Err, out.Truncated, out.Cached
+		p.complete(j, res)
+		if restart {
+			replace = true
+			return
+		}
+	}
+}
+
+// logShutdownOutcome records how a task that was running when shutdown
+// began came to an end.
+func (p *TypedPool[In, Out]) logShutdownOutcome(workerID int, t TypedTask[In], runCtx context.Context) {
+	if runCtx.Err() != nil {
+		p.cfg.Logger.Warn("task hard-killed at shutdown deadline", "worker", workerID, "task", t.ID, metadataAttr(t.Metadata))
+		return
+	}
+	p.cfg.Logger.Info("task finished cleanly during shutdown", "worker", workerID, "task", t.ID, metadataAttr(t.Metadata))
+}
+
+// complete delivers the final result for j.
+func (p *TypedPool[In, Out]) complete(j job[In], res TypedResult[Out]) {
+	if res.Err != nil {
+		res.Err = taskError(j, res.Err)
+	}
+	running := p.tracker.finishSettling(j.task.ID)
+	defer p.tracker.settled(j.task.ID)
+	p.throughput.record(p.cfg.Clock.Now())
+	p.rates.record(res.Err != nil)
+	if log := p.shutdownLog.Load(); log != nil {
+		log.record(j.task.ID, running, res.Err)
+	}
+	p.subtrees.finish(j.task.ID)
+	e := TypedEvent[In, Out]{Kind: EventSucceeded, Task: j.task, Attempt: res.Attempt, Value: res.Value, Err: res.Err, Time: time.Now()}
+	if res.Err != nil {
+		e.Kind = EventFailed
+		p.failed.Add(1)
+	} else {
+		p.succeeded.Add(1)
+		if res.Attempt > 1 {
+			p.eventualSuccess.Add(1)
+		} else {
+			p.firstAttemptSuccess.Add(1)
+		}
+	}
+	p.events.publish(e)
+	if j.untracked {
+		if res.Err != nil {
+			p.cfg.Logger.Warn("untracked task failed", "task", j.task.ID, metadataAttr(j.task.Metadata), "attempt", res.Attempt, "err", res.Err)
+		}
+		p.pending.Done()
+		return
+	}
+	p.writeResult(res)
+	p.waiters.resolve(j.task.ID, res, nil)
+	if !p.batches.settle(j.task.ID, &res) {
+		p.deliver(res)
+	}
+	p.pending.Done()
+}
+
+// processOne runs a single attempt of t under ctx — waiting for its share
+// of Config.MaxWeight and Config.ResourceLimits, then timeouts, panic
+// recovery and the result size limit — and reports it as a Result with
+// that attempt's timing. Queueing, retries and bookkeeping are left to the
+// caller, so this is the piece to test when only the handling of one task
+// matters.
+func (p *TypedPool[In, Out]) processOne(ctx context.Context, t TypedTask[In]) TypedResult[Out] {
+	if value, ok := p.cached(t); ok {
+		return TypedResult[Out]{ID: t.ID, Value: value, Cached: true, Metadata: t.Metadata, StartedAt: time.Now()}
+	}
+	releaseWeight, err := p.acquireWeight(ctx, t.Weight)
+	if err != nil {
+		return TypedResult[Out]{ID: t.ID, Err: err, Metadata: t.Metadata}
+	}
+	defer releaseWeight()
+	release, err := p.resources.acquire(ctx, t.ResourceKey, t.ResourceWeight)
+	if err != nil {
+		return TypedResult[Out]{ID: t.ID, Err: err, Metadata: t.Metadata}
+	}
+	defer release()
+
+	start := time.Now()
+	end := p.handlers.begin(t.Type)
+	value, err := p.attempt(ctx, t)
+	elapsed := time.Since(start)
+	end(elapsed, err)
+	p.processTime.record(elapsed)
+	p.checkSlow(t, elapsed, err)
+	value, truncated, err := p.limitResult(value, err)
+	if err == nil {
+		p.remember(t, value)
+	}
+	return TypedResult[Out]{ID: t.ID, Value: value, Err: err, Truncated: truncated, Metadata: t.Metadata, StartedAt: start, Duration: elapsed}
+}
+
+// checkSlow logs and counts an attempt of t that ran past
+// Config.SlowTaskThreshold. Attempts that timed out are the timeout's
+// business, not a latency warning.
+func (p *TypedPool[In, Out]) checkSlow(t TypedTask[In], elapsed time.Duration, err error) {
+	threshold := p.slowTaskThreshold()
+	if threshold <= 0 || elapsed <= threshold {
+		return
+	}
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, ErrHardTimeout) {
+		return
+	}
+	p.slowTasks.Add(1)
+	p.cfg.Logger.Warn("slow task", "task", t.ID, metadataAttr(t.Metadata), "duration", elapsed, "threshold", threshold)
+}
+
+// attempt runs the handler once under the task's soft timeout, which
+// cancels its context. With Config.HardTimeoutGrace set, the handler runs on
+// its own goroutine and the worker stops waiting for it once the grace
+// period after the soft timeout has also passed; see HardTimeoutGrace.
+func (p *TypedPool[In, Out]) attempt(ctx context.Context, task TypedTask[In]) (Out, error) {
+	soft := p.timeoutFor(task)
+	if deadline, ok := ctxutil.EffectiveDeadline(ctx, soft); ok {
+		// The submitter's deadline can cut the task's own timeout short.
+		soft = max(time.Until(deadline), time.Nanosecond)
+	}
+	grace := p.hardTimeoutGrace()
+	hardStage := soft > 0 && grace > 0
+	if hardStage {
+		// Wait for room before the timeout starts, so time spent at
+		// Config.MaxGoroutines doesn't eat into the attempt.
+		if err := p.goroutines.acquire(ctx); err != nil {
+			var zero Out
+			return zero, err
+		}
+	}
+	if soft > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, soft)
+		defer cancel()
+	}
+	if !hardStage {
+		return p.call(ctx, task)
+	}
+
+	type outcome struct {
+		value Out
+		err   error
+	}
+	// Buffered so an abandoned handler can still deliver and exit.
+	done := make(chan outcome, 1)
+	var abandoned atomic.Bool
+	go func() {
+		defer p.goroutines.release()
+		value, err := p.call(ctx, task)
+		done <- outcome{value, err}
+		if abandoned.Load() {
+			p.abandoned.Add(-1)
+		}
+	}()
+
+	hard := time.NewTimer(soft + grace)
+	defer hard.Stop()
+	select {
+	case o := <-done:
+		return o.value, o.err
+	case <-hard.C:
+		// Count before publishing the flag so the goroutine's decrement
+		// can never run first.
+		p.abandoned.Add(1)
+		abandoned.Store(true)
+		p.cfg.Logger.Warn("task abandoned at hard timeout", "task", task.ID, metadataAttr(task.Metadata), "timeout", soft, "grace", grace)
+		var zero Out
+		return zero, ErrHardTimeout
+	}
+}
+
+// call invokes the handler. Unless Config.OnPanic is PanicCrash, a
+// panicking handler is recovered and reported as an ErrPanic failure, so
+// the worker survives and the task still gets a result; without that, the
+// pending count would never reach zero and Close would never close
+// Results.
+func (p *TypedPool[In, Out]) call(ctx context.Context, task TypedTask[In]) (value Out, err error) {
+	if p.cfg.OnPanic == PanicCrash {
+		return p.process(ctx, task)
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			p.cfg.Logger.Error("task panicked", "task", task.ID, metadataAttr(task.Metadata), "panic", r, "stack", string(debug.Stack()))
+			var zero Out
+			value, err = zero, fmt.Errorf("%w: %v", ErrPanic, r)
+		}
+	}()
+	return p.process(ctx, task)
+}
+
+// CancelGroup cancels every queued and in-flight task whose Group is group
+// and returns how many were cancelled. In-flight tasks have their context
+// cancelled and are not retried; queued tasks are dropped when a worker
+// reaches them and reported with context.Canceled without running.
+func (p *TypedPool[In, Out]) CancelGroup(group string) int {
+	return p.tracker.cancelGroup(group)
+}
+
+// CancelTree cancels task id and every task descended from it through a
+// Submitter, however deep, and returns how many were cancelled. Running
+// tasks have their context cancelled and are not retried. Queued ones are
+// taken out of the queue at once and reported with context.Canceled
+// without running; those backing off before a retry are reported when
+// their backoff ends. Children a cancelled task submits afterwards start
+// out cancelled too.
+func (p *TypedPool[In, Out]) CancelTree(id string) int {
+	cancelled := p.tracker.cancelTree(id)
+	if len(cancelled) > 0 {
+		p.dropCancelled(cancelled)
+	}
+	return len(cancelled)
+}
+
+// dropCancelled takes the tracker-cancelled tasks in cancelled out of the
+// queue and reports them with context.Canceled.
+func (p *TypedPool[In, Out]) dropCancelled(cancelled map[string]bool) {
+	for _, j := range p.queue.removeFunc(func(j job[In]) bool { return cancelled[j.task.ID] }) {
+		res := p.result(j)
+		res.Err = context.Canceled
+		p.complete(j, res)
+	}
+}
+
+func (p *TypedPool[In, Out]) deadLetter(j job[In], err error, reason string) {
+	p.publish(EventDeadLettered, j, err)
+	d := TypedDeadLetter[In]{Task: j.task, Err: err, Reason: reason, Attempts: j.attempts}
+	p.deadLetters.add(d)
+	if p.cfg.DeadLetter == nil {
+		return
+	}
+	p.cfg.DeadLetter <- d
+}
+
+// timeoutFor resolves the timeout for t; see Config.DefaultTimeout for the
+// precedence rules. Zero means unbounded.
+func (p *TypedPool[In, Out]) timeoutFor(t TypedTask[In]) time.Duration {
+	if t.Timeout > 0 {
+		return t.Timeout
+	}
+	return p.defaultTimeout()
+}
@@ -0,0 +1,664 @@
+package worker_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rajatx185/golang-scalable-background-job-system/internal/clock"
+	"github.com/rajatx185/golang-scalable-background-job-system/internal/worker"
+)
+
+// deadlineProbe reports how much time the handler's context had left, or
+// -1 when it had no deadline.
+func deadlineProbe(ctx context.Context, t worker.Task) (string, error) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return "-1", nil
+	}
+	return time.Until(deadline).String(), nil
+}
+
+func TestTimeoutPrecedence(t *testing.T) {
+	tests := []struct {
+		name        string
+		defaultTime time.Duration
+		taskTime    time.Duration
+		want        time.Duration // -1 means no deadline
+	}{
+		{"task overrides default", time.Hour, time.Minute, time.Minute},
+		{"default applies", time.Hour, 0, time.Hour},
+		{"unbounded", 0, 0, -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pool := worker.NewPool(worker.Config{DefaultTimeout: tt.defaultTime}, deadlineProbe)
+			results := pool.Process(context.Background(), []worker.Task{{ID: "t", Timeout: tt.taskTime}})
+			if len(results) != 1 {
+				t.Fatalf("got %d results, want 1", len(results))
+			}
+
+			if tt.want < 0 {
+				if results[0].Value != "-1" {
+					t.Fatalf("task had a deadline %s left, want none", results[0].Value)
+				}
+				return
+			}
+			left, err := time.ParseDuration(results[0].Value)
+			if err != nil {
+				t.Fatalf("task had no deadline, want %s", tt.want)
+			}
+			if left > tt.want || left < tt.want-time.Second {
+				t.Fatalf("task had %s left, want about %s", left, tt.want)
+			}
+		})
+	}
+}
+
+// retryAll overrides the default classifier, which only retries timeouts.
+func retryAll(error) bool { return true }
+
+type order struct {
+	Items []int
+}
+
+func TestTypedPool(t *testing.T) {
+	dead := make(chan worker.TypedDeadLetter[order], 1)
+	pool := worker.NewPool(worker.TypedConfig[order]{DeadLetter: dead}, func(ctx context.Context, t worker.TypedTask[order]) (int, error) {
+		if len(t.Data.Items) == 0 {
+			return 0, errors.New("empty order")
+		}
+		total := 0
+		for _, n := range t.Data.Items {
+			total += n
+		}
+		return total, nil
+	})
+
+	results := pool.Process(context.Background(), []worker.TypedTask[order]{
+		{ID: "a", Data: order{Items: []int{1, 2, 3}}},
+		{ID: "b"},
+	})
+	got := map[string]int{}
+	for _, r := range results {
+		got[r.ID] = r.Value
+	}
+	if got["a"] != 6 {
+		t.Fatalf("total for a = %d, want 6", got["a"])
+	}
+	d := <-dead
+	if d.Task.ID != "b" || d.Task.Data.Items != nil {
+		t.Fatalf("dead-lettered %+v, want task b", d.Task)
+	}
+}
+
+func TestRunSummary(t *testing.T) {
+	var mu sync.Mutex
+	calls := map[string]int{}
+	pool := worker.NewPool(worker.Config{Workers: 2, MaxRetries: 1, IsRetryable: retryAll}, func(ctx context.Context, t worker.Task) (string, error) {
+		mu.Lock()
+		calls[t.ID]++
+		n := calls[t.ID]
+		mu.Unlock()
+
+		switch {
+		case t.Data == "fail":
+			return "", errors.New("boom")
+		case t.Data == "flaky" && n == 1:
+			return "", errors.New("try again")
+		}
+		return "ok", nil
+	})
+
+	summary, results := pool.Run(context.Background(), []worker.Task{
+		{ID: "a"}, {ID: "b", Data: "flaky"}, {ID: "c", Data: "fail"},
+	})
+	if len(results) != 3 {
+		t.Fatalf("got %d results, want 3", len(results))
+	}
+	want := worker.Summary{Total: 3, Succeeded: 2, Failed: 1, Retried: 2}
+	got := worker.Summary{Total: summary.Total, Succeeded: summary.Succeeded, Failed: summary.Failed, Retried: summary.Retried}
+	if got != want {
+		t.Fatalf("summary counts = %+v, want %+v", got, want)
+	}
+	if summary.P95Duration < summary.AvgDuration || summary.WallTime <= 0 {
+		t.Fatalf("inconsistent timings: %+v", summary)
+	}
+}
+
+func TestSummaryLeavesDroppedTasksOutOfDurations(t *testing.T) {
+	start := time.Now()
+	summary := worker.Summarize([]worker.Result{
+		{ID: "a", StartedAt: start, Duration: 100 * time.Millisecond},
+		{ID: "b", StartedAt: start, Duration: 300 * time.Millisecond},
+		{ID: "c", Err: worker.ErrDropped, Duration: time.Since(time.Time{})},
+	}, time.Second)
+	if summary.Total != 3 || summary.Failed != 1 {
+		t.Fatalf("summary counts = %+v, want the dropped task counted as failed", summary)
+	}
+	if summary.AvgDuration != 200*time.Millisecond || summary.P95Duration != 300*time.Millisecond {
+		t.Fatalf("AvgDuration, P95Duration = %v, %v, want 200ms, 300ms over the tasks that ran", summary.AvgDuration, summary.P95Duration)
+	}
+}
+
+func TestCancelGroup(t *testing.T) {
+	started := make(chan struct{})
+	pool := worker.NewPool(worker.Config{Workers: 1, MaxRetries: 3}, func(ctx context.Context, t worker.Task) (string, error) {
+		if t.Data == "block" {
+			close(started)
+			<-ctx.Done()
+			return "", ctx.Err()
+		}
+		return "ok", nil
+	})
+
+	done := make(chan []worker.Result)
+	go func() {
+		done <- pool.Process(context.Background(), []worker.Task{
+			{ID: "a", Group: "acme", Data: "block"},
+			{ID: "b", Group: "acme"},
+			{ID: "c", Group: "other"},
+		})
+	}()
+
+	<-started
+	if n := pool.CancelGroup("acme"); n != 2 {
+		t.Fatalf("CancelGroup = %d, want 2", n)
+	}
+
+	byID := map[string]worker.Result{}
+	for _, r := range <-done {
+		byID[r.ID] = r
+	}
+	for _, id := range []string{"a", "b"} {
+		if !errors.Is(byID[id].Err, context.Canceled) {
+			t.Errorf("task %s: err = %v, want context.Canceled", id, byID[id].Err)
+		}
+	}
+	if byID["a"].Attempt != 1 {
+		t.Errorf("cancelled task retried: %d attempts", byID["a"].Attempt)
+	}
+	if byID["b"].Attempt != 0 {
+		t.Errorf("queued task ran %d times after cancel", byID["b"].Attempt)
+	}
+	if byID["c"].Err != nil {
+		t.Errorf("other group affected: %v", byID["c"].Err)
+	}
+}
+
+func TestRetryDoesNotHoldWorker(t *testing.T) {
+	var mu sync.Mutex
+	failed := false
+	pool := worker.NewPool(worker.Config{Workers: 1, MaxRetries: 1, RetryBackoff: 50 * time.Millisecond, IsRetryable: retryAll},
+		func(ctx context.Context, t worker.Task) (string, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			if t.ID == "flaky" && !failed {
+				failed = true
+				return "", errors.New("try again")
+			}
+			return "ok", nil
+		})
+
+	results := pool.Process(context.Background(), []worker.Task{{ID: "flaky"}, {ID: "a"}, {ID: "b"}, {ID: "c"}})
+	if len(results) != 4 {
+		t.Fatalf("got %d results, want 4", len(results))
+	}
+	// With one worker, fresh tasks can only finish first if the backoff was
+	// spent off the worker.
+	last := results[len(results)-1]
+	if last.ID != "flaky" || last.Err != nil || last.Attempt != 2 {
+		t.Fatalf("last result = %+v, want flaky succeeding on attempt 2", last)
+	}
+}
+
+func TestRetryBackoffUsesClock(t *testing.T) {
+	clk := clock.NewManual(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	var calls atomic.Int32
+	pool := worker.NewPool(worker.Config{MaxRetries: 1, RetryBackoff: time.Hour, IsRetryable: retryAll, Clock: clk},
+		func(ctx context.Context, t worker.Task) (string, error) {
+			if calls.Add(1) == 1 {
+				return "", errors.New("try again")
+			}
+			return "ok", nil
+		})
+
+	done := make(chan []worker.Result)
+	go func() { done <- pool.Process(context.Background(), []worker.Task{{ID: "a"}}) }()
+	// The retry loop waits on the clock once the failed attempt is queued,
+	// alongside the rate window's ticker.
+	clk.BlockUntil(2)
+	clk.Advance(time.Hour)
+
+	select {
+	case results := <-done:
+		if r := results[0]; r.Err != nil || r.Attempt != 2 {
+			t.Fatalf("result = %+v, want success on attempt 2", r)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("retry was not released by advancing the clock")
+	}
+}
+
+func TestPoisonTaskSkipsRemainingRetries(t *testing.T) {
+	var mu sync.Mutex
+	calls := map[string]int{}
+	dead := make(chan worker.DeadLetter, 2)
+	pool := worker.NewPool(worker.Config{MaxRetries: 5, PoisonThreshold: 2, DeadLetter: dead, IsRetryable: retryAll},
+		func(ctx context.Context, t worker.Task) (string, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			calls[t.ID]++
+			if t.ID == "poison" {
+				return "", errors.New("invalid payload")
+			}
+			return "", fmt.Errorf("upstream error %d", calls[t.ID])
+		})
+
+	pool.Process(context.Background(), []worker.Task{{ID: "poison"}, {ID: "flaky"}})
+	close(dead)
+
+	got := map[string]worker.DeadLetter{}
+	for dl := range dead {
+		got[dl.Task.ID] = dl
+	}
+	if dl := got["poison"]; dl.Reason != worker.ReasonPoison || dl.Attempts != 2 {
+		t.Errorf("poison task dead-lettered as %q after %d attempts, want %q after 2", dl.Reason, dl.Attempts, worker.ReasonPoison)
+	}
+	if dl := got["flaky"]; dl.Reason != worker.ReasonExhausted || dl.Attempts != 6 {
+		t.Errorf("flaky task dead-lettered as %q after %d attempts, want %q after 6", dl.Reason, dl.Attempts, worker.ReasonExhausted)
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	errInvalid := errors.New("invalid payload")
+	var mu sync.Mutex
+	calls := map[string]int{}
+	dead := make(chan worker.DeadLetter, 2)
+	pool := worker.NewPool(worker.Config{MaxRetries: 2, DeadLetter: dead},
+		func(ctx context.Context, t worker.Task) (string, error) {
+			mu.Lock()
+			calls[t.ID]++
+			n := calls[t.ID]
+			mu.Unlock()
+			if t.ID == "invalid" {
+				return "", errInvalid
+			}
+			if n == 1 {
+				return "", fmt.Errorf("upstream: %w", context.DeadlineExceeded)
+			}
+			return "ok", nil
+		})
+
+	byID := map[string]worker.Result{}
+	for _, r := range pool.Process(context.Background(), []worker.Task{{ID: "invalid"}, {ID: "slow"}}) {
+		byID[r.ID] = r
+	}
+	close(dead)
+
+	if r := byID["slow"]; r.Err != nil || r.Attempt != 2 {
+		t.Errorf("timed-out task: err = %v after %d attempts, want success on attempt 2", r.Err, r.Attempt)
+	}
+	if r := byID["invalid"]; !errors.Is(r.Err, errInvalid) || r.Attempt != 1 {
+		t.Errorf("fatal task: err = %v after %d attempts, want %v after 1", r.Err, r.Attempt, errInvalid)
+	}
+	var letters []worker.DeadLetter
+	for dl := range dead {
+		letters = append(letters, dl)
+	}
+	if len(letters) != 1 || letters[0].Task.ID != "invalid" || letters[0].Reason != worker.ReasonFatal {
+		t.Errorf("dead letters = %+v, want only invalid with %q", letters, worker.ReasonFatal)
+	}
+}
+
+func TestResultsStreamsUntilClosed(t *testing.T) {
+	pool := worker.NewPool(worker.Config{Workers: 2}, func(ctx context.Context, t worker.Task) (string, error) {
+		return t.ID, nil
+	})
+	ctx := context.Background()
+	pool.Start(ctx)
+	go func() {
+		for i := 0; i < 10; i++ {
+			pool.Submit(ctx, worker.Task{ID: fmt.Sprint(i)})
+		}
+		pool.Close()
+	}()
+
+	seen := 0
+	for r := range pool.Results() {
+		if r.Value != r.ID {
+			t.Errorf("result %s has value %q", r.ID, r.Value)
+		}
+		seen++
+	}
+	if seen != 10 {
+		t.Fatalf("iterated %d results, want 10", seen)
+	}
+}
+
+func TestResultsStopsOnCancel(t *testing.T) {
+	pool := worker.NewPool(worker.Config{QueueSize: 4}, func(ctx context.Context, t worker.Task) (string, error) {
+		return "", nil
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	pool.Start(ctx)
+	pool.Submit(ctx, worker.Task{ID: "a"})
+	pool.Submit(ctx, worker.Task{ID: "b"})
+
+	seen := 0
+	for range pool.Results() {
+		seen++
+		if seen == 1 {
+			cancel()
+		}
+	}
+	// Reaching here at all (without Close) is the point; the sequence must
+	// not wait for results that nobody will submit.
+	if seen == 0 {
+		t.Fatal("no results yielded")
+	}
+}
+
+func TestPanickingWorkerDoesNotHangCollector(t *testing.T) {
+	pool := worker.NewPool(worker.Config{Workers: 2, Logger: slog.New(slog.NewTextHandler(io.Discard, nil))},
+		func(ctx context.Context, t worker.Task) (string, error) {
+			if t.ID == "boom" {
+				panic("nil map write")
+			}
+			return "ok", nil
+		})
+
+	done := make(chan []worker.Result)
+	go func() {
+		done <- pool.Process(context.Background(), []worker.Task{{ID: "a"}, {ID: "boom"}, {ID: "b"}, {ID: "c"}})
+	}()
+
+	var results []worker.Result
+	select {
+	case results = <-done:
+	case <-time.After(time.Second):
+		t.Fatal("collector hung after a worker panicked")
+	}
+	if len(results) != 4 {
+		t.Fatalf("got %d results, want 4", len(results))
+	}
+	for _, r := range results {
+		if r.ID == "boom" && !errors.Is(r.Err, worker.ErrPanic) {
+			t.Errorf("panicking task err = %v, want ErrPanic", r.Err)
+		}
+		if r.ID != "boom" && r.Err != nil {
+			t.Errorf("task %s err = %v", r.ID, r.Err)
+		}
+	}
+}
+
+func TestStatsCounts(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{})
+	pool := worker.NewPool(worker.Config{Workers: 1, QueueSize: 4, RetryBackoff: time.Hour, IsRetryable: retryAll, MaxRetries: 1}, func(ctx context.Context, task worker.Task) (string, error) {
+		if task.ID == "block" {
+			close(started)
+			<-release
+		}
+		if task.ID == "flaky" {
+			return "", errors.New("later")
+		}
+		return "", nil
+	})
+	pool.Start(context.Background())
+	got := make(chan worker.Result, 4)
+	go func() {
+		for r := range pool.Results() {
+			got <- r
+		}
+	}()
+
+	for _, id := range []string{"block", "flaky", "ok"} {
+		if err := pool.Submit(context.Background(), worker.Task{ID: id}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	<-started
+	time.Sleep(5 * time.Millisecond)
+	s := pool.Stats()
+	if s.ActiveWorkers != 1 || s.Queued != 2 || s.OldestQueued < 5*time.Millisecond {
+		t.Fatalf("while blocked: active %d, queued %d, oldest %s; want 1, 2, >=5ms", s.ActiveWorkers, s.Queued, s.OldestQueued)
+	}
+
+	close(release)
+	<-got
+	<-got
+	s = pool.Stats()
+	if s.ActiveWorkers != 0 || s.Queued != 0 || s.OldestQueued != 0 || s.Retrying != 1 || s.Succeeded != 2 || s.Failed != 0 {
+		t.Fatalf("after run: %+v; want flaky backing off and 2 succeeded", s)
+	}
+	pool.Shutdown(context.Background())
+}
+
+func TestMetadataFlowsThrough(t *testing.T) {
+	var logs bytes.Buffer
+	pool := worker.NewPool(worker.Config{Logger: slog.New(slog.NewTextHandler(&logs, nil))},
+		func(ctx context.Context, t worker.Task) (string, error) { panic("boom") })
+	defer pool.Close()
+	var mu sync.Mutex
+	var seen []map[string]string
+	pool.Subscribe(func(_ context.Context, e worker.Event) {
+		mu.Lock()
+		defer mu.Unlock()
+		seen = append(seen, e.Task.Metadata)
+	})
+
+	tags := map[string]string{"tenant": "acme", "source": "api"}
+	results := pool.Process(context.Background(), []worker.Task{{ID: "a", Metadata: tags}})
+	if got := results[0].Metadata; !maps.Equal(got, tags) {
+		t.Fatalf("Result.Metadata = %v, want %v", got, tags)
+	}
+	if !strings.Contains(logs.String(), "metadata.source=api metadata.tenant=acme") {
+		t.Fatalf("panic log line lacks the task's tags:\n%s", logs.String())
+	}
+	pool.Close()
+	for _, m := range seen {
+		if !maps.Equal(m, tags) {
+			t.Fatalf("event carried metadata %v, want %v", m, tags)
+		}
+	}
+}
+
+func TestSubmitAndForget(t *testing.T) {
+	var logs bytes.Buffer
+	pool := worker.NewPool(worker.Config{Logger: slog.New(slog.NewTextHandler(&logs, nil))},
+		func(ctx context.Context, t worker.Task) (string, error) {
+			if t.ID == "bad" {
+				return "", errors.New("invalid payload")
+			}
+			return "done", nil
+		})
+	pool.Start(context.Background())
+	for _, id := range []string{"a", "b", "bad"} {
+		if err := pool.SubmitAndForget(context.Background(), worker.Task{ID: id}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := pool.Submit(context.Background(), worker.Task{ID: "tracked"}); err != nil {
+		t.Fatal(err)
+	}
+	go pool.Close()
+	var got []string
+	for r := range pool.Results() {
+		got = append(got, r.ID)
+	}
+
+	if len(got) != 1 || got[0] != "tracked" {
+		t.Fatalf("Results delivered %v, want only the tracked task", got)
+	}
+	if _, err := pool.Wait(context.Background(), "a"); !errors.Is(err, worker.ErrUnknownJob) {
+		t.Fatalf("Wait on a forgotten task: err = %v, want ErrUnknownJob", err)
+	}
+	if s := pool.Stats(); s.Succeeded != 3 || s.Failed != 1 {
+		t.Fatalf("Succeeded, Failed = %d, %d; want 3, 1", s.Succeeded, s.Failed)
+	}
+	if !strings.Contains(logs.String(), "untracked task failed") {
+		t.Fatalf("the forgotten failure was not logged:\n%s", logs.String())
+	}
+}
+
+func TestSuccessByAttempt(t *testing.T) {
+	var mu sync.Mutex
+	failures := map[string]int{"flaky": 1, "flakier": 2, "broken": 99}
+	pool := worker.NewPool(worker.Config{MaxRetries: 2, IsRetryable: retryAll}, func(ctx context.Context, t worker.Task) (string, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if failures[t.ID] > 0 {
+			failures[t.ID]--
+			return "", errors.New("flaky")
+		}
+		return "ok", nil
+	})
+	defer pool.Close()
+
+	pool.Process(context.Background(), []worker.Task{{ID: "solid"}, {ID: "steady"}, {ID: "flaky"}, {ID: "flakier"}, {ID: "broken"}})
+	s := pool.Stats()
+	if s.FirstAttemptSuccess != 2 || s.EventualSuccess != 2 || s.Succeeded != 4 || s.Failed != 1 {
+		t.Fatalf("first-attempt %d, eventual %d, succeeded %d, failed %d; want 2, 2, 4, 1",
+			s.FirstAttemptSuccess, s.EventualSuccess, s.Succeeded, s.Failed)
+	}
+}
+
+func TestAttemptHistory(t *testing.T) {
+	var calls atomic.Int64
+	pool := worker.NewPool(worker.Config{Workers: 1, MaxRetries: 5, AttemptHistory: 3, IsRetryable: retryAll},
+		func(ctx context.Context, t worker.Task) (string, error) {
+			if n := calls.Add(1); n < 5 {
+				return "", fmt.Errorf("fail %d", n)
+			}
+			return "ok", nil
+		})
+	defer pool.Close()
+
+	r := pool.Process(context.Background(), []worker.Task{{ID: "flaky"}})[0]
+	if r.Err != nil || r.Attempt != 5 {
+		t.Fatalf("result = %+v, want success on attempt 5", r)
+	}
+	if len(r.Attempts) != 3 {
+		t.Fatalf("kept %d attempts, want the last 3: %+v", len(r.Attempts), r.Attempts)
+	}
+	for i, a := range r.Attempts {
+		wantErr := fmt.Sprintf("fail %d", i+3)
+		if i == 2 {
+			wantErr = ""
+		}
+		gotErr := ""
+		if a.Err != nil {
+			gotErr = a.Err.Error()
+		}
+		if a.Attempt != i+3 || a.WorkerID != 1 || gotErr != wantErr {
+			t.Fatalf("Attempts[%d] = %+v, want attempt %d on worker 1 with err %q", i, a, i+3, wantErr)
+		}
+	}
+}
+
+func TestSubmitSyncWaitsForAFreeWorker(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan string, 4)
+	pool := worker.NewPool(worker.Config{Workers: 2, QueueSize: 8}, func(ctx context.Context, t worker.Task) (string, error) {
+		started <- t.ID
+		<-release
+		return "ok", nil
+	})
+	if err := pool.SubmitSync(context.Background(), worker.Task{ID: "early"}); !errors.Is(err, worker.ErrNoWorkers) {
+		t.Fatalf("SubmitSync before Start = %v, want ErrNoWorkers", err)
+	}
+	pool.Start(context.Background())
+	go func() {
+		for range pool.Results() {
+		}
+	}()
+
+	for _, id := range []string{"a", "b"} {
+		if err := pool.SubmitSync(context.Background(), worker.Task{ID: id}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	<-started
+	<-started
+	// Both workers are busy, so a third task is refused rather than
+	// queued, though the queue has room.
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := pool.SubmitSync(ctx, worker.Task{ID: "c"}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("SubmitSync with every worker busy = %v, want the deadline", err)
+	}
+	if n := pool.Stats().Queued; n != 0 {
+		t.Fatalf("Queued = %d after the refused SubmitSync, want 0", n)
+	}
+
+	handed := make(chan error, 1)
+	go func() { handed <- pool.SubmitSync(context.Background(), worker.Task{ID: "d"}) }()
+	select {
+	case err := <-handed:
+		t.Fatalf("SubmitSync returned %v before a worker was free", err)
+	case <-time.After(10 * time.Millisecond):
+	}
+	release <- struct{}{}
+	if err := <-handed; err != nil {
+		t.Fatal(err)
+	}
+	if id := <-started; id != "d" {
+		t.Fatalf("freed worker started %q, want d", id)
+	}
+	close(release)
+	pool.Close()
+}
+
+func TestSubmitSyncWaitDoesNotHoldUpThePool(t *testing.T) {
+	release := make(chan struct{})
+	running := make(chan struct{}, 1)
+	pool := worker.NewPool(worker.Config{Workers: 1, QueueSize: 4}, func(ctx context.Context, t worker.Task) (string, error) {
+		running <- struct{}{}
+		<-release
+		return "ok", nil
+	})
+	pool.Start(context.Background())
+	go func() {
+		for range pool.Results() {
+		}
+	}()
+	if err := pool.Submit(context.Background(), worker.Task{ID: "busy"}); err != nil {
+		t.Fatal(err)
+	}
+	<-running
+	synced := make(chan error, 1)
+	go func() { synced <- pool.SubmitSync(context.Background(), worker.Task{ID: "sync"}) }()
+	time.Sleep(10 * time.Millisecond)
+
+	// With SubmitSync waiting for the only worker, Persist takes the pool's
+	// lock and a plain Submit gets through.
+	done := make(chan error, 1)
+	go func() { done <- pool.Persist(t.TempDir() + "/handoff.bin") }()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Persist stalled behind a waiting SubmitSync")
+	}
+	if err := pool.Submit(context.Background(), worker.Task{ID: "late"}); !errors.Is(err, worker.ErrClosed) {
+		t.Fatalf("Submit after Persist = %v, want ErrClosed", err)
+	}
+	close(release)
+	if err := <-synced; err != nil {
+		t.Fatalf("SubmitSync = %v, want it handed over once the worker was free", err)
+	}
+	pool.Close()
+}
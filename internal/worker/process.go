@@ -0,0 +1,75 @@
+package worker
+
+import "sync"
+
+// batches routes the results of the tasks a Process call submitted, and of
+// the children they spawn, back to that call instead of Results, so one
+// pool can run batch after batch, or several at once.
+type batches[Out any] struct {
+	mu   sync.Mutex
+	byID map[string]*batchEntry[Out]
+}
+
+type batchEntry[Out any] struct {
+	b *batch[Out]
+	n int // copies of the ID in flight; Process does not dedupe
+}
+
+type batch[Out any] struct {
+	results []TypedResult[Out]
+	left    int // tasks without a final result yet
+	done    chan struct{}
+}
+
+func newBatches[Out any]() *batches[Out] {
+	return &batches[Out]{byID: make(map[string]*batchEntry[Out])}
+}
+
+// add counts task id towards b.
+func (bs *batches[Out]) add(id string, b *batch[Out]) {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+	bs.addLocked(id, b)
+}
+
+func (bs *batches[Out]) addLocked(id string, b *batch[Out]) {
+	e, ok := bs.byID[id]
+	if !ok {
+		e = &batchEntry[Out]{b: b}
+		bs.byID[id] = e
+	}
+	e.n++
+	b.left++
+}
+
+// adopt counts child id towards the batch of its parent, if it has one.
+func (bs *batches[Out]) adopt(parentID, id string) {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+	if e, ok := bs.byID[parentID]; ok {
+		bs.addLocked(id, e.b)
+	}
+}
+
+// settle hands task id's final result to its batch and reports whether it
+// had one. A nil res settles a task that will never have a result, such as
+// one handed off with Persist.
+func (bs *batches[Out]) settle(id string, res *TypedResult[Out]) bool {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+	e, ok := bs.byID[id]
+	if !ok {
+		return false
+	}
+	if e.n--; e.n == 0 {
+		delete(bs.byID, id)
+	}
+	b := e.b
+	if res != nil {
+		b.results = append(b.results, *res)
+	}
+	if b.left--; b.left == 0 {
+		close(b.done)
+	}
+	return true
+}
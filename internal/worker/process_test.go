@@ -0,0 +1,119 @@
+package worker_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/rajatx185/golang-scalable-background-job-system/internal/worker"
+)
+
+func TestProcessOne(t *testing.T) {
+	handler := func(ctx context.Context, t worker.Task) (string, error) {
+		switch t.Data {
+		case "panic":
+			panic("kaboom")
+		case "wait":
+			<-ctx.Done()
+			return "", ctx.Err()
+		}
+		return "done", nil
+	}
+	pool := worker.NewPool(worker.Config{Logger: slog.New(slog.NewTextHandler(io.Discard, nil))}, handler)
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		task    worker.Task
+		value   string
+		wantErr error
+	}{
+		{"success", context.Background(), worker.Task{ID: "a"}, "done", nil},
+		{"timeout", context.Background(), worker.Task{ID: "b", Data: "wait", Timeout: 10 * time.Millisecond}, "", context.DeadlineExceeded},
+		{"panic", context.Background(), worker.Task{ID: "c", Data: "panic"}, "", worker.ErrPanic},
+		{"cancel", cancelled, worker.Task{ID: "d", Data: "wait"}, "", context.Canceled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := worker.ProcessOne(pool, tt.ctx, tt.task)
+			if r.ID != tt.task.ID || r.Value != tt.value || !errors.Is(r.Err, tt.wantErr) {
+				t.Fatalf("result = %+v, want value %q and err %v", r, tt.value, tt.wantErr)
+			}
+			if r.StartedAt.IsZero() {
+				t.Fatal("StartedAt not set")
+			}
+		})
+	}
+}
+
+func TestProcessReusesPool(t *testing.T) {
+	pool := worker.NewPool(worker.Config{Workers: 2}, func(ctx context.Context, t worker.Task) (string, error) {
+		if t.Data == "spawn" {
+			sub, _ := worker.SubmitterFromContext(ctx)
+			if err := sub.Submit(ctx, worker.Task{ID: t.ID + "-child"}); err != nil {
+				return "", err
+			}
+		}
+		return "done " + t.ID, nil
+	})
+	defer pool.Close()
+
+	for i, batch := range [][]worker.Task{
+		{{ID: "a"}, {ID: "b"}},
+		{{ID: "c", Data: "spawn"}},
+		{{ID: "d"}, {ID: "e"}, {ID: "f"}},
+	} {
+		want := map[string]bool{}
+		for _, task := range batch {
+			want[task.ID] = true
+			if task.Data == "spawn" {
+				want[task.ID+"-child"] = true
+			}
+		}
+		results := pool.Process(context.Background(), batch)
+		if len(results) != len(want) {
+			t.Fatalf("batch %d: %d results, want %d", i, len(results), len(want))
+		}
+		for _, r := range results {
+			if !want[r.ID] || r.Err != nil {
+				t.Fatalf("batch %d: unexpected result %+v", i, r)
+			}
+		}
+	}
+	if n := pool.Stats().Succeeded; n != 7 {
+		t.Fatalf("Succeeded = %d over three batches, want 7", n)
+	}
+}
+
+func TestProcessEmptyBatch(t *testing.T) {
+	pool := worker.NewPool(worker.Config{}, func(ctx context.Context, t worker.Task) (string, error) { return "", nil })
+	defer pool.Close()
+	done := make(chan []worker.Result)
+	go func() { done <- pool.Process(context.Background(), nil) }()
+	select {
+	case results := <-done:
+		if len(results) != 0 {
+			t.Fatalf("results = %+v, want none", results)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Process hung on an empty batch")
+	}
+}
+
+func TestNilHandler(t *testing.T) {
+	pool := worker.NewPool[string, string](worker.Config{}, nil)
+	defer pool.Close()
+	if err := pool.Submit(context.Background(), worker.Task{ID: "a"}); !errors.Is(err, worker.ErrNoHandler) {
+		t.Fatalf("Submit: err = %v, want ErrNoHandler", err)
+	}
+	results := pool.Process(context.Background(), []worker.Task{{ID: "b"}})
+	if len(results) != 1 || !errors.Is(results[0].Err, worker.ErrNoHandler) {
+		t.Fatalf("Process results = %+v, want one with ErrNoHandler", results)
+	}
+}
@@ -0,0 +1,73 @@
+package worker
+
+import (
+	"context"
+	"slices"
+)
+
+type (
+	propagateKey       struct{}
+	propagateCancelKey struct{}
+)
+
+// WithPropagatedValues marks the values stored under keys in ctx to be
+// carried over to tasks submitted with the returned context. Submit copies
+// them, and each attempt's context carries the copies, so a handler can read a
+// request's user or tenant even though the request context itself —
+// cancelled as soon as the response is written — is never used to run the
+// task. Calls accumulate: keys added by an outer caller stay whitelisted.
+//
+// Only the values are propagated, never the cancellation unless ctx also
+// carries WithPropagatedCancel, and they are not included in Export
+// snapshots.
+func WithPropagatedValues(ctx context.Context, keys ...any) context.Context {
+	whitelist, _ := ctx.Value(propagateKey{}).([]any)
+	return context.WithValue(ctx, propagateKey{}, append(slices.Clip(whitelist), keys...))
+}
+
+// ctxValue is a context value captured at submit time.
+type ctxValue struct {
+	key, value any
+}
+
+// captureValues copies the whitelisted values present in ctx.
+func captureValues(ctx context.Context) []ctxValue {
+	whitelist, _ := ctx.Value(propagateKey{}).([]any)
+	var values []ctxValue
+	for _, key := range whitelist {
+		if v := ctx.Value(key); v != nil {
+			values = append(values, ctxValue{key, v})
+		}
+	}
+	return values
+}
+
+// injectValues returns ctx carrying the captured values.
+func injectValues(ctx context.Context, values []ctxValue) context.Context {
+	for _, v := range values {
+		ctx = context.WithValue(ctx, v.key, v.value)
+	}
+	return ctx
+}
+
+// WithPropagatedCancel marks tasks submitted with the returned context, or
+// one derived from it, to be cancelled along with the context Submit was
+// called with, on top of the pool's own shutdown: a task queued for a
+// request that gives up is not worth finishing. A task whose submitter is
+// gone by the time a worker takes it fails with context.Canceled without
+// running, and one cancelled while running is not retried.
+//
+// The link lives in memory only: tasks handed off with Export or Persist
+// lose it.
+func WithPropagatedCancel(ctx context.Context) context.Context {
+	return context.WithValue(ctx, propagateCancelKey{}, true)
+}
+
+// cancelSource returns ctx if tasks submitted with it should be cancelled
+// along with it, or nil.
+func cancelSource(ctx context.Context) context.Context {
+	if on, _ := ctx.Value(propagateCancelKey{}).(bool); on {
+		return ctx
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package worker_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/rajatx185/golang-scalable-background-job-system/internal/ctxutil"
+	"github.com/rajatx185/golang-scalable-background-job-system/internal/worker"
+)
+
+type traceKey struct{}
+
+func TestPropagatedValuesOutliveSubmitContext(t *testing.T) {
+	type seen struct {
+		user  string
+		trace any
+	}
+	got := make(chan seen, 1)
+	pool := worker.NewPool(worker.Config{}, func(ctx context.Context, t worker.Task) (string, error) {
+		user, _ := ctxutil.UserIDFromContext(ctx)
+		got <- seen{user, ctx.Value(traceKey{})}
+		return "", nil
+	})
+
+	// A request context carrying a user and a value nobody whitelisted.
+	reqCtx, cancel := context.WithCancel(context.Background())
+	reqCtx = ctxutil.WithUserID(reqCtx, "u-42")
+	reqCtx = context.WithValue(reqCtx, traceKey{}, "t-1")
+	reqCtx = worker.WithPropagatedValues(reqCtx, ctxutil.Keys()...)
+
+	// Never started, so the task is still queued when the request ends.
+	if err := pool.Submit(reqCtx, worker.Task{ID: "a"}); err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+	pool.Start(context.Background())
+	go pool.Close()
+	for range pool.Results() {
+	}
+
+	if s := <-got; s.user != "u-42" || s.trace != nil {
+		t.Fatalf("handler saw user %q and trace %v, want u-42 and nothing", s.user, s.trace)
+	}
+}
+
+func TestPropagatedCancelStopsTasks(t *testing.T) {
+	started := make(chan struct{})
+	var ran atomic.Int64
+	pool := worker.NewPool(worker.Config{
+		Workers:     1,
+		QueueSize:   2,
+		MaxRetries:  3,
+		IsRetryable: func(error) bool { return true },
+	}, func(ctx context.Context, t worker.Task) (string, error) {
+		ran.Add(1)
+		close(started)
+		<-ctx.Done()
+		return "", ctx.Err()
+	})
+	pool.Start(context.Background())
+
+	req, cancel := context.WithCancel(context.Background())
+	ctx := worker.WithPropagatedCancel(req)
+	for _, id := range []string{"running", "queued"} {
+		if err := pool.Submit(ctx, worker.Task{ID: id}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	<-started
+	cancel()
+	for _, id := range []string{"running", "queued"} {
+		r, err := pool.Wait(context.Background(), id)
+		if err != nil || !errors.Is(r.Err, context.Canceled) {
+			t.Fatalf("%s: %+v, %v, want context.Canceled", id, r, err)
+		}
+	}
+	pool.Close()
+	if n := ran.Load(); n != 1 {
+		t.Fatalf("handler ran %d times, want once: no retry, and nothing for the queued task", n)
+	}
+}
@@ -0,0 +1,520 @@
+package worker
+
+import (
+	"context"
+	"hash/fnv"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// OverflowPolicy decides what Submit does when the task queue is full.
+type OverflowPolicy int
+
+const (
+	// OverflowBlock makes Submit wait for space or for its context.
+	OverflowBlock OverflowPolicy = iota
+	// OverflowDropNewest discards the task being submitted.
+	OverflowDropNewest
+	// OverflowDropOldest discards the task that has waited longest to make
+	// room for the new one.
+	OverflowDropOldest
+	// OverflowError makes Submit return ErrQueueFull.
+	OverflowError
+)
+
+const noHead = math.MinInt64
+
+// seat is the state of one worker ID's goroutine.
+type seat uint8
+
+const (
+	seatEmpty    seat = iota // no goroutine
+	seatTaken                // a goroutine is running as the worker
+	seatRetiring             // retired by resize, on its way out
+	seatRecalled             // retiring, but resize wants the ID again
+)
+
+// taskQueue is a mutex-guarded FIFO ring buffer. Unlike a channel it can
+// evict from the head, which OverflowDropOldest needs.
+type taskQueue[In any] struct {
+	mu       sync.Mutex
+	cond     *sync.Cond // broadcast on every push, pop and close
+	buf      []job[In]
+	head, n  int
+	capacity int
+	closed   bool
+	dropped  atomic.Int64
+	// workers is how many workers the pool runs, for routing affinity
+	// keys; see resize. seats holds what each worker ID's goroutine is
+	// doing, indexed by ID - 1, so resize knows which IDs need a new one.
+	workers int
+	seats   []seat
+	// idle marks, by ID - 1, the workers waiting in pop with nothing to
+	// take, idlers counts them, and ready is signalled each time one
+	// starts waiting, for handOff.
+	idle   []bool
+	idlers int
+	ready  *sync.Cond
+	// seq stamps each job as it joins; edf, if set, orders the jobs by
+	// deadlineOf for DispatchEarliestDeadline.
+	seq        uint64
+	edf        *deadlineHeap
+	deadlineOf func(job[In]) time.Time
+
+	// Gauges kept current under mu so Stats can read them without it:
+	// the length, the EnqueuedAt of the head job (Unix nanoseconds, noHead
+	// when empty), and how many queued jobs each worker's affinity
+	// keys hold, indexed by worker ID - 1. resize replaces the last.
+	size      atomic.Int64
+	headSince atomic.Int64
+	pinned    atomic.Pointer[[]atomic.Int64]
+
+	// depth carries the latest queue length to a QueueDepth subscriber
+	// whenever it has moved by depthStep since the last report.
+	depth         chan int
+	depthStep     int
+	reportedDepth int
+}
+
+func newTaskQueue[In any](capacity, depthStep, workers int) *taskQueue[In] {
+	q := &taskQueue[In]{
+		workers:   workers,
+		seats:     make([]seat, workers),
+		idle:      make([]bool, workers),
+		buf:       make([]job[In], capacity),
+		capacity:  capacity,
+		depth:     make(chan int, 1),
+		depthStep: max(depthStep, 1),
+	}
+	q.cond = sync.NewCond(&q.mu)
+	q.ready = sync.NewCond(&q.mu)
+	q.headSince.Store(noHead)
+	pinned := make([]atomic.Int64, workers)
+	q.pinned.Store(&pinned)
+	return q
+}
+
+// push appends j, applying policy if the queue is full. victim is the job
+// the policy discarded, if any; the caller owns reporting it. queued runs
+// under q.mu once j is accepted, before any worker can take it, so an
+// event it publishes comes ahead of the ones the worker does.
+func (q *taskQueue[In]) push(ctx context.Context, j job[In], policy OverflowPolicy, queued func()) (victim *job[In], err error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.n >= q.capacity && policy == OverflowBlock {
+		stop := context.AfterFunc(ctx, func() {
+			q.mu.Lock()
+			defer q.mu.Unlock()
+			q.cond.Broadcast()
+		})
+		defer stop()
+	}
+
+	for q.n >= q.capacity && !q.closed {
+		switch policy {
+		case OverflowDropNewest:
+			q.dropped.Add(1)
+			queued()
+			return &j, nil
+		case OverflowDropOldest:
+			oldest := q.popFront()
+			q.pushBack(j)
+			q.dropped.Add(1)
+			q.reportDepth()
+			queued()
+			return &oldest, nil
+		case OverflowError:
+			return nil, ErrQueueFull
+		default:
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			q.cond.Wait()
+		}
+	}
+	if q.closed {
+		return nil, ErrClosed
+	}
+	q.pushBack(j)
+	q.reportDepth()
+	queued()
+	q.cond.Broadcast()
+	return nil, nil
+}
+
+// full reports whether push would have to wait or drop.
+func (q *taskQueue[In]) full() bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.n >= q.capacity
+}
+
+// pushRetry appends a job coming back from the retry queue, or one Import
+// restores. Both were already accepted once, so they bypass the capacity
+// check and are never dropped.
+func (q *taskQueue[In]) pushRetry(j job[In]) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.pushBack(j)
+	q.reportDepth()
+	q.cond.Broadcast()
+}
+
+// pushChild appends a child a running task submitted, or fails with
+// ErrClosed once the queue is closed. Children would deadlock a worker
+// waiting for its own slot, so they bypass the capacity check like
+// retries. queued runs as it does for push.
+func (q *taskQueue[In]) pushChild(j job[In], queued func()) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return ErrClosed
+	}
+	q.pushBack(j)
+	q.reportDepth()
+	queued()
+	q.cond.Broadcast()
+	return nil
+}
+
+// pop removes the oldest job, blocking until one is available. It reports
+// false once the queue is closed and empty, or once resize has retired
+// worker, which should then exit. Worker 0 ignores affinity.
+func (q *taskQueue[In]) pop(worker int) (job[In], bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for {
+		if worker > q.workers {
+			q.seats[worker-1] = seatRetiring
+			return job[In]{}, false
+		}
+		if i := q.nextFor(worker); i >= 0 {
+			j := q.removeAt(i)
+			q.reportDepth()
+			q.cond.Broadcast()
+			return j, true
+		}
+		if q.closed {
+			return job[In]{}, false
+		}
+		q.setIdle(worker, true)
+		q.cond.Wait()
+		q.setIdle(worker, false)
+	}
+}
+
+// setIdle marks worker as waiting for a job or not, waking handOff when
+// it starts to. It requires q.mu.
+func (q *taskQueue[In]) setIdle(worker int, idle bool) {
+	if worker == 0 || q.idle[worker-1] == idle {
+		return
+	}
+	q.idle[worker-1] = idle
+	if idle {
+		q.idlers++
+		q.ready.Broadcast()
+	} else {
+		q.idlers--
+	}
+}
+
+// handOff appends j once a worker is waiting to take it straight away,
+// bypassing the capacity check since j won't sit in the queue. Every job
+// already queued is counted as claiming one of the idle workers, so j
+// never waits behind them. A job with an AffinityKey needs its own worker
+// idle, with nothing queued that it could take first. handOff gives up
+// with ctx's error, or ErrClosed once the queue is closed. queued runs as
+// it does for push.
+func (q *taskQueue[In]) handOff(ctx context.Context, j job[In], queued func()) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	stop := context.AfterFunc(ctx, func() {
+		q.mu.Lock()
+		defer q.mu.Unlock()
+		q.ready.Broadcast()
+	})
+	defer stop()
+	for !q.closed && !q.readyFor(j) {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		q.ready.Wait()
+	}
+	if q.closed {
+		return ErrClosed
+	}
+	q.pushBack(j)
+	q.reportDepth()
+	queued()
+	q.cond.Broadcast()
+	return nil
+}
+
+// readyFor reports whether a worker would start j the moment it joined
+// the queue. It requires q.mu.
+func (q *taskQueue[In]) readyFor(j job[In]) bool {
+	key := j.task.AffinityKey
+	if key == "" {
+		return q.idlers > q.n
+	}
+	owner := q.owner(key)
+	if owner > len(q.idle) || !q.idle[owner-1] {
+		return false
+	}
+	pinned := *q.pinned.Load()
+	var held int64
+	for i := range pinned {
+		held += pinned[i].Load()
+	}
+	// Nothing queued for the owner, and nothing unpinned it could take.
+	return pinned[owner-1].Load() == 0 && int64(q.n) == held
+}
+
+// nextFor returns the position of the next job worker may run: one with
+// no AffinityKey, or one whose key hashes to worker. That is the oldest,
+// unless the queue is ordered by deadline. It returns -1 if there is none.
+func (q *taskQueue[In]) nextFor(worker int) int {
+	if q.edf != nil {
+		return q.nextByDeadline(worker)
+	}
+	for i := 0; i < q.n; i++ {
+		j := &q.buf[(q.head+i)%len(q.buf)]
+		if worker == 0 || j.task.AffinityKey == "" || q.owner(j.task.AffinityKey) == worker {
+			return i
+		}
+	}
+	return -1
+}
+
+// owner maps an affinity key to the ID (1-based) of the worker that runs it.
+func (q *taskQueue[In]) owner(key string) int {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return int(h.Sum32()%uint32(q.workers)) + 1
+}
+
+// pinnedDepths counts the queued jobs each worker's affinity keys hold it
+// to, indexed by worker ID - 1.
+func (q *taskQueue[In]) pinnedDepths() []int {
+	pinned := *q.pinned.Load()
+	depths := make([]int, len(pinned))
+	for i := range depths {
+		depths[i] = int(pinned[i].Load())
+	}
+	return depths
+}
+
+// pin adjusts the pinned count of j's worker, if j has an affinity key. It
+// requires q.mu.
+func (q *taskQueue[In]) pin(j job[In], delta int64) {
+	if j.task.AffinityKey != "" {
+		(*q.pinned.Load())[q.owner(j.task.AffinityKey)-1].Add(delta)
+	}
+}
+
+// resize routes affinity keys over n workers from now on. Workers above n
+// are retired: their next pop reports false. Keys move to new owners, so a
+// key's queued tasks may run on a different worker than its earlier ones.
+// IDs up to n left without a goroutine need one started; see claim. It
+// reports false if the queue already routed over n workers.
+func (q *taskQueue[In]) resize(n int) (changed bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if n == q.workers {
+		return false
+	}
+	q.workers = n
+	for len(q.seats) < n {
+		q.seats = append(q.seats, seatEmpty)
+		q.idle = append(q.idle, false)
+	}
+	for id := 1; id <= n; id++ {
+		if q.seats[id-1] == seatRetiring {
+			// It is still tearing down; it starts its own successor.
+			q.seats[id-1] = seatRecalled
+		}
+	}
+	pinned := make([]atomic.Int64, n)
+	for i := 0; i < q.n; i++ {
+		if key := q.buf[(q.head+i)%len(q.buf)].task.AffinityKey; key != "" {
+			pinned[q.owner(key)-1].Add(1)
+		}
+	}
+	q.pinned.Store(&pinned)
+	q.cond.Broadcast()
+	return true
+}
+
+// claim returns the IDs, among the workers resize last asked for, that
+// have no goroutine, and counts them as taken: the caller starts them.
+func (q *taskQueue[In]) claim() []int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var ids []int
+	for id := 1; id <= q.workers; id++ {
+		if q.seats[id-1] == seatEmpty {
+			q.seats[id-1] = seatTaken
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// vacate records that worker's goroutine has exited, and reports whether
+// resize asked for the ID back meanwhile, in which case the caller starts
+// a successor.
+func (q *taskQueue[In]) vacate(worker int) (recalled bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.seats[worker-1] == seatRecalled {
+		q.seats[worker-1] = seatTaken
+		return true
+	}
+	q.seats[worker-1] = seatEmpty
+	return false
+}
+
+// oldest returns how long before now the job at the head of the queue was
+// enqueued, or zero if the queue is empty.
+func (q *taskQueue[In]) oldest(now time.Time) time.Duration {
+	since := q.headSince.Load()
+	if since == noHead {
+		return 0
+	}
+	return max(now.Sub(time.Unix(0, since)), 0)
+}
+
+// peek returns the job at the head of the queue.
+func (q *taskQueue[In]) peek() (job[In], bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.n == 0 {
+		return job[In]{}, false
+	}
+	return q.buf[q.head], true
+}
+
+func (q *taskQueue[In]) close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.closed = true
+	q.cond.Broadcast()
+	q.ready.Broadcast()
+}
+
+// reportDepth refreshes the size and head gauges, and publishes the
+// current length if it moved by at least depthStep. It requires q.mu,
+// which also makes it the only sender, so replacing an unread value can't
+// race another report: a slow subscriber just sees the latest depth
+// instead of stalling the queue.
+func (q *taskQueue[In]) reportDepth() {
+	q.size.Store(int64(q.n))
+	if q.n == 0 {
+		q.headSince.Store(noHead)
+	} else {
+		q.headSince.Store(q.buf[q.head].task.EnqueuedAt.UnixNano())
+	}
+
+	diff := q.n - q.reportedDepth
+	if diff < q.depthStep && -diff < q.depthStep {
+		return
+	}
+	q.reportedDepth = q.n
+	select {
+	case <-q.depth:
+	default:
+	}
+	q.depth <- q.n
+}
+
+// pushBack, popFront and removeAt require q.mu. pushBack grows the ring
+// when a retry pushes past capacity.
+func (q *taskQueue[In]) pushBack(j job[In]) {
+	q.pin(j, 1)
+	if q.n == len(q.buf) {
+		grown := make([]job[In], max(2*len(q.buf), 1))
+		for i := 0; i < q.n; i++ {
+			grown[i] = q.buf[(q.head+i)%len(q.buf)]
+		}
+		q.buf, q.head = grown, 0
+	}
+	q.seq++
+	j.seq = q.seq
+	q.buf[(q.head+q.n)%len(q.buf)] = j
+	q.n++
+	if q.edf != nil {
+		q.index(j)
+	}
+}
+
+// snapshot returns the queued jobs, oldest first.
+func (q *taskQueue[In]) snapshot() []job[In] {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	jobs := make([]job[In], q.n)
+	for i := range jobs {
+		jobs[i] = q.buf[(q.head+i)%len(q.buf)]
+	}
+	return jobs
+}
+
+// removeFunc removes and returns the queued jobs remove reports true for,
+// oldest first, keeping the others in order.
+func (q *taskQueue[In]) removeFunc(remove func(job[In]) bool) []job[In] {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var removed []job[In]
+	for i := 0; i < q.n; {
+		if remove(q.buf[(q.head+i)%len(q.buf)]) {
+			removed = append(removed, q.removeAt(i))
+		} else {
+			i++
+		}
+	}
+	if len(removed) > 0 {
+		q.reportDepth()
+		q.cond.Broadcast()
+	}
+	return removed
+}
+
+// takeAll removes and returns every queued job, oldest first.
+func (q *taskQueue[In]) takeAll() []job[In] {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	jobs := make([]job[In], 0, q.n)
+	for q.n > 0 {
+		jobs = append(jobs, q.popFront())
+	}
+	q.reportDepth()
+	q.cond.Broadcast()
+	return jobs
+}
+
+// removeAt takes out the job at position i, keeping the others in order.
+func (q *taskQueue[In]) removeAt(i int) job[In] {
+	if i == 0 {
+		return q.popFront()
+	}
+	at := func(k int) int { return (q.head + k) % len(q.buf) }
+	j := q.buf[at(i)]
+	for k := i; k < q.n-1; k++ {
+		q.buf[at(k)] = q.buf[at(k+1)]
+	}
+	q.buf[at(q.n-1)] = job[In]{}
+	q.n--
+	q.pin(j, -1)
+	return j
+}
+
+func (q *taskQueue[In]) popFront() job[In] {
+	j := q.buf[q.head]
+	q.buf[q.head] = job[In]{}
+	q.head = (q.head + 1) % len(q.buf)
+	q.n--
+	q.pin(j, -1)
+	return j
+}
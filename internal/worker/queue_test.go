@@ -0,0 +1,276 @@
+package worker_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rajatx185/golang-scalable-background-job-system/internal/ids"
+	"github.com/rajatx185/golang-scalable-background-job-system/internal/worker"
+)
+
+func TestOverflowPolicies(t *testing.T) {
+	tests := []struct {
+		policy     worker.OverflowPolicy
+		wantSubmit error  // from submitting the third task
+		wantDrop   string // task reported with ErrDropped
+	}{
+		{worker.OverflowBlock, context.DeadlineExceeded, ""},
+		{worker.OverflowDropNewest, nil, "c"},
+		{worker.OverflowDropOldest, nil, "b"},
+		{worker.OverflowError, worker.ErrQueueFull, ""},
+	}
+	for _, tt := range tests {
+		started := make(chan struct{}, 3)
+		release := make(chan struct{})
+		pool := worker.NewPool(worker.Config{Workers: 1, QueueSize: 1, Overflow: tt.policy},
+			func(ctx context.Context, t worker.Task) (string, error) {
+				started <- struct{}{}
+				<-release
+				return "ok", nil
+			})
+		ctx := context.Background()
+		pool.Start(ctx)
+
+		// Occupy the only worker, then fill the only queue slot.
+		if err := pool.Submit(ctx, worker.Task{ID: "a"}); err != nil {
+			t.Fatal(err)
+		}
+		<-started
+		if err := pool.Submit(ctx, worker.Task{ID: "b"}); err != nil {
+			t.Fatal(err)
+		}
+
+		submitCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+		err := pool.Submit(submitCtx, worker.Task{ID: "c"})
+		cancel()
+		if !errors.Is(err, tt.wantSubmit) {
+			t.Errorf("policy %d: Submit = %v, want %v", tt.policy, err, tt.wantSubmit)
+		}
+
+		close(release)
+		go pool.Close()
+		dropped := ""
+		for r := range pool.Results() {
+			if errors.Is(r.Err, worker.ErrDropped) {
+				dropped = r.ID
+			}
+		}
+		if dropped != tt.wantDrop {
+			t.Errorf("policy %d: dropped %q, want %q", tt.policy, dropped, tt.wantDrop)
+		}
+		wantCount := int64(0)
+		if tt.wantDrop != "" {
+			wantCount = 1
+		}
+		if n := pool.Stats().Dropped; n != wantCount {
+			t.Errorf("policy %d: Stats().Dropped = %d, want %d", tt.policy, n, wantCount)
+		}
+	}
+}
+
+func TestOverflowPoolTakesSpill(t *testing.T) {
+	ctx := context.Background()
+	backup := worker.NewPool(worker.Config{}, func(ctx context.Context, t worker.Task) (string, error) {
+		return "backup", nil
+	})
+	backup.Start(ctx)
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	pool := worker.NewPool(worker.Config{Workers: 1, QueueSize: 1, OverflowPool: backup},
+		func(ctx context.Context, t worker.Task) (string, error) {
+			started <- struct{}{}
+			<-release
+			return "primary", nil
+		})
+	pool.Start(ctx)
+
+	// Occupy the only worker and fill the only slot; the next task spills.
+	if err := pool.Submit(ctx, worker.Task{ID: "a"}); err != nil {
+		t.Fatal(err)
+	}
+	<-started
+	for _, id := range []string{"b", "spilled"} {
+		if err := pool.Submit(ctx, worker.Task{ID: id}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if r, err := backup.Wait(ctx, "spilled"); err != nil || r.Value != "backup" {
+		t.Fatalf("backup Wait = %+v, %v, want the spilled task run by the backup pool", r, err)
+	}
+	if _, err := pool.Wait(ctx, "spilled"); !errors.Is(err, worker.ErrUnknownJob) {
+		t.Fatalf("primary Wait = %v, want ErrUnknownJob for a spilled task", err)
+	}
+	if n := pool.Stats().Spilled; n != 1 {
+		t.Fatalf("Spilled = %d, want 1", n)
+	}
+
+	close(release)
+	go pool.Close()
+	var got []string
+	for r := range pool.Results() {
+		got = append(got, r.ID)
+	}
+	go backup.Close()
+	for range backup.Results() {
+	}
+	if slices.Sort(got); !slices.Equal(got, []string{"a", "b"}) {
+		t.Fatalf("primary results = %v, want a and b", got)
+	}
+}
+
+func TestQueueDepthReportsBySteps(t *testing.T) {
+	pool := worker.NewPool(worker.Config{QueueSize: 8, DepthStep: 2}, func(ctx context.Context, t worker.Task) (string, error) {
+		return "", nil
+	})
+	ctx := context.Background()
+	depth := pool.QueueDepth()
+	latest := func() (int, bool) {
+		select {
+		case d := <-depth:
+			return d, true
+		default:
+			return 0, false
+		}
+	}
+
+	// No workers yet, so every submit grows the queue.
+	for i, want := range []int{-1, 2, -1, 4} {
+		if err := pool.Submit(ctx, worker.Task{ID: string(rune('a' + i))}); err != nil {
+			t.Fatal(err)
+		}
+		d, ok := latest()
+		switch {
+		case want < 0 && ok:
+			t.Fatalf("after %d submits: reported depth %d, want no report", i+1, d)
+		case want >= 0 && (!ok || d != want):
+			t.Fatalf("after %d submits: depth = %d (reported %v), want %d", i+1, d, ok, want)
+		}
+	}
+
+	pool.Start(ctx)
+	go pool.Close()
+	for range pool.Results() {
+	}
+	if d, ok := latest(); !ok || d != 0 {
+		t.Fatalf("after draining: depth = %d (reported %v), want 0", d, ok)
+	}
+}
+
+func TestSubmitBatchStopsWhenQueueFills(t *testing.T) {
+	pool := worker.NewPool(worker.Config{QueueSize: 2, Overflow: worker.OverflowError}, func(ctx context.Context, t worker.Task) (string, error) {
+		return "", nil
+	})
+
+	// Workers aren't started, so the third task finds the queue full.
+	ids, err := pool.SubmitBatch(context.Background(), []worker.Task{{}, {ID: "given"}, {}, {}})
+	if !errors.Is(err, worker.ErrQueueFull) {
+		t.Fatalf("err = %v, want ErrQueueFull", err)
+	}
+	if len(ids) != 2 || ids[0] == "" || ids[1] != "given" {
+		t.Fatalf("ids = %q, want a generated ID then \"given\"", ids)
+	}
+	if want := "2 of 4 tasks rejected"; !strings.Contains(err.Error(), want) {
+		t.Fatalf("err = %q, want it to mention %q", err, want)
+	}
+}
+
+func TestSubmitBatchUsesIDGenerator(t *testing.T) {
+	pool := worker.NewPool(worker.Config{QueueSize: 3, IDGenerator: &ids.Sequence{}}, func(ctx context.Context, t worker.Task) (string, error) {
+		return "", nil
+	})
+	got, err := pool.SubmitBatch(context.Background(), []worker.Task{{}, {ID: "given"}, {}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"job-1", "given", "job-2"}; !slices.Equal(got, want) {
+		t.Fatalf("ids = %q, want %q", got, want)
+	}
+}
+
+func TestSubmitWithTimeout(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{}, 1)
+	pool := worker.NewPool(worker.Config{Workers: 1, QueueSize: 1}, func(ctx context.Context, t worker.Task) (string, error) {
+		select {
+		case started <- struct{}{}:
+		default:
+		}
+		<-release
+		return "", nil
+	})
+	pool.Start(context.Background())
+	results := make(chan int)
+	go func() {
+		n := 0
+		for range pool.Results() {
+			n++
+		}
+		results <- n
+	}()
+	if err := pool.Submit(context.Background(), worker.Task{ID: "running"}); err != nil {
+		t.Fatal(err)
+	}
+	<-started
+	if err := pool.SubmitWithTimeout(worker.Task{ID: "queued"}, time.Second); err != nil {
+		t.Fatalf("SubmitWithTimeout with room = %v", err)
+	}
+
+	if err := pool.SubmitWithTimeout(worker.Task{ID: "late"}, 20*time.Millisecond); !errors.Is(err, worker.ErrQueueFull) {
+		t.Fatalf("SubmitWithTimeout on a full queue = %v, want ErrQueueFull", err)
+	}
+	if n := pool.Stats().Queued; n != 1 {
+		t.Fatalf("Queued = %d after the timeout, want only the task that fit", n)
+	}
+	if _, err := pool.Wait(context.Background(), "late"); !errors.Is(err, worker.ErrUnknownJob) {
+		t.Fatalf("Wait on the timed-out task: err = %v, want ErrUnknownJob", err)
+	}
+
+	close(release)
+	pool.Close()
+	if n := <-results; n != 2 {
+		t.Fatalf("%d results, want 2", n)
+	}
+}
+
+func TestDroppedTaskHasNoDuration(t *testing.T) {
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	pool := worker.NewPool(worker.Config{Workers: 1, QueueSize: 1, Overflow: worker.OverflowDropOldest},
+		func(ctx context.Context, t worker.Task) (string, error) {
+			started <- struct{}{}
+			<-release
+			return "ok", nil
+		})
+	ctx := context.Background()
+	pool.Start(ctx)
+
+	// Occupy the only worker, fill the only queue slot, then push b out.
+	for _, id := range []string{"a", "b", "c"} {
+		if err := pool.Submit(ctx, worker.Task{ID: id}); err != nil {
+			t.Fatal(err)
+		}
+		if id == "a" {
+			<-started
+		}
+	}
+	for r := range pool.Results() {
+		if r.ID != "b" {
+			continue
+		}
+		if !errors.Is(r.Err, worker.ErrDropped) || r.Duration != 0 || !r.StartedAt.IsZero() {
+			t.Errorf("dropped result = %+v, want ErrDropped with no start time or Duration", r)
+		}
+		break
+	}
+	if avg, ok := pool.EMALatency(); ok {
+		t.Errorf("EMALatency = %v after only a drop, want no samples", avg)
+	}
+	close(release)
+	go pool.Close()
+	for range pool.Results() {
+	}
+}
@@ -0,0 +1,143 @@
+package worker
+
+import (
+	"context"
+	"reflect"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// tunables holds the settings Reconfigure can change while tasks run. The
+// pool reads them from here rather than from cfg, which never changes
+// after NewPool.
+type tunables struct {
+	defaultTimeout    atomic.Int64 // time.Duration
+	hardTimeoutGrace  atomic.Int64
+	slowTaskThreshold atomic.Int64
+}
+
+func (t *tunables) set(defaultTimeout, hardTimeoutGrace, slowTaskThreshold time.Duration) {
+	t.defaultTimeout.Store(int64(defaultTimeout))
+	t.hardTimeoutGrace.Store(int64(hardTimeoutGrace))
+	t.slowTaskThreshold.Store(int64(slowTaskThreshold))
+}
+
+func (p *TypedPool[In, Out]) defaultTimeout() time.Duration {
+	return time.Duration(p.tunables.defaultTimeout.Load())
+}
+
+func (p *TypedPool[In, Out]) hardTimeoutGrace() time.Duration {
+	return time.Duration(p.tunables.hardTimeoutGrace.Load())
+}
+
+func (p *TypedPool[In, Out]) slowTaskThreshold() time.Duration {
+	return time.Duration(p.tunables.slowTaskThreshold.Load())
+}
+
+// liveFields are the Config fields Reconfigure applies without a restart.
+// Workers is one only without MaxGoroutines, whose handler slots are
+// sized for the worker count NewPool saw.
+var liveFields = map[string]bool{
+	"Workers":           true,
+	"DefaultTimeout":    true,
+	"HardTimeoutGrace":  true,
+	"SlowTaskThreshold": true,
+	"RetryBudget":       true,
+}
+
+// Reconfigure applies the settings of cfg that can change while the pool
+// runs, and returns the names of the Config fields that differ from the
+// pool's but can't, each of which is also logged as needing a restart.
+// Fields are compared after defaults are applied, so leaving one zero
+// means its default, not "unchanged"; pass the whole config, as loaded
+// from wherever it lives.
+//
+// The live settings are:
+//   - Workers: new workers start after warming up like the first ones;
+//     the ones with the highest IDs finish the task they are running, if
+//     any, and exit. Affinity keys are spread over the new count, so a key's
+//     queued tasks may move to another worker. A pool that sized its queue
+//     from Workers keeps that size, and Consume keeps the workers it
+//     started with. With MaxGoroutines set, Workers needs a restart.
+//   - DefaultTimeout, HardTimeoutGrace and SlowTaskThreshold: from the
+//     next attempt on; attempts already running keep the ones they
+//     started with.
+//   - RetryBudget: the token bucket refills at the new rate, keeping the
+//     tokens it has, up to the new burst.
+//
+// Reconfigure on a closed pool changes nothing it would have to undo.
+func (p *TypedPool[In, Out]) Reconfigure(cfg TypedConfig[In]) (needRestart []string) {
+	cfg = cfg.withDefaults()
+	live := func(name string) bool {
+		return liveFields[name] && (name != "Workers" || p.cfg.MaxGoroutines == 0)
+	}
+	oldV, newV := reflect.ValueOf(p.cfg), reflect.ValueOf(cfg)
+	for i := range oldV.NumField() {
+		name := oldV.Type().Field(i).Name
+		if !live(name) && !sameSetting(oldV.Field(i), newV.Field(i)) {
+			needRestart = append(needRestart, name)
+			p.cfg.Logger.Warn("config change needs a restart, ignoring it", "field", name)
+		}
+	}
+
+	p.tunables.set(cfg.DefaultTimeout, cfg.HardTimeoutGrace, cfg.SlowTaskThreshold)
+	p.retryBudget.setRate(cfg.RetryBudget)
+	if live("Workers") {
+		p.resize(cfg.Workers)
+	}
+	return needRestart
+}
+
+// resize scales the pool to n workers. mu is held so Close either sees
+// the new workers counted or stops them from starting.
+func (p *TypedPool[In, Out]) resize(n int) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.closed {
+		return
+	}
+	if !p.queue.resize(n) {
+		return
+	}
+	if p.workerCtx == nil || p.warmupErr != nil {
+		return // Start claims the workers, or none run at all
+	}
+	for _, id := range p.queue.claim() {
+		p.succeed(p.workerCtx, p.runCtx, id)
+	}
+	p.cfg.Logger.Info("resized the pool", "workers", n)
+}
+
+// vacate records that worker id has exited without a replacement, and
+// starts a successor if resize wanted the ID back meanwhile.
+func (p *TypedPool[In, Out]) vacate(ctx, runCtx context.Context, id int) {
+	if id == 0 {
+		return
+	}
+	p.heartbeats.beats.Delete(strconv.Itoa(id))
+	if p.queue.vacate(id) {
+		p.succeed(ctx, runCtx, id)
+	}
+}
+
+// sameSetting reports whether a and b configure the pool alike. Functions
+// compare by identity, since reflect.DeepEqual only finds nil ones equal.
+func sameSetting(a, b reflect.Value) bool {
+	switch a.Kind() {
+	case reflect.Func:
+		return a.Pointer() == b.Pointer()
+	case reflect.Interface:
+		if a.IsNil() || b.IsNil() {
+			return a.IsNil() == b.IsNil()
+		}
+		a, b = a.Elem(), b.Elem()
+		return a.Type() == b.Type() && sameSetting(a, b)
+	case reflect.Pointer, reflect.Chan:
+		return a.Pointer() == b.Pointer()
+	}
+	if a.Comparable() {
+		return a.Equal(b)
+	}
+	return reflect.DeepEqual(a.Interface(), b.Interface())
+}
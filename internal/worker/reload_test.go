@@ -0,0 +1,95 @@
+package worker_test
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rajatx185/golang-scalable-background-job-system/internal/worker"
+)
+
+func TestReconfigureScalesWorkersLive(t *testing.T) {
+	running := make(chan string, 6)
+	release := make(chan struct{})
+	cfg := worker.Config{Workers: 1, QueueSize: 8}
+	pool := worker.NewPool(cfg, func(ctx context.Context, t worker.Task) (string, error) {
+		running <- t.ID
+		<-release
+		return "done " + t.ID, nil
+	})
+	pool.Start(context.Background())
+	got := make(chan worker.Result, 6)
+	go func() {
+		for r := range pool.Results() {
+			got <- r
+		}
+	}()
+	for i := range 6 {
+		if err := pool.Submit(context.Background(), worker.Task{ID: fmt.Sprint(i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	<-running
+
+	cfg.Workers = 3
+	if restart := pool.Reconfigure(cfg); restart != nil {
+		t.Fatalf("Reconfigure(Workers: 3) needs a restart for %v, want none", restart)
+	}
+	for range 2 {
+		select {
+		case <-running:
+		case <-time.After(time.Second):
+			t.Fatal("the added workers never picked up the queued tasks")
+		}
+	}
+	waitForWorkers(t, pool, 3)
+
+	// Shrinking lets the three running tasks finish; the rest run on the
+	// one worker left.
+	cfg.Workers = 1
+	pool.Reconfigure(cfg)
+	close(release)
+	for range 6 {
+		if r := <-got; r.Err != nil || r.Value != "done "+r.ID {
+			t.Fatalf("result %+v, want every task to finish across the resize", r)
+		}
+	}
+	waitForWorkers(t, pool, 1)
+	pool.Close()
+}
+
+func TestReconfigureAppliesTimeoutsAndFlagsTheRest(t *testing.T) {
+	var logs syncBuffer
+	cfg := worker.Config{
+		Workers:        2,
+		DefaultTimeout: time.Hour,
+		Logger:         slog.New(slog.NewTextHandler(&logs, nil)),
+	}
+	pool := worker.NewPool(cfg, deadlineProbe)
+	defer pool.Close()
+	if restart := pool.Reconfigure(cfg); restart != nil {
+		t.Fatalf("Reconfigure with the pool's own config needs a restart for %v, want none", restart)
+	}
+
+	cfg.DefaultTimeout = time.Second
+	cfg.QueueSize = 100
+	cfg.MaxRetries = 3
+	restart := pool.Reconfigure(cfg)
+	slices.Sort(restart)
+	if want := []string{"MaxRetries", "QueueSize"}; !slices.Equal(restart, want) {
+		t.Fatalf("Reconfigure needs a restart for %v, want %v", restart, want)
+	}
+	if !strings.Contains(logs.String(), "field=QueueSize") {
+		t.Fatalf("logs = %q, want a restart warning for QueueSize", logs.String())
+	}
+
+	r := pool.Process(context.Background(), []worker.Task{{ID: "a"}})[0]
+	left, err := time.ParseDuration(r.Value)
+	if err != nil || left <= 0 || left > time.Second {
+		t.Fatalf("task had %q left, want under the reloaded 1s DefaultTimeout", r.Value)
+	}
+}
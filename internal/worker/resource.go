@@ -0,0 +1,151 @@
+package worker
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"math"
+	"sync"
+)
+
+// ResourceUsage is how much of one Config.ResourceLimits entry is taken.
+type ResourceUsage struct {
+	Limit   int64
+	InUse   int64
+	Waiting int // tasks blocked waiting for capacity
+}
+
+// weightedSemaphore is a FIFO weighted semaphore: a waiter is only
+// admitted once everyone ahead of it has been, so a heavy task can't be
+// starved by a stream of light ones. The channel-backed
+// semaphore.Semaphore can't offer either weights or that order.
+type weightedSemaphore struct {
+	mu      sync.Mutex
+	limit   int64
+	inUse   int64
+	waiters list.List // of *semWaiter
+}
+
+type semWaiter struct {
+	n     int64
+	ready chan struct{}
+}
+
+// acquire blocks until n units are free or ctx is done.
+func (s *weightedSemaphore) acquire(ctx context.Context, n int64) error {
+	s.mu.Lock()
+	if s.waiters.Len() == 0 && s.inUse+n <= s.limit {
+		s.inUse += n
+		s.mu.Unlock()
+		return nil
+	}
+	w := &semWaiter{n: n, ready: make(chan struct{})}
+	elem := s.waiters.PushBack(w)
+	s.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return nil
+	case <-ctx.Done():
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		select {
+		case <-w.ready:
+			// Admitted just as ctx ended; hand the units back.
+			s.inUse -= n
+		default:
+			s.waiters.Remove(elem)
+		}
+		s.admit()
+		return ctx.Err()
+	}
+}
+
+func (s *weightedSemaphore) release(n int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.inUse -= n
+	s.admit()
+}
+
+// admit wakes waiters from the front while they fit. s.mu must be held.
+func (s *weightedSemaphore) admit() {
+	for e := s.waiters.Front(); e != nil; e = s.waiters.Front() {
+		w := e.Value.(*semWaiter)
+		if s.inUse+w.n > s.limit {
+			return
+		}
+		s.inUse += w.n
+		s.waiters.Remove(e)
+		close(w.ready)
+	}
+}
+
+func (s *weightedSemaphore) usage() ResourceUsage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return ResourceUsage{Limit: s.limit, InUse: s.inUse, Waiting: s.waiters.Len()}
+}
+
+// resources holds one semaphore per limited resource key. The set of keys
+// is fixed at NewPool, so the map is read without locking.
+type resources map[string]*weightedSemaphore
+
+func newResources(limits map[string]int64) resources {
+	r := make(resources, len(limits))
+	for key, limit := range limits {
+		r[key] = &weightedSemaphore{limit: limit}
+	}
+	return r
+}
+
+// acquire takes a task's weight of resource key and returns the function
+// that gives it back. Tasks without a limited resource pass straight
+// through.
+func (r resources) acquire(ctx context.Context, key string, weight int64) (release func(), err error) {
+	sem, ok := r[key]
+	if !ok {
+		return func() {}, nil
+	}
+	n := max(weight, 1)
+	if n > sem.limit {
+		return nil, fmt.Errorf("%w: weight %d, %q allows %d", ErrOverResourceLimit, n, key, sem.limit)
+	}
+	if err := sem.acquire(ctx, n); err != nil {
+		return nil, err
+	}
+	return func() { sem.release(n) }, nil
+}
+
+func (r resources) usage() map[string]ResourceUsage {
+	if len(r) == 0 {
+		return nil
+	}
+	usage := make(map[string]ResourceUsage, len(r))
+	for key, sem := range r {
+		usage[key] = sem.usage()
+	}
+	return usage
+}
+
+// newWeightLimit returns the semaphore behind Config.MaxWeight. Without a
+// cap it never blocks, but still tracks the weight in flight for Stats.
+func newWeightLimit(limit int) *weightedSemaphore {
+	if limit <= 0 {
+		return &weightedSemaphore{limit: math.MaxInt64}
+	}
+	return &weightedSemaphore{limit: int64(limit)}
+}
+
+// acquireWeight takes a task's Weight of Config.MaxWeight and returns the
+// function that gives it back.
+func (p *TypedPool[In, Out]) acquireWeight(ctx context.Context, weight int) (release func(), err error) {
+	n := int64(max(weight, 1))
+	if n > p.weights.limit {
+		return nil, fmt.Errorf("%w: weight %d, MaxWeight allows %d", ErrOverResourceLimit, n, p.weights.limit)
+	}
+	if err := p.weights.acquire(ctx, n); err != nil {
+		return nil, err
+	}
+	return func() { p.weights.release(n) }, nil
+}
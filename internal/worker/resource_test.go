@@ -0,0 +1,108 @@
+package worker_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rajatx185/golang-scalable-background-job-system/internal/worker"
+)
+
+func TestResourceLimitThrottlesAcrossWorkers(t *testing.T) {
+	var running, peak atomic.Int64
+	release := make(chan struct{})
+	pool := worker.NewPool(worker.Config{Workers: 6, QueueSize: 8, ResourceLimits: map[string]int64{"db": 2}},
+		func(ctx context.Context, t worker.Task) (string, error) {
+			if t.ResourceKey == "db" {
+				n := running.Add(1)
+				defer running.Add(-1)
+				for p := peak.Load(); n > p && !peak.CompareAndSwap(p, n); p = peak.Load() {
+				}
+			}
+			<-release
+			return "", nil
+		})
+
+	tasks := []worker.Task{{ID: "free"}, {ID: "too-heavy", ResourceKey: "db", ResourceWeight: 3}}
+	for i := range 5 {
+		tasks = append(tasks, worker.Task{ID: fmt.Sprint("db-", i), ResourceKey: "db"})
+	}
+	done := make(chan []worker.Result)
+	go func() { done <- pool.Process(context.Background(), tasks) }()
+
+	// Two db tasks hold the limit and three wait, alongside the free task.
+	deadline := time.Now().Add(time.Second)
+	want := worker.ResourceUsage{Limit: 2, InUse: 2, Waiting: 3}
+	for pool.Stats().Resources["db"] != want && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if got := pool.Stats().Resources["db"]; got != want {
+		t.Fatalf("db usage = %+v, want %+v", got, want)
+	}
+	close(release)
+
+	for _, r := range <-done {
+		if r.ID == "too-heavy" {
+			if !errors.Is(r.Err, worker.ErrOverResourceLimit) {
+				t.Errorf("too-heavy: err = %v, want ErrOverResourceLimit", r.Err)
+			}
+		} else if r.Err != nil {
+			t.Errorf("task %s: %v", r.ID, r.Err)
+		}
+	}
+	if p := peak.Load(); p != 2 {
+		t.Fatalf("peak db concurrency = %d, want 2", p)
+	}
+}
+
+func TestMaxWeightCapsInFlightWeight(t *testing.T) {
+	var running, peak atomic.Int64
+	release := make(chan struct{})
+	pool := worker.NewPool(worker.Config{Workers: 5, QueueSize: 8, MaxWeight: 10},
+		func(ctx context.Context, t worker.Task) (string, error) {
+			n := running.Add(int64(max(t.Weight, 1)))
+			defer running.Add(-int64(max(t.Weight, 1)))
+			for p := peak.Load(); n > p && !peak.CompareAndSwap(p, n); p = peak.Load() {
+			}
+			<-release
+			return "", nil
+		})
+
+	tasks := []worker.Task{{ID: "heavy", Weight: 10}, {ID: "too-heavy", Weight: 11}}
+	for i := range 3 {
+		tasks = append(tasks, worker.Task{ID: fmt.Sprint("light-", i)})
+	}
+	done := make(chan []worker.Result)
+	go func() { done <- pool.Process(context.Background(), tasks) }()
+
+	// Whichever runs first, the heavy task or the light ones, the other
+	// side waits: the two don't fit together.
+	deadline := time.Now().Add(time.Second)
+	for pool.Stats().InFlightWeight == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(20 * time.Millisecond)
+	if w := pool.Stats().InFlightWeight; w != 10 && w != 3 {
+		t.Fatalf("InFlightWeight = %d, want the heavy task's 10 or the light tasks' 3", w)
+	}
+	close(release)
+
+	for _, r := range <-done {
+		if r.ID == "too-heavy" {
+			if !errors.Is(r.Err, worker.ErrOverResourceLimit) {
+				t.Errorf("too-heavy: err = %v, want ErrOverResourceLimit", r.Err)
+			}
+		} else if r.Err != nil {
+			t.Errorf("task %s: %v", r.ID, r.Err)
+		}
+	}
+	if p := peak.Load(); p != 10 {
+		t.Fatalf("peak in-flight weight = %d, want 10", p)
+	}
+	if w := pool.Stats().InFlightWeight; w != 0 {
+		t.Fatalf("InFlightWeight = %d after every task finished, want 0", w)
+	}
+}
@@ -0,0 +1,169 @@
+package worker
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// TypedResultSink stores final Results outside the process, so finished
+// work survives a crash; see WriteResultsTo.
+type TypedResultSink[Out any] interface {
+	Write(TypedResult[Out]) error
+	// Close flushes whatever Write buffered and releases the sink.
+	Close() error
+}
+
+// ResultSink is the TypedResultSink for string results.
+type ResultSink = TypedResultSink[string]
+
+// FileSink appends each Result to a file as a line of JSON. Writes are
+// buffered, so a crash loses the results written since the last Flush,
+// and may tear the line it was writing; ReadFileSink skips torn lines.
+type FileSink[Out any] struct {
+	mu  sync.Mutex
+	f   *os.File
+	buf *bufio.Writer
+}
+
+// sinkRecord is a Result as FileSink writes it: Err is kept as its
+// message, since an error value doesn't survive JSON.
+type sinkRecord[Out any] struct {
+	ID        string
+	Value     Out
+	Err       string            `json:",omitempty"`
+	Truncated bool              `json:",omitempty"`
+	Cached    bool              `json:",omitempty"`
+	Metadata  map[string]string `json:",omitempty"`
+	Attempt   int
+	StartedAt time.Time
+	Duration  time.Duration
+}
+
+// OpenFileSink opens path for appending results, creating it if needed.
+// Results already in the file are kept, so a restarted job adds to the
+// record of the run before it.
+func OpenFileSink[Out any](path string) (*FileSink[Out], error) {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	s := &FileSink[Out]{f: f, buf: bufio.NewWriter(f)}
+	// End a line torn by a crash, so the first new result starts a line
+	// of its own instead of running on from it.
+	if info, err := f.Stat(); err == nil && info.Size() > 0 {
+		last := make([]byte, 1)
+		if _, err := f.ReadAt(last, info.Size()-1); err == nil && last[0] != '\n' {
+			s.buf.WriteByte('\n')
+		}
+	}
+	return s, nil
+}
+
+// Write buffers r as the next line of the file.
+func (s *FileSink[Out]) Write(r TypedResult[Out]) error {
+	rec := sinkRecord[Out]{
+		ID: r.ID, Value: r.Value, Truncated: r.Truncated, Cached: r.Cached,
+		Metadata: r.Metadata, Attempt: r.Attempt, StartedAt: r.StartedAt, Duration: r.Duration,
+	}
+	if r.Err != nil {
+		rec.Err = r.Err.Error()
+	}
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.buf.Write(line)
+	return s.buf.WriteByte('\n')
+}
+
+// Flush writes the buffered results through to the file.
+func (s *FileSink[Out]) Flush() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.buf.Flush()
+}
+
+// Close flushes the buffered results and closes the file.
+func (s *FileSink[Out]) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return errors.Join(s.buf.Flush(), s.f.Close())
+}
+
+// ReadFileSink returns the results a FileSink wrote to path, oldest first,
+// so a restarted job can skip the tasks that already finished. A missing
+// file reads as no results. Each Err comes back as an error with the
+// original message only: errors.Is no longer matches the sentinels. Lines
+// that aren't valid JSON were torn by a crash and are skipped.
+func ReadFileSink[Out any](path string) ([]TypedResult[Out], error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var results []TypedResult[Out]
+	r := bufio.NewReader(f)
+	for {
+		line, err := r.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return results, err
+		}
+		var rec sinkRecord[Out]
+		if json.Unmarshal(line, &rec) == nil {
+			res := TypedResult[Out]{
+				ID: rec.ID, Value: rec.Value, Truncated: rec.Truncated, Cached: rec.Cached,
+				Metadata: rec.Metadata, Attempt: rec.Attempt, StartedAt: rec.StartedAt, Duration: rec.Duration,
+			}
+			if rec.Err != "" {
+				res.Err = errors.New(rec.Err)
+			}
+			results = append(results, res)
+		}
+		if err == io.EOF {
+			return results, nil
+		}
+	}
+}
+
+// WriteResultsTo makes the pool write every final Result to sink as the
+// task finishes, whether it goes out on Results, to Wait or to a Process
+// batch, and before a slow collector gets to it. Tasks submitted with
+// SubmitAndForget are not written. A failed write is logged and the
+// result delivered anyway. Close closes sink once the last result is in,
+// which flushes it. Call it before submitting any tasks.
+func (p *TypedPool[In, Out]) WriteResultsTo(sink TypedResultSink[Out]) {
+	p.sink.Store(&sink)
+}
+
+// writeResult hands res to the sink, if there is one.
+func (p *TypedPool[In, Out]) writeResult(res TypedResult[Out]) {
+	sink := p.sink.Load()
+	if sink == nil {
+		return
+	}
+	if err := (*sink).Write(res); err != nil {
+		p.cfg.Logger.Warn("result sink write failed", "task", res.ID, "err", err)
+	}
+}
+
+// closeSink closes the sink, if there is one.
+func (p *TypedPool[In, Out]) closeSink() {
+	sink := p.sink.Load()
+	if sink == nil {
+		return
+	}
+	if err := (*sink).Close(); err != nil {
+		p.cfg.Logger.Error("closing result sink", "err", err)
+	}
+}
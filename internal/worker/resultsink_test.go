@@ -0,0 +1,74 @@
+package worker_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/rajatx185/golang-scalable-background-job-system/internal/worker"
+)
+
+func TestFileSinkSurvivesRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "results.jsonl")
+	handler := func(ctx context.Context, t worker.Task) (string, error) {
+		if t.ID == "bad" {
+			return "", errors.New("boom")
+		}
+		return "done " + t.ID, nil
+	}
+	run := func(tasks []worker.Task) {
+		sink, err := worker.OpenFileSink[string](path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pool := worker.NewPool(worker.Config{Workers: 2}, handler)
+		pool.WriteResultsTo(sink)
+		pool.Process(context.Background(), tasks)
+		pool.Close()
+	}
+
+	run([]worker.Task{{ID: "a"}, {ID: "bad"}})
+	// A crash mid-write leaves a torn line behind.
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"ID":"torn","Val`)
+	f.Close()
+
+	done, err := worker.ReadFileSink[string](path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	finished := map[string]worker.Result{}
+	for _, r := range done {
+		finished[r.ID] = r
+	}
+	if len(finished) != 2 || finished["a"].Value != "done a" || finished["bad"].Err == nil || finished["bad"].Err.Error() != "boom" {
+		t.Fatalf("sink held %+v, want a's value and bad's error", done)
+	}
+
+	// The restarted job skips what already succeeded.
+	var rerun []worker.Task
+	for _, task := range []worker.Task{{ID: "a"}, {ID: "bad"}, {ID: "c"}} {
+		if r, ok := finished[task.ID]; !ok || r.Err != nil {
+			rerun = append(rerun, task)
+		}
+	}
+	run(rerun)
+	done, err = worker.ReadFileSink[string](path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, r := range done {
+		ids = append(ids, r.ID)
+	}
+	slices.Sort(ids)
+	if !slices.Equal(ids, []string{"a", "bad", "bad", "c"}) {
+		t.Fatalf("sink holds %v after the rerun, want a once and bad twice, plus c", ids)
+	}
+}
@@ -0,0 +1,146 @@
+package worker
+
+import (
+	"container/heap"
+	"context"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rajatx185/golang-scalable-background-job-system/internal/clock"
+)
+
+// retryQueue holds failed jobs until their backoff expires, in a min-heap
+// by due time. A single goroutine (run) with a single timer, kept set to
+// the earliest due time, moves due jobs back onto the main queue, so
+// thousands of pending retries cost neither a goroutine nor a timer each.
+type retryQueue[In any] struct {
+	mu    sync.Mutex
+	items retryHeap[In]
+	wake  chan struct{}
+	clock clock.Clock
+	size  atomic.Int64 // len(items), readable without mu
+}
+
+func newRetryQueue[In any](clk clock.Clock) *retryQueue[In] {
+	return &retryQueue[In]{wake: make(chan struct{}, 1), clock: clk}
+}
+
+// push schedules j to re-enter the main queue at due.
+func (q *retryQueue[In]) push(j job[In], due time.Time) {
+	q.mu.Lock()
+	heap.Push(&q.items, retryItem[In]{job: j, due: due})
+	q.size.Store(int64(len(q.items)))
+	q.mu.Unlock()
+	// j may be the earliest item now.
+	q.nudge()
+}
+
+// nudge wakes run to set its timer again.
+func (q *retryQueue[In]) nudge() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+// popDue removes and returns every job due at or before now (every job at
+// all when flush is set), plus how long until the next one is due, or -1 if
+// the queue is empty.
+func (q *retryQueue[In]) popDue(now time.Time, flush bool) ([]job[In], time.Duration) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var due []job[In]
+	for len(q.items) > 0 && (flush || !q.items[0].due.After(now)) {
+		due = append(due, heap.Pop(&q.items).(retryItem[In]).job)
+	}
+	q.size.Store(int64(len(q.items)))
+	if len(q.items) == 0 {
+		return due, -1
+	}
+	return due, q.items[0].due.Sub(now)
+}
+
+// snapshot returns the waiting jobs, soonest due first.
+func (q *retryQueue[In]) snapshot() []retryItem[In] {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	items := slices.Clone(q.items)
+	slices.SortStableFunc(items, func(a, b retryItem[In]) int { return a.due.Compare(b.due) })
+	return items
+}
+
+// takeAll removes and returns every waiting job, soonest due first.
+// It takes them in one critical section, so a job pushed or released by
+// run meanwhile is either taken or left, never lost or taken twice.
+func (q *retryQueue[In]) takeAll() []retryItem[In] {
+	q.mu.Lock()
+	items := q.items
+	q.items = nil
+	q.size.Store(0)
+	q.mu.Unlock()
+	q.nudge()
+	slices.SortStableFunc(items, func(a, b retryItem[In]) int { return a.due.Compare(b.due) })
+	return items
+}
+
+// run moves due jobs onto out until stop is closed. Once ctx is done the
+// backoff no longer matters, so everything pending is released at once and
+// the workers report it as cancelled.
+func (q *retryQueue[In]) run(ctx context.Context, out *taskQueue[In], stop <-chan struct{}) {
+	ctxDone := ctx.Done()
+	var timer clock.Timer // made on first use, then reset
+	defer func() {
+		if timer != nil {
+			timer.Stop()
+		}
+	}()
+	for {
+		due, next := q.popDue(q.clock.Now(), ctx.Err() != nil)
+		for _, j := range due {
+			out.pushRetry(j)
+		}
+
+		var fire <-chan time.Time
+		switch {
+		case next < 0:
+			if timer != nil {
+				timer.Stop()
+			}
+		case timer == nil:
+			timer = q.clock.NewTimer(next)
+			fire = timer.C()
+		default:
+			timer.Reset(next)
+			fire = timer.C()
+		}
+		select {
+		case <-fire:
+		case <-q.wake:
+		case <-ctxDone:
+			ctxDone = nil
+		case <-stop:
+			return
+		}
+	}
+}
+
+type retryItem[In any] struct {
+	job job[In]
+	due time.Time
+}
+
+// retryHeap is a min-heap of retryItems ordered by due time.
+type retryHeap[In any] []retryItem[In]
+
+func (h retryHeap[In]) Len() int           { return len(h) }
+func (h retryHeap[In]) Less(i, j int) bool { return h[i].due.Before(h[j].due) }
+func (h retryHeap[In]) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *retryHeap[In]) Push(x any)        { *h = append(*h, x.(retryItem[In])) }
+func (h *retryHeap[In]) Pop() any {
+	old := *h
+	item := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return item
+}
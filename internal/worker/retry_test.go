@@ -0,0 +1,52 @@
+package worker_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/rajatx185/golang-scalable-background-job-system/internal/worker"
+)
+
+// spread staggers retry delays over 0-10ms, out of order, so the retry
+// queue's timer keeps having to move.
+func spread(i int) time.Duration {
+	return time.Duration(i*7919%10) * time.Millisecond
+}
+
+func TestPendingRetriesShareOneGoroutine(t *testing.T) {
+	if extra := worker.RelayRetries(5000, spread); extra > 1 {
+		t.Fatalf("%d goroutines for 5000 pending retries, want just the retry loop", extra)
+	}
+}
+
+// BenchmarkRetryScheduling relays a batch of delayed retries through the
+// retry queue, against a goroutine sleeping per retry.
+func BenchmarkRetryScheduling(b *testing.B) {
+	for _, n := range []int{100, 10000} {
+		for _, impl := range []struct {
+			name  string
+			relay func(int, func(int) time.Duration) int
+		}{
+			{"heap", worker.RelayRetries},
+			{"goroutine-per-retry", worker.RelayRetriesNaive},
+		} {
+			b.Run(fmt.Sprintf("%s/retries=%d", impl.name, n), func(b *testing.B) {
+				b.ReportAllocs()
+				goroutines := 0
+				for b.Loop() {
+					goroutines = impl.relay(n, spread)
+				}
+				b.ReportMetric(float64(goroutines), "goroutines")
+			})
+		}
+	}
+}
+
+func TestTakeAllLosesNoConcurrentRetry(t *testing.T) {
+	for range 20 {
+		if taken := worker.TakeRetriesWhilePushing(200); taken != 200 {
+			t.Fatalf("took %d of 200 retries pushed during takeAll, want all", taken)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package worker
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/rajatx185/golang-scalable-background-job-system/internal/clock"
+)
+
+// retryBudget is a token bucket shared by every retry in the pool; see
+// Config.RetryBudget. A retry always gets a token, but once the bucket is
+// empty it borrows against future refills, and reserve tells it to wait
+// until the token it took would have been there.
+type retryBudget struct {
+	clock clock.Clock
+
+	mu     sync.Mutex
+	rate   float64 // tokens per second; zero disables the budget
+	burst  float64
+	tokens float64 // negative while retries are waiting on the budget
+	last   time.Time
+}
+
+func newRetryBudget(rate float64, clk clock.Clock) *retryBudget {
+	burst := math.Max(math.Ceil(rate), 1)
+	return &retryBudget{rate: rate, burst: burst, clock: clk, tokens: burst, last: clk.Now()}
+}
+
+// setRate retunes the budget to rate, for Reconfigure. The tokens earned
+// so far are kept, up to the new burst, and the debt of waiting retries
+// is repaid at the new rate from now on.
+func (b *retryBudget) setRate(rate float64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := b.clock.Now()
+	if b.rate > 0 {
+		b.refillLocked(now)
+	}
+	b.rate, b.burst = rate, math.Max(math.Ceil(rate), 1)
+	b.tokens = math.Min(b.tokens, b.burst)
+	b.last = now
+}
+
+// refillLocked adds the tokens earned since the last call. It requires
+// b.mu.
+func (b *retryBudget) refillLocked(now time.Time) {
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens = math.Min(b.tokens+elapsed.Seconds()*b.rate, b.burst)
+		b.last = now
+	}
+}
+
+// reserve takes a token for one retry and returns the earliest time it may
+// run: now, unless the budget is exhausted.
+func (b *retryBudget) reserve() time.Time {
+	now := b.clock.Now()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.rate <= 0 {
+		return now
+	}
+	b.refillLocked(now)
+	b.tokens--
+	if b.tokens >= 0 {
+		return now
+	}
+	return now.Add(time.Duration(-b.tokens / b.rate * float64(time.Second)))
+}
+
+// utilization is the share of the burst in use, from 0 for an idle budget
+// to 1 for an exhausted one.
+func (b *retryBudget) utilization() float64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.rate <= 0 {
+		return 0
+	}
+	b.refillLocked(b.clock.Now())
+	return math.Min((b.burst-b.tokens)/b.burst, 1)
+}
+
+// retryAt returns when the next attempt of a task that has made attempts
+// attempts should run: after its backoff, and no sooner than the retry
+// budget allows.
+func (p *TypedPool[In, Out]) retryAt(attempts int) time.Time {
+	due := p.cfg.Clock.Now().Add(p.backoff(attempts))
+	if allowed := p.retryBudget.reserve(); allowed.After(due) {
+		return allowed
+	}
+	return due
+}
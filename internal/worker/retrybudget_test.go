@@ -0,0 +1,59 @@
+package worker_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rajatx185/golang-scalable-background-job-system/internal/clock"
+	"github.com/rajatx185/golang-scalable-background-job-system/internal/worker"
+)
+
+func TestRetryBudgetSpacesOutRetries(t *testing.T) {
+	clk := clock.NewManual(time.Unix(0, 0))
+	var mu sync.Mutex
+	failed := map[string]bool{}
+	pool := worker.NewPool(worker.Config{
+		Workers:     3,
+		QueueSize:   3,
+		MaxRetries:  1,
+		IsRetryable: retryAll,
+		RetryBudget: 1, // one retry a second, pool-wide
+		Clock:       clk,
+	}, func(ctx context.Context, t worker.Task) (string, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if !failed[t.ID] {
+			failed[t.ID] = true
+			return "", errors.New("downstream unavailable")
+		}
+		return "ok", nil
+	})
+	defer pool.Close()
+	go pool.Process(context.Background(), []worker.Task{{ID: "a"}, {ID: "b"}, {ID: "c"}})
+
+	// With no backoff the first retry runs at once; the budget holds the
+	// other two back a second each.
+	for want := int64(1); want <= 3; want++ {
+		deadline := time.Now().Add(time.Second)
+		for pool.Stats().Succeeded < want && time.Now().Before(deadline) {
+			time.Sleep(time.Millisecond)
+		}
+		s := pool.Stats()
+		if s.Succeeded != want {
+			t.Fatalf("%s: %d succeeded, want %d", clk.Now().Sub(time.Unix(0, 0)), s.Succeeded, want)
+		}
+		if want < 3 {
+			if s.RetryBudgetUtilization != 1 {
+				t.Fatalf("RetryBudgetUtilization = %v with retries held back, want 1", s.RetryBudgetUtilization)
+			}
+			time.Sleep(10 * time.Millisecond)
+			if n := pool.Stats().Succeeded; n != want {
+				t.Fatalf("a retry ran before the budget allowed: %d succeeded", n)
+			}
+		}
+		clk.Advance(time.Second)
+	}
+}
@@ -0,0 +1,58 @@
+package worker
+
+import (
+	"slices"
+	"sync"
+)
+
+// ShutdownReport accounts for every task that was still unfinished when
+// Shutdown was called, by task ID, so the caller can persist or resubmit
+// the leftovers elsewhere. Each list is in the order tasks reached their
+// final result.
+type ShutdownReport struct {
+	// NotStarted lists tasks that were queued or waiting on a retry and
+	// were abandoned without running again.
+	NotStarted []string
+	// Cancelled lists tasks that were running and did not succeed, whether
+	// the grace period ran out or they failed on their own.
+	Cancelled []string
+	// Completed lists tasks that succeeded during the grace period.
+	Completed []string
+}
+
+// shutdownLog builds a ShutdownReport from the results that land after
+// Shutdown was called.
+type shutdownLog struct {
+	mu     sync.Mutex
+	report ShutdownReport
+	sealed bool
+}
+
+func (l *shutdownLog) record(id string, running bool, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.sealed {
+		return
+	}
+	switch {
+	case err == nil:
+		l.report.Completed = append(l.report.Completed, id)
+	case running:
+		l.report.Cancelled = append(l.report.Cancelled, id)
+	default:
+		l.report.NotStarted = append(l.report.NotStarted, id)
+	}
+}
+
+// seal stops recording and returns the report, with the tasks Shutdown
+// stopped waiting for added in ID order.
+func (l *shutdownLog) seal(queued, running []string) ShutdownReport {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.sealed = true
+	slices.Sort(queued)
+	slices.Sort(running)
+	l.report.NotStarted = append(l.report.NotStarted, queued...)
+	l.report.Cancelled = append(l.report.Cancelled, running...)
+	return l.report
+}
@@ -0,0 +1,374 @@
+package worker_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rajatx185/golang-scalable-background-job-system/internal/worker"
+)
+
+// syncBuffer is a bytes.Buffer safe for the pool's concurrent log writes.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestShutdownLetsRunningTaskFinish(t *testing.T) {
+	tests := []struct {
+		name    string
+		grace   time.Duration
+		wantErr error
+		wantLog string
+	}{
+		{"finishes within grace", time.Second, nil, "finished cleanly during shutdown"},
+		{"hard-killed at deadline", 10 * time.Millisecond, context.Canceled, "hard-killed at shutdown deadline"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var logs syncBuffer
+			started := make(chan struct{})
+			pool := worker.NewPool(worker.Config{
+				ShutdownGrace: tt.grace,
+				Logger:        slog.New(slog.NewTextHandler(&logs, nil)),
+			}, func(ctx context.Context, t worker.Task) (string, error) {
+				close(started)
+				select {
+				case <-time.After(100 * time.Millisecond):
+					return "done", nil
+				case <-ctx.Done():
+					return "", ctx.Err()
+				}
+			})
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			done := make(chan []worker.Result)
+			go func() { done <- pool.Process(ctx, []worker.Task{{ID: "side-effect"}}) }()
+			<-started
+			cancel()
+
+			results := <-done
+			if len(results) != 1 || !errors.Is(results[0].Err, tt.wantErr) {
+				t.Fatalf("results = %+v, want one with err %v", results, tt.wantErr)
+			}
+			if !strings.Contains(logs.String(), tt.wantLog) {
+				t.Fatalf("logs = %q, want %q", logs.String(), tt.wantLog)
+			}
+		})
+	}
+}
+
+func TestDrainFinishesBacklog(t *testing.T) {
+	pool := worker.NewPool(worker.Config{QueueSize: 5}, func(ctx context.Context, t worker.Task) (string, error) {
+		time.Sleep(time.Millisecond)
+		return "ok", nil
+	})
+	ctx := context.Background()
+	for i := range 5 {
+		if err := pool.Submit(ctx, worker.Task{ID: fmt.Sprint(i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	pool.Start(ctx)
+	if err := pool.Drain(ctx); err != nil {
+		t.Fatalf("Drain: %v", err)
+	}
+	if err := pool.Submit(ctx, worker.Task{ID: "late"}); !errors.Is(err, worker.ErrDraining) {
+		t.Fatalf("Submit after Drain: err = %v, want ErrDraining", err)
+	}
+
+	n := 0
+	for r := range pool.Results() {
+		if r.Err != nil {
+			t.Errorf("task %s: %v", r.ID, r.Err)
+		}
+		n++
+	}
+	if n != 5 {
+		t.Fatalf("drained %d tasks, want 5", n)
+	}
+}
+
+func TestDrainDeadlineAbandonsBacklog(t *testing.T) {
+	pool := worker.NewPool(worker.Config{QueueSize: 3}, func(ctx context.Context, t worker.Task) (string, error) {
+		<-ctx.Done()
+		return "", ctx.Err()
+	})
+	for i := range 3 {
+		if err := pool.Submit(context.Background(), worker.Task{ID: fmt.Sprint(i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	pool.Start(context.Background())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := pool.Drain(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Drain: err = %v, want DeadlineExceeded", err)
+	}
+	n := 0
+	for r := range pool.Results() {
+		if !errors.Is(r.Err, context.Canceled) {
+			t.Errorf("task %s: err = %v, want context.Canceled", r.ID, r.Err)
+		}
+		n++
+	}
+	if n != 3 {
+		t.Fatalf("got %d results, want 3", n)
+	}
+}
+
+func TestShutdownAbandonsQueuedTasks(t *testing.T) {
+	started := make(chan struct{})
+	pool := worker.NewPool(worker.Config{QueueSize: 3}, func(ctx context.Context, t worker.Task) (string, error) {
+		close(started)
+		<-ctx.Done()
+		return "", ctx.Err()
+	})
+	pool.Start(context.Background())
+	for i := range 3 {
+		if err := pool.Submit(context.Background(), worker.Task{ID: fmt.Sprint(i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	<-started
+	pool.Shutdown(context.Background())
+
+	attempts := 0
+	for r := range pool.Results() {
+		if !errors.Is(r.Err, context.Canceled) {
+			t.Errorf("task %s: err = %v, want context.Canceled", r.ID, r.Err)
+		}
+		attempts += r.Attempt
+	}
+	if attempts != 1 {
+		t.Fatalf("%d attempts ran, want only the one already in flight", attempts)
+	}
+}
+
+func TestShutdownReport(t *testing.T) {
+	running := make(chan struct{}, 2)
+	pool := worker.NewPool(worker.Config{
+		Workers:       2,
+		QueueSize:     4,
+		ShutdownGrace: 50 * time.Millisecond,
+		Logger:        slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}, func(ctx context.Context, t worker.Task) (string, error) {
+		running <- struct{}{}
+		if t.ID == "finishes" {
+			time.Sleep(10 * time.Millisecond)
+			return "ok", nil
+		}
+		<-ctx.Done()
+		return "", ctx.Err()
+	})
+	pool.Start(context.Background())
+	for _, id := range []string{"finishes", "outlives-grace"} {
+		if err := pool.Submit(context.Background(), worker.Task{ID: id}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	<-running
+	<-running
+	// Both workers are busy, so these wait in the queue.
+	for _, id := range []string{"q1", "q2"} {
+		if err := pool.Submit(context.Background(), worker.Task{ID: id}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	report := pool.Shutdown(context.Background())
+	slices.Sort(report.NotStarted)
+	want := worker.ShutdownReport{NotStarted: []string{"q1", "q2"}, Cancelled: []string{"outlives-grace"}, Completed: []string{"finishes"}}
+	if !reflect.DeepEqual(report, want) {
+		t.Fatalf("report = %+v, want %+v", report, want)
+	}
+	for range pool.Results() {
+	}
+}
+
+func TestShutdownReportAtDeadline(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{})
+	pool := worker.NewPool(worker.Config{QueueSize: 2, Logger: slog.New(slog.NewTextHandler(io.Discard, nil))}, func(ctx context.Context, t worker.Task) (string, error) {
+		close(started)
+		<-release // ignores ctx
+		return "", nil
+	})
+	pool.Start(context.Background())
+	for _, id := range []string{"stuck", "queued"} {
+		if err := pool.Submit(context.Background(), worker.Task{ID: id}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	report := pool.Shutdown(ctx)
+	want := worker.ShutdownReport{NotStarted: []string{"queued"}, Cancelled: []string{"stuck"}}
+	if !reflect.DeepEqual(report, want) {
+		t.Fatalf("report = %+v, want %+v", report, want)
+	}
+	close(release)
+	for range pool.Results() {
+	}
+}
+
+func TestConcurrentShutdownPaths(t *testing.T) {
+	pool := worker.NewPool(worker.Config{QueueSize: 4, Logger: slog.New(slog.NewTextHandler(io.Discard, nil))},
+		func(ctx context.Context, t worker.Task) (string, error) {
+			time.Sleep(time.Millisecond)
+			return "ok", nil
+		})
+	pool.Subscribe(func(context.Context, worker.Event) {})
+	pool.Start(context.Background())
+	for i := range 4 {
+		if err := pool.Submit(context.Background(), worker.Task{ID: fmt.Sprint(i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// A double close of Results or the event bus would panic here.
+	var wg sync.WaitGroup
+	for i := range 6 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			switch i % 3 {
+			case 0:
+				pool.Close()
+			case 1:
+				pool.Drain(context.Background())
+			case 2:
+				pool.Shutdown(context.Background())
+			}
+		}()
+	}
+	wg.Wait()
+	// Every caller returned only once the pool was down, so Results is
+	// closed and ranging over it ends.
+	for range pool.Results() {
+	}
+}
+
+func TestFlush(t *testing.T) {
+	release := map[string]chan struct{}{"a": make(chan struct{}), "late": make(chan struct{})}
+	started := make(chan string, 3)
+	pool := worker.NewPool(worker.Config{QueueSize: 4}, func(ctx context.Context, t worker.Task) (string, error) {
+		started <- t.ID
+		if ch, ok := release[t.ID]; ok {
+			<-ch
+		}
+		return "ok", nil
+	})
+	pool.Start(context.Background())
+	defer pool.Close()
+	for _, id := range []string{"a", "b"} {
+		if err := pool.Submit(context.Background(), worker.Task{ID: id}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	<-started // a is running, b queued behind it
+
+	flushed := make(chan error)
+	go func() { flushed <- pool.Flush(context.Background()) }()
+	time.Sleep(10 * time.Millisecond)
+	if err := pool.Submit(context.Background(), worker.Task{ID: "late"}); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case err := <-flushed:
+		t.Fatalf("Flush returned %v with a still running", err)
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	close(release["a"])
+	if err := <-flushed; err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	// late, submitted after Flush began, is only now running or about to.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := pool.Flush(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("second Flush: err = %v, want DeadlineExceeded while late is blocked", err)
+	}
+	close(release["late"])
+	if err := pool.Flush(context.Background()); err != nil {
+		t.Fatalf("third Flush: %v", err)
+	}
+	if s := pool.Stats(); s.Succeeded != 3 {
+		t.Fatalf("Succeeded = %d, want 3", s.Succeeded)
+	}
+}
+
+func TestCloseWaitsOutFinishingTasks(t *testing.T) {
+	pool := worker.NewPool(worker.Config{Workers: 8, QueueSize: 64}, func(ctx context.Context, t worker.Task) (string, error) {
+		time.Sleep(time.Millisecond)
+		return "ok", ctx.Err()
+	})
+	pool.Start(context.Background())
+	got := make(chan int)
+	go func() {
+		n := 0
+		for range pool.Results() {
+			n++
+		}
+		got <- n
+	}()
+	// Half the tasks are cancelled while the workers finish the rest, so
+	// results arrive from both while Close is closing Results.
+	var wg sync.WaitGroup
+	for i := range 64 {
+		cancel, err := pool.SubmitWithCancel(context.Background(), worker.Task{ID: fmt.Sprint(i)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if i%2 == 0 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				cancel()
+			}()
+		}
+	}
+	pool.Close()
+	wg.Wait()
+	if n := <-got; n != 64 {
+		t.Fatalf("got %d results before Results closed, want all 64", n)
+	}
+}
+
+func TestLateResultIsDroppedAndLogged(t *testing.T) {
+	var logs syncBuffer
+	pool := worker.NewPool(worker.Config{Logger: slog.New(slog.NewTextHandler(&logs, nil))},
+		func(ctx context.Context, t worker.Task) (string, error) { return t.Data, nil })
+	pool.Close()
+
+	worker.Deliver(pool, worker.Result{ID: "stray"})
+	if !strings.Contains(logs.String(), "after Results was closed") || !strings.Contains(logs.String(), "task=stray") {
+		t.Fatalf("logs = %q, want the late result logged", logs.String())
+	}
+}
@@ -0,0 +1,152 @@
+package worker
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// snapshot is the JSON form of a pool's waiting work.
+type snapshot[In any] struct {
+	Queued   []snapshotJob[In] `json:",omitempty"`
+	Retrying []snapshotJob[In] `json:",omitempty"`
+}
+
+type snapshotJob[In any] struct {
+	Task TypedTask[In] `json:"-"`
+	// The task as Config.Codec encoded it: inline under JSONCodec, so
+	// the snapshot stays readable, and as base64 under any other.
+	TaskJSON    json.RawMessage `json:"Task,omitempty"`
+	TaskEncoded []byte          `json:",omitempty"`
+
+	Attempts  int       `json:",omitempty"`
+	Started   time.Time `json:",omitzero"`
+	LastErr   string    `json:",omitempty"`
+	SameErrs  int       `json:",omitempty"`
+	Cancelled bool      `json:",omitempty"`
+	Untracked bool      `json:",omitempty"`
+	// RetryIn is how much backoff a retrying job had left at export.
+	RetryIn time.Duration `json:",omitempty"`
+}
+
+func (p *TypedPool[In, Out]) snapshotJob(j job[In]) (snapshotJob[In], error) {
+	sj := snapshotJob[In]{
+		Task:      j.task,
+		Attempts:  j.attempts,
+		Started:   j.started,
+		LastErr:   j.lastErr,
+		SameErrs:  j.sameErrs,
+		Cancelled: p.tracker.cancelled(j.task.ID),
+		Untracked: j.untracked,
+	}
+	data, err := p.cfg.Codec.Encode(j.task)
+	if _, ok := p.cfg.Codec.(JSONCodec[In]); ok {
+		sj.TaskJSON = data
+	} else {
+		sj.TaskEncoded = data
+	}
+	return sj, err
+}
+
+// decodeTask fills in s.Task from whichever form it was exported in. An
+// inline task is JSON whatever the importing pool's codec is.
+func (p *TypedPool[In, Out]) decodeTask(s *snapshotJob[In]) error {
+	var err error
+	if len(s.TaskJSON) > 0 {
+		s.Task, err = JSONCodec[In]{}.Decode(s.TaskJSON)
+	} else {
+		s.Task, err = p.cfg.Codec.Decode(s.TaskEncoded)
+	}
+	return err
+}
+
+// job rebuilds the exported job. Snapshots written before EnqueuedAt
+// existed count their tasks' age from now.
+func (s snapshotJob[In]) job(now time.Time) job[In] {
+	if s.Task.EnqueuedAt.IsZero() {
+		s.Task.EnqueuedAt = now
+	}
+	return job[In]{task: s.Task, attempts: s.Attempts, started: s.Started, lastErr: s.LastErr, sameErrs: s.SameErrs, untracked: s.Untracked}
+}
+
+// Export serializes the tasks waiting in the pool — queued, or backing off
+// before a retry — as JSON, along with their attempt counts and whether
+// they were cancelled. Tasks already running on a worker are not
+// included, so the snapshot is only complete for a pool that is idle or
+// not yet started. Export is meant for tests that need a reproducible
+// starting point; see Import.
+func (p *TypedPool[In, Out]) Export() ([]byte, error) {
+	// Hold off new Submits; one already pushing lands either side of the
+	// snapshot, never half in it.
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.encodeSnapshot(p.queue.snapshot(), p.retries.snapshot())
+}
+
+func (p *TypedPool[In, Out]) encodeSnapshot(queued []job[In], retrying []retryItem[In]) ([]byte, error) {
+	var s snapshot[In]
+	for _, j := range queued {
+		sj, err := p.snapshotJob(j)
+		if err != nil {
+			return nil, fmt.Errorf("worker: export task %s: %w", j.task.ID, err)
+		}
+		s.Queued = append(s.Queued, sj)
+	}
+	now := p.cfg.Clock.Now()
+	for _, item := range retrying {
+		sj, err := p.snapshotJob(item.job)
+		if err != nil {
+			return nil, fmt.Errorf("worker: export task %s: %w", item.job.task.ID, err)
+		}
+		sj.RetryIn = max(item.due.Sub(now), 0)
+		s.Retrying = append(s.Retrying, sj)
+	}
+	return json.Marshal(s)
+}
+
+// Import loads a snapshot produced by Export. It is only valid on a fresh
+// pool — one that has not been started and holds no tasks — and returns
+// ErrNotFresh otherwise. Imported tasks count as submitted: they run once
+// the pool is started and their results arrive on Results. Retrying tasks
+// resume with the backoff they had left.
+func (p *TypedPool[In, Out]) Import(data []byte) error {
+	var s snapshot[In]
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("worker: import: %w", err)
+	}
+	for _, jobs := range [][]snapshotJob[In]{s.Queued, s.Retrying} {
+		for i := range jobs {
+			if err := p.decodeTask(&jobs[i]); err != nil {
+				return fmt.Errorf("worker: import: %w", err)
+			}
+		}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return ErrClosed
+	}
+	if p.ctx != nil || p.tracker.len() > 0 {
+		return ErrNotFresh
+	}
+
+	now := p.cfg.Clock.Now()
+	for _, sj := range s.Queued {
+		p.tracker.restore(sj.Task.ID, sj.Task.Group, sj.Cancelled)
+		if !sj.Untracked {
+			p.waiters.register(sj.Task.ID)
+		}
+		p.pending.Add(1)
+		p.queue.pushRetry(sj.job(now))
+	}
+	for _, sj := range s.Retrying {
+		p.tracker.restore(sj.Task.ID, sj.Task.Group, sj.Cancelled)
+		if !sj.Untracked {
+			p.waiters.register(sj.Task.ID)
+		}
+		p.pending.Add(1)
+		p.retries.push(sj.job(now), now.Add(sj.RetryIn))
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package worker_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/rajatx185/golang-scalable-background-job-system/internal/worker"
+)
+
+func TestExportImportRoundTrip(t *testing.T) {
+	echo := func(ctx context.Context, t worker.Task) (string, error) { return t.Data, nil }
+	ctx := context.Background()
+
+	// Never started, so everything submitted stays queued.
+	src := worker.NewPool(worker.Config{QueueSize: 4}, echo)
+	for _, task := range []worker.Task{
+		{ID: "a", Data: "1"},
+		{ID: "b", Data: "2", Group: "acme"},
+		{ID: "c", Data: "3"},
+	} {
+		if err := src.Submit(ctx, task); err != nil {
+			t.Fatal(err)
+		}
+	}
+	src.CancelGroup("acme")
+	data, err := src.Export()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dst := worker.NewPool(worker.Config{QueueSize: 4}, echo)
+	if err := dst.Import(data); err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	if again, err := dst.Export(); err != nil || !bytes.Equal(again, data) {
+		t.Fatalf("re-export = %s, %v; want %s", again, err, data)
+	}
+
+	dst.Start(ctx)
+	go dst.Close()
+	got := map[string]worker.Result{}
+	for r := range dst.Results() {
+		got[r.ID] = r
+	}
+	if len(got) != 3 || got["a"].Value != "1" || got["c"].Value != "3" {
+		t.Errorf("results = %+v, want a=1 and c=3", got)
+	}
+	if !errors.Is(got["b"].Err, context.Canceled) {
+		t.Errorf("cancelled task: err = %v, want context.Canceled", got["b"].Err)
+	}
+}
+
+func TestImportRejectsUsedPool(t *testing.T) {
+	pool := worker.NewPool(worker.Config{}, func(ctx context.Context, t worker.Task) (string, error) { return "", nil })
+	pool.Start(context.Background())
+	defer pool.Close()
+	if err := pool.Import([]byte(`{}`)); !errors.Is(err, worker.ErrNotFresh) {
+		t.Fatalf("Import on started pool: err = %v, want ErrNotFresh", err)
+	}
+}
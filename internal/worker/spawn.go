@@ -0,0 +1,156 @@
+package worker
+
+import (
+	"context"
+	"sync"
+
+	"github.com/rajatx185/golang-scalable-background-job-system/internal/ids"
+)
+
+// TypedSubmitter enqueues child tasks from inside a running handler; see
+// TypedSubmitterFromContext.
+type TypedSubmitter[In any] interface {
+	Submit(ctx context.Context, t TypedTask[In]) error
+}
+
+// Submitter is the TypedSubmitter for string tasks.
+type Submitter = TypedSubmitter[string]
+
+type submitterKey struct{}
+
+// SubmitterFromContext returns the Submitter of the Pool task ctx belongs
+// to. See TypedSubmitterFromContext.
+func SubmitterFromContext(ctx context.Context) (Submitter, bool) {
+	return TypedSubmitterFromContext[string](ctx)
+}
+
+// TypedSubmitterFromContext returns a submitter that enqueues child tasks
+// of the running task into the same pool, for jobs that fan out as they go
+// — a crawl that discovers new URLs, say. It reports false outside a task
+// run by a pool's workers, including tasks run by Consume.
+//
+// Children skip the queue's capacity, the way retries do, so a handler
+// never deadlocks waiting for room that only its own worker could free.
+// They get a fresh job ID if they have none, inherit the parent's Group if
+// they have none, and have ParentID set to the parent's ID. A child counts
+// as submitted as soon as Submit returns, so Close and Drain wait for it
+// even if they were called while the parent was running. Submit only fails
+// once the pool's work has been handed off with Persist or the pool has
+// closed, or if ctx is already done.
+func TypedSubmitterFromContext[In any](ctx context.Context) (TypedSubmitter[In], bool) {
+	s, ok := ctx.Value(submitterKey{}).(TypedSubmitter[In])
+	return s, ok
+}
+
+type childSubmitter[In, Out any] struct {
+	pool   *TypedPool[In, Out]
+	parent TypedTask[In]
+}
+
+func (s childSubmitter[In, Out]) Submit(ctx context.Context, t TypedTask[In]) error {
+	return s.pool.submitChild(ctx, s.parent, t)
+}
+
+func (p *TypedPool[In, Out]) submitChild(ctx context.Context, parent, t TypedTask[In]) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if t.ID == "" {
+		t.ID = ids.From(p.cfg.IDGenerator, "job")
+	}
+	if t.Group == "" {
+		t.Group = parent.Group
+	}
+	t.ParentID = parent.ID
+	t.EnqueuedAt = p.cfg.Clock.Now()
+	if err := p.Validate(t); err != nil {
+		return err
+	}
+
+	// No mu here: a Submit blocked on a full queue may be waiting with
+	// Close queued behind it, and only this task's worker can make room.
+	// The parent is still running, so pending can't reach zero under the
+	// child; the queue itself refuses it once Close is past that point.
+	if p.handedOff.Load() {
+		return ErrClosed
+	}
+	p.tracker.queueChild(parent.ID, t.ID, t.Group)
+	p.waiters.register(t.ID)
+	p.pending.Add(1)
+	p.subtrees.add(parent.ID, t.ID)
+	p.batches.adopt(parent.ID, t.ID)
+	j := job[In]{task: t, values: captureValues(ctx)}
+	if err := p.queue.pushChild(j, func() { p.publish(EventEnqueued, j, nil) }); err != nil {
+		p.tracker.finish(t.ID)
+		p.waiters.forget(t.ID)
+		p.subtrees.finish(t.ID)
+		p.batches.settle(t.ID, nil)
+		p.pending.Done()
+		return err
+	}
+	return nil
+}
+
+// Subtree is the progress of a task tree: a root task and every task
+// submitted below it.
+type Subtree struct {
+	// Total counts the tasks in the tree so far, the root included.
+	Total int
+	// Done counts the tasks that have a final result.
+	Done int
+}
+
+// subtrees groups tasks into trees by the root they descend from. A tree
+// only exists once its root spawns a child, and is forgotten once every
+// member has finished.
+type subtrees struct {
+	mu    sync.Mutex
+	root  map[string]string // unfinished member ID -> root ID
+	trees map[string]*Subtree
+}
+
+func newSubtrees() *subtrees {
+	return &subtrees{root: make(map[string]string), trees: make(map[string]*Subtree)}
+}
+
+// add records child as spawned by parent, which is still running.
+func (s *subtrees) add(parent, child string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	root, ok := s.root[parent]
+	if !ok {
+		root = parent
+		s.root[parent] = parent
+		s.trees[parent] = &Subtree{Total: 1}
+	}
+	s.root[child] = root
+	s.trees[root].Total++
+}
+
+// finish records that task id has a final result.
+func (s *subtrees) finish(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	root, ok := s.root[id]
+	if !ok {
+		return
+	}
+	delete(s.root, id)
+	tree := s.trees[root]
+	if tree.Done++; tree.Done == tree.Total {
+		delete(s.trees, root)
+	}
+}
+
+func (s *subtrees) snapshot() map[string]Subtree {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out map[string]Subtree
+	for root, tree := range s.trees {
+		if out == nil {
+			out = make(map[string]Subtree)
+		}
+		out[root] = *tree
+	}
+	return out
+}
@@ -0,0 +1,215 @@
+package worker_test
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rajatx185/golang-scalable-background-job-system/internal/worker"
+)
+
+func TestSubmitterSpawnsChildrenPastFullQueue(t *testing.T) {
+	var mu sync.Mutex
+	parents := map[string]string{}
+	// One worker and one slot: a blocking Submit from the handler would
+	// wait forever for its own worker to free the slot.
+	pool := worker.NewPool(worker.Config{Workers: 1, QueueSize: 1}, func(ctx context.Context, task worker.Task) (string, error) {
+		mu.Lock()
+		parents[task.ID] = task.ParentID
+		mu.Unlock()
+		depth, _ := strconv.Atoi(task.Data)
+		if depth == 2 {
+			return "leaf", nil
+		}
+		sub, ok := worker.SubmitterFromContext(ctx)
+		if !ok {
+			t.Error("handler context has no Submitter")
+			return "", nil
+		}
+		for range 2 {
+			if err := sub.Submit(ctx, worker.Task{Data: strconv.Itoa(depth + 1)}); err != nil {
+				t.Errorf("Submit child: %v", err)
+			}
+		}
+		return "branch", nil
+	})
+
+	results := pool.Process(context.Background(), []worker.Task{{ID: "root", Data: "0", Group: "crawl"}})
+	if len(results) != 7 {
+		t.Fatalf("got %d results, want 7 for a binary tree of depth 2", len(results))
+	}
+	for id, parent := range parents {
+		if id == "root" {
+			if parent != "" {
+				t.Fatalf("root has ParentID %q", parent)
+			}
+			continue
+		}
+		if _, ok := parents[parent]; !ok {
+			t.Fatalf("task %s has ParentID %q, which never ran", id, parent)
+		}
+	}
+	if st := pool.Stats().Subtrees; len(st) != 0 {
+		t.Fatalf("Subtrees = %v after every task finished, want none", st)
+	}
+}
+
+func TestCloseWaitsForChildren(t *testing.T) {
+	release := make(chan struct{})
+	pool := worker.NewPool(worker.Config{Workers: 2}, func(ctx context.Context, task worker.Task) (string, error) {
+		if task.ParentID != "" {
+			<-release
+			return "child", nil
+		}
+		sub, _ := worker.SubmitterFromContext(ctx)
+		return "root", sub.Submit(ctx, worker.Task{ID: "child"})
+	})
+	pool.Start(context.Background())
+	if err := pool.Submit(context.Background(), worker.Task{ID: "root"}); err != nil {
+		t.Fatal(err)
+	}
+
+	got := make(chan worker.Result)
+	go func() {
+		for r := range pool.Results() {
+			got <- r
+		}
+		close(got)
+	}()
+	if r := <-got; r.ID != "root" || r.Err != nil {
+		t.Fatalf("first result = %+v, want root", r)
+	}
+	if st := pool.Stats().Subtrees["root"]; st != (worker.Subtree{Total: 2, Done: 1}) {
+		t.Fatalf("Subtrees[root] = %+v, want 1 of 2 done", st)
+	}
+
+	closed := make(chan struct{})
+	go func() {
+		pool.Close()
+		close(closed)
+	}()
+	select {
+	case <-closed:
+		t.Fatal("Close returned while a child was still running")
+	case <-time.After(20 * time.Millisecond):
+	}
+	close(release)
+	if r := <-got; r.ID != "child" || r.Err != nil {
+		t.Fatalf("second result = %+v, want child", r)
+	}
+	<-closed
+	if _, ok := <-got; ok {
+		t.Fatal("Results kept going after Close")
+	}
+}
+
+func TestCancelTreeReachesEveryDescendant(t *testing.T) {
+	ready := make(chan struct{})
+	var ran sync.Map
+	// Two workers: root and mid hold them, so the leaves stay queued.
+	pool := worker.NewPool(worker.Config{Workers: 2}, func(ctx context.Context, task worker.Task) (string, error) {
+		ran.Store(task.ID, true)
+		sub, _ := worker.SubmitterFromContext(ctx)
+		switch task.ID {
+		case "root":
+			if err := sub.Submit(ctx, worker.Task{ID: "mid"}); err != nil {
+				return "", err
+			}
+		case "mid":
+			for _, id := range []string{"leaf-1", "leaf-2"} {
+				if err := sub.Submit(ctx, worker.Task{ID: id}); err != nil {
+					return "", err
+				}
+			}
+			close(ready)
+		default:
+			return "leaf", nil
+		}
+		<-ctx.Done()
+		return "", ctx.Err()
+	})
+	defer pool.Close()
+
+	done := make(chan []worker.Result)
+	go func() { done <- pool.Process(context.Background(), []worker.Task{{ID: "root"}}) }()
+	<-ready
+	if n := pool.CancelTree("root"); n != 4 {
+		t.Fatalf("CancelTree = %d, want 4 for root, mid and two leaves", n)
+	}
+	if n := pool.Stats().Queued; n != 0 {
+		t.Fatalf("Queued = %d after CancelTree, want the leaves taken out", n)
+	}
+
+	results := <-done
+	if len(results) != 4 {
+		t.Fatalf("got %d results, want 4", len(results))
+	}
+	for _, r := range results {
+		if !errors.Is(r.Err, context.Canceled) {
+			t.Errorf("task %s: err = %v, want context.Canceled", r.ID, r.Err)
+		}
+	}
+	for _, id := range []string{"leaf-1", "leaf-2"} {
+		if _, ok := ran.Load(id); ok {
+			t.Errorf("%s ran after its tree was cancelled", id)
+		}
+	}
+}
+
+func TestChildSubmitDuringCloseWithBlockedSubmit(t *testing.T) {
+	running, spawn := make(chan struct{}), make(chan struct{})
+	childErr := make(chan error, 1)
+	pool := worker.NewPool(worker.Config{Workers: 1, QueueSize: 1}, func(ctx context.Context, task worker.Task) (string, error) {
+		if task.ID != "root" {
+			return "", nil
+		}
+		close(running)
+		<-spawn
+		sub, _ := worker.SubmitterFromContext(ctx)
+		childErr <- sub.Submit(ctx, worker.Task{ID: "child"})
+		return "", nil
+	})
+	pool.Start(context.Background())
+	go func() {
+		for range pool.Results() {
+		}
+	}()
+	if err := pool.Submit(context.Background(), worker.Task{ID: "root"}); err != nil {
+		t.Fatal(err)
+	}
+	<-running
+	if err := pool.Submit(context.Background(), worker.Task{ID: "filler"}); err != nil {
+		t.Fatal(err)
+	}
+	// The queue is full, so this blocks until the root's worker is free.
+	blocked := make(chan error, 1)
+	go func() { blocked <- pool.Submit(context.Background(), worker.Task{ID: "blocked"}) }()
+	time.Sleep(10 * time.Millisecond)
+	closed := make(chan struct{})
+	go func() {
+		pool.Close()
+		close(closed)
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	close(spawn)
+	select {
+	case err := <-childErr:
+		if err != nil {
+			t.Fatalf("child Submit = %v, want it queued", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("child Submit deadlocked behind the blocked Submit and Close")
+	}
+	select {
+	case <-closed:
+	case <-time.After(time.Second):
+		t.Fatal("Close never returned")
+	}
+	if err := <-blocked; err != nil {
+		t.Fatalf("blocked Submit = %v, want it accepted before Close", err)
+	}
+}
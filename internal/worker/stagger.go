@@ -0,0 +1,51 @@
+package worker
+
+import "context"
+
+// launchWorkers starts the workers warmed into states, spaced out by
+// Config.StartupStagger, and returns a func that stops the ramp-up. Workers
+// still waiting their turn when it is stopped, or when ctx is done, are
+// launched at once: they find the queue closed or the pool stopping and
+// exit after settling whatever was pinned to them.
+func (p *TypedPool[In, Out]) launchWorkers(ctx, runCtx context.Context, states []WorkerState) (stop func()) {
+	launch := func(id int) {
+		p.workers.Add(1)
+		go p.worker(ctx, runCtx, id, states[id-1])
+	}
+	n := len(states)
+	if p.cfg.StartupStagger <= 0 {
+		for id := 1; id <= n; id++ {
+			launch(id)
+		}
+		return func() {}
+	}
+
+	launch(1)
+	ticker := p.cfg.Clock.NewTicker(p.cfg.StartupStagger)
+	done := make(chan struct{})
+	exited := make(chan struct{})
+	go func() {
+		defer close(exited)
+		defer ticker.Stop()
+		id := 2
+		defer func() {
+			for ; id <= n; id++ {
+				launch(id)
+			}
+		}()
+		for ; id <= n; id++ {
+			select {
+			case <-done:
+				return
+			case <-ctx.Done():
+				return
+			case <-ticker.C():
+			}
+			launch(id)
+		}
+	}()
+	return func() {
+		close(done)
+		<-exited
+	}
+}
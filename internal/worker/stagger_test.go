@@ -0,0 +1,64 @@
+package worker_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/rajatx185/golang-scalable-background-job-system/internal/clock"
+	"github.com/rajatx185/golang-scalable-background-job-system/internal/worker"
+)
+
+// waitForWorkers polls Stats until n workers are online.
+func waitForWorkers(t *testing.T, pool *worker.Pool, n int) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for pool.Stats().Workers != n && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if got := pool.Stats().Workers; got != n {
+		t.Fatalf("Workers = %d, want %d", got, n)
+	}
+}
+
+func TestStartupStagger(t *testing.T) {
+	clk := clock.NewManual(time.Unix(0, 0))
+	pool := worker.NewPool(worker.Config{Workers: 3, StartupStagger: time.Second, Clock: clk},
+		func(ctx context.Context, t worker.Task) (string, error) { return "", nil })
+	pool.Start(context.Background())
+	defer pool.Close()
+
+	waitForWorkers(t, pool, 1)
+	clk.Advance(time.Second)
+	waitForWorkers(t, pool, 2)
+	clk.Advance(time.Second)
+	waitForWorkers(t, pool, 3)
+}
+
+func TestShutdownDuringStagger(t *testing.T) {
+	pool := worker.NewPool(worker.Config{Workers: 4, QueueSize: 8, StartupStagger: time.Hour},
+		func(ctx context.Context, t worker.Task) (string, error) { return "", nil })
+	// Spread the tasks over every worker, including the ones not online yet.
+	for i := range 8 {
+		if err := pool.Submit(context.Background(), worker.Task{ID: fmt.Sprint(i), AffinityKey: fmt.Sprint("key", i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	pool.Start(context.Background())
+	waitForWorkers(t, pool, 1)
+
+	done := make(chan struct{})
+	go func() {
+		pool.Shutdown(context.Background())
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Shutdown hung on workers that were never launched")
+	}
+	if s := pool.Stats(); s.Succeeded+s.Failed != 8 || s.Workers != 0 {
+		t.Fatalf("after Shutdown: %d succeeded, %d failed, %d workers online; want 8 results and 0 workers", s.Succeeded, s.Failed, s.Workers)
+	}
+}
@@ -0,0 +1,156 @@
+package worker
+
+import (
+	"runtime"
+	"time"
+)
+
+// Stats is a point-in-time view of the pool.
+//
+// The queue gauges and task counts are kept in atomics, so calling Stats
+// often — from a monitoring endpoint, say — never takes the queue's lock.
+type Stats struct {
+	// Queued is how many tasks are waiting for a worker; OldestQueued is
+	// OldestQueuedAge.
+	Queued       int
+	OldestQueued time.Duration
+	// Retrying is how many failed tasks are backing off before another
+	// attempt.
+	Retrying int
+	// RetryBudgetUtilization is the share of Config.RetryBudget in use,
+	// from 0 to 1 once it is exhausted and retries are being held back.
+	RetryBudgetUtilization float64
+	// Workers is how many workers are online, which climbs towards
+	// Config.Workers while Config.StartupStagger ramps them up.
+	// ActiveWorkers is how many of them are running a task right now.
+	Workers       int
+	ActiveWorkers int
+	// Goroutines counts the goroutines the pool is running tasks on,
+	// abandoned handlers included; see Config.MaxGoroutines.
+	// ProcessGoroutines is runtime.NumGoroutine, for comparison.
+	Goroutines        int
+	ProcessGoroutines int
+	// CacheHits and CacheMisses count IdempotencyKey lookups in the
+	// result cache, and CacheHitRate is the share that hit; see
+	// Config.ResultCacheTTL.
+	CacheHits    int64
+	CacheMisses  int64
+	CacheHitRate float64
+	// DeadLetters is how many dead letters are retained for
+	// ReplayDeadLetters.
+	DeadLetters int
+	// RetainedResults is how many finished results Wait can still return;
+	// see Config.ResultRetention.
+	RetainedResults int
+	// Succeeded and Failed count the tasks that have produced a final
+	// Result.
+	Succeeded int64
+	Failed    int64
+	// FirstAttemptSuccess and EventualSuccess split Succeeded into tasks
+	// that succeeded on their first attempt and those that needed at
+	// least one retry, which shows how much flakiness retries are hiding.
+	FirstAttemptSuccess int64
+	EventualSuccess     int64
+	// ResultSendBlockedTotal is how long workers have spent, in total,
+	// blocked handing results to a Results channel nobody was reading
+	// fast enough. Steady growth means the collector is the bottleneck;
+	// see Config.ResultBlockWarning and Collect.
+	ResultSendBlockedTotal time.Duration
+	// Throughput is the recent rate, in tasks per second, at which tasks
+	// reach a final Result, or zero before it can be estimated; see
+	// DrainEstimate.
+	Throughput float64
+	// StuckWorkers lists busy workers whose heartbeat went stale, as of
+	// the monitor's last check.
+	StuckWorkers []StuckWorker
+	// Dropped counts tasks discarded by the DropNewest and DropOldest
+	// overflow policies.
+	Dropped int64
+	// Spilled counts tasks handed to Config.OverflowPool because the
+	// queue was full.
+	Spilled int64
+	// SlowTasks counts attempts that ran past Config.SlowTaskThreshold
+	// without timing out.
+	SlowTasks int64
+	// ExpiredBeforeStart counts tasks failed with ErrExpiredBeforeStart
+	// because their deadline had passed by the time a worker took them.
+	ExpiredBeforeStart int64
+	// Abandoned counts handler goroutines given up on at their hard
+	// timeout that have not returned yet.
+	Abandoned int64
+	// Handlers breaks the handler's attempts down by Task.Type; tasks
+	// without one are counted under "".
+	Handlers map[string]HandlerStats
+	// Resources reports the utilization of each Config.ResourceLimits
+	// entry.
+	Resources map[string]ResourceUsage
+	// InFlightWeight is the total Task.Weight of the attempts running
+	// right now; see Config.MaxWeight.
+	InFlightWeight int64
+	// Throttled maps each ResourceKey currently cooling down to when its
+	// tasks are released again; see Config.ThrottleAfter.
+	Throttled map[string]time.Time
+	// PinnedDepth counts the queued tasks held for each worker by their
+	// AffinityKey, indexed by worker ID - 1. Tasks without a key can run
+	// anywhere and are not counted.
+	PinnedDepth []int
+	// WorkerRestarts counts workers replaced after a panic under
+	// PanicRestart.
+	WorkerRestarts int64
+	// Subtrees reports, by root task ID, the progress of every task tree
+	// with unfinished members. A tree forms when a running task submits
+	// children through its Submitter.
+	Subtrees map[string]Subtree
+	// EventsDropped counts lifecycle events discarded because subscribers
+	// fell behind; see Pool.Subscribe.
+	EventsDropped int64
+	// QueueLatency summarizes how long the latest tasks waited in the
+	// queue before a worker took them (see Result.QueueLatency), and
+	// ProcessTime how long the latest attempts spent in the handler. A
+	// long wait with quick processing calls for more workers; quick waits
+	// with slow processing point at the handlers.
+	QueueLatency LatencySummary
+	ProcessTime  LatencySummary
+}
+
+// Stats returns a snapshot of the pool's health.
+func (p *TypedPool[In, Out]) Stats() Stats {
+	throughput, _ := p.throughput.rate(p.cfg.Clock.Now())
+	return Stats{
+		Queued:                 int(p.queue.size.Load()),
+		OldestQueued:           p.OldestQueuedAge(),
+		Retrying:               int(p.retries.size.Load()),
+		RetryBudgetUtilization: p.retryBudget.utilization(),
+		Workers:                int(p.online.Load()),
+		ActiveWorkers:          int(p.active.Load()),
+		Goroutines:             p.goroutines.count(),
+		ProcessGoroutines:      runtime.NumGoroutine(),
+		CacheHits:              p.cache.hits.Load(),
+		CacheMisses:            p.cache.misses.Load(),
+		CacheHitRate:           p.cache.hitRate(),
+		DeadLetters:            p.deadLetters.len(),
+		RetainedResults:        p.waiters.retained(),
+		Succeeded:              p.succeeded.Load(),
+		Failed:                 p.failed.Load(),
+		FirstAttemptSuccess:    p.firstAttemptSuccess.Load(),
+		EventualSuccess:        p.eventualSuccess.Load(),
+		ResultSendBlockedTotal: time.Duration(p.resultsBlocked.Load()),
+		Throughput:             throughput,
+		StuckWorkers:           p.heartbeats.stuckWorkers(),
+		Dropped:                p.queue.dropped.Load(),
+		Spilled:                p.spilled.Load(),
+		SlowTasks:              p.slowTasks.Load(),
+		ExpiredBeforeStart:     p.expiredBeforeStart.Load(),
+		Handlers:               p.handlers.snapshot(),
+		Abandoned:              p.abandoned.Load(),
+		Resources:              p.resources.usage(),
+		InFlightWeight:         p.weights.usage().InUse,
+		EventsDropped:          p.events.dropped.Load(),
+		PinnedDepth:            p.queue.pinnedDepths(),
+		WorkerRestarts:         p.restarts.Load(),
+		Throttled:              p.throttle.throttled(),
+		Subtrees:               p.subtrees.snapshot(),
+		QueueLatency:           p.queueLatency.summary(),
+		ProcessTime:            p.processTime.summary(),
+	}
+}
@@ -0,0 +1,71 @@
+package worker
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+)
+
+// Summary is a post-run health check computed from a batch of Results.
+type Summary struct {
+	Total     int
+	Succeeded int
+	Failed    int
+	// Retried counts tasks that needed more than one attempt, whether or
+	// not they eventually succeeded.
+	Retried int
+	// WallTime is the elapsed time of the whole run.
+	WallTime time.Duration
+	// AvgDuration and P95Duration are taken over Result.Duration of the
+	// tasks that started; dropped ones have no duration to count.
+	AvgDuration time.Duration
+	P95Duration time.Duration
+}
+
+// Run processes tasks like Process and also returns a Summary of the run.
+// Like Process, it can be called again for the next batch.
+func (p *TypedPool[In, Out]) Run(ctx context.Context, tasks []TypedTask[In]) (Summary, []TypedResult[Out]) {
+	start := time.Now()
+	results := p.Process(ctx, tasks)
+	return summarize(results, time.Since(start)), results
+}
+
+func summarize[Out any](results []TypedResult[Out], wall time.Duration) Summary {
+	s := Summary{Total: len(results), WallTime: wall}
+	if len(results) == 0 {
+		return s
+	}
+
+	durations := make([]time.Duration, 0, len(results))
+	var total time.Duration
+	for _, r := range results {
+		if r.Err == nil {
+			s.Succeeded++
+		} else {
+			s.Failed++
+		}
+		if r.Attempt > 1 {
+			s.Retried++
+		}
+		if r.StartedAt.IsZero() {
+			continue
+		}
+		durations = append(durations, r.Duration)
+		total += r.Duration
+	}
+	if len(durations) == 0 {
+		return s
+	}
+
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+	s.AvgDuration = total / time.Duration(len(durations))
+	s.P95Duration = percentile(durations, 0.95)
+	return s
+}
+
+// percentile returns the nearest-rank q-th percentile of sorted.
+func percentile(sorted []time.Duration, q float64) time.Duration {
+	rank := int(math.Ceil(q*float64(len(sorted)))) - 1
+	return sorted[max(rank, 0)]
+}
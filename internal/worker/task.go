@@ -0,0 +1,121 @@
+package worker
+
+import (
+	"log/slog"
+	"maps"
+	"slices"
+	"time"
+)
+
+// TypedTask is one unit of work submitted to a TypedPool, carrying input
+// of type In.
+type TypedTask[In any] struct {
+	ID string
+	// Type names the kind of work the task is, for a handler that does
+	// more than one kind; the pool only uses it to break down
+	// Stats.Handlers.
+	Type string
+	// Kind says whether the task is CPU-bound or I/O-bound, which a
+	// TypedKindPool routes it by. A plain TypedPool ignores it.
+	Kind TaskKind
+	Data In
+	// Timeout bounds a single run of this task. It takes precedence over
+	// Config.DefaultTimeout; leave it zero to inherit the pool default.
+	Timeout time.Duration
+	// Group tags the task with a tenant or customer so that all of its
+	// work can be cancelled together; see Pool.CancelGroup.
+	Group string
+	// AffinityKey pins the task to one worker: every task with the same key
+	// runs on the same worker, so per-key state that worker caches stays
+	// warm. This trades load balancing for locality — a busy key queues
+	// behind its own worker even while others sit idle. Tasks without a
+	// key go to whichever worker is free first.
+	AffinityKey string
+	// ResourceKey names a downstream resource whose concurrency is capped
+	// by Config.ResourceLimits. Tasks sharing a key are throttled however
+	// many workers are free; an empty or unlisted key is unlimited.
+	ResourceKey string
+	// ResourceWeight is how much of the ResourceKey limit one attempt
+	// holds. Defaults to 1.
+	ResourceWeight int64
+	// Weight is how much of Config.MaxWeight one attempt holds, so a
+	// weight-10 task takes the room of ten weight-1 tasks. Defaults to 1.
+	Weight int
+	// ParentID is the ID of the task that submitted this one through its
+	// Submitter. The pool sets it; see TypedSubmitterFromContext.
+	ParentID string
+	// IdempotencyKey marks tasks that produce the same result, so a
+	// recent success can be reused instead of running again; see
+	// Config.ResultCacheTTL.
+	IdempotencyKey string
+	// Metadata carries free-form tags, such as a tenant, source or
+	// correlation ID. The pool doesn't interpret them: they are copied
+	// onto the Result, show up in the pool's log lines about the task and
+	// in its events, and survive every Codec.
+	Metadata map[string]string
+	// EnqueuedAt is when the task was submitted, by Config.Clock. Submit
+	// sets it, and it survives Export and Persist, so a task's age keeps
+	// counting across a handoff.
+	EnqueuedAt time.Time
+}
+
+// Task is the string-payload TypedTask used by Pool.
+type Task = TypedTask[string]
+
+// TypedResult is the outcome of running a TypedTask, carrying output of type
+// Out.
+type TypedResult[Out any] struct {
+	ID    string
+	Value Out
+	Err   error
+	// Truncated reports that Value was cut to Config.MaxResultSize. Only
+	// string values are ever truncated.
+	Truncated bool
+	// Cached reports that Value was reused from an earlier task with the
+	// same IdempotencyKey instead of being computed; see
+	// Config.ResultCacheTTL.
+	Cached bool
+	// Metadata is the task's Task.Metadata.
+	Metadata map[string]string
+	// Attempt is how many times the task ran, counting the first try.
+	Attempt int
+	// Attempts is the task's attempt history, oldest first, capped to the
+	// latest Config.AttemptHistory. It starts over for a task handed over
+	// with Export or Persist.
+	Attempts []AttemptInfo
+	// StartedAt is when the first attempt began; Duration spans every
+	// attempt up to the final one. Both are zero for a task no worker
+	// took, such as one dropped by Config.Overflow.
+	StartedAt time.Time
+	Duration  time.Duration
+	// QueueLatency is how long the task waited in the queue, from its
+	// EnqueuedAt until a worker first took it, by Config.Clock. Retry
+	// backoff doesn't count. It is zero for results not produced by the
+	// pool's workers.
+	QueueLatency time.Duration
+}
+
+// Result is the string-valued TypedResult produced by Pool.
+type Result = TypedResult[string]
+
+// AttemptInfo describes one run of a task, for seeing what went wrong
+// before a retry went through.
+type AttemptInfo struct {
+	// Attempt numbers the run, starting at 1.
+	Attempt  int
+	WorkerID int
+	// Err is what the run failed with, or nil if it succeeded.
+	Err       error
+	StartedAt time.Time
+	Duration  time.Duration
+}
+
+// metadataAttr groups t's Metadata for the pool's log lines about t. A
+// task without metadata adds nothing.
+func metadataAttr(m map[string]string) slog.Attr {
+	args := make([]any, 0, 2*len(m))
+	for _, k := range slices.Sorted(maps.Keys(m)) {
+		args = append(args, k, m[k])
+	}
+	return slog.Group("metadata", args...)
+}
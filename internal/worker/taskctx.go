@@ -0,0 +1,78 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+)
+
+type (
+	jobIDKey    struct{}
+	attemptKey  struct{}
+	metadataKey struct{}
+	loggerKey   struct{}
+)
+
+// withTask returns ctx carrying t's ID and Metadata, the number of the
+// attempt about to run and the ID of the worker running it, for the
+// handler to read back with JobIDFromContext, AttemptFromContext,
+// MetadataFromContext and WorkerIDFromContext, or all at once as the
+// attributes of LoggerFromContext.
+func (p *TypedPool[In, Out]) withTask(ctx context.Context, t TypedTask[In], attempt, workerID int) context.Context {
+	ctx = context.WithValue(ctx, jobIDKey{}, t.ID)
+	ctx = context.WithValue(ctx, attemptKey{}, attempt)
+	ctx = context.WithValue(ctx, workerIDKey{}, workerID)
+	ctx = context.WithValue(ctx, loggerKey{}, &taskLogger{base: p.taskLogger, task: t.ID, attempt: attempt, worker: workerID})
+	return context.WithValue(ctx, metadataKey{}, t.Metadata)
+}
+
+// taskLogger attaches an attempt's fields to the pool's logger the first
+// time a handler asks for it, so attempts that never log don't pay for it.
+type taskLogger struct {
+	once            sync.Once
+	base            *slog.Logger
+	task            string
+	attempt, worker int
+	l               *slog.Logger
+}
+
+func (t *taskLogger) get() *slog.Logger {
+	t.once.Do(func() { t.l = t.base.With("task", t.task, "attempt", t.attempt, "worker", t.worker) })
+	return t.l
+}
+
+// LoggerFromContext returns Config.Logger with the task, attempt and
+// worker of the attempt ctx belongs to already attached, so a handler's
+// log lines can be told apart without threading the IDs through every
+// call. If the pool has no Config.Logger, or ctx isn't pool-managed, it
+// returns a logger that discards everything.
+func LoggerFromContext(ctx context.Context) *slog.Logger {
+	if l, ok := ctx.Value(loggerKey{}).(*taskLogger); ok {
+		return l.get()
+	}
+	return slog.New(slog.DiscardHandler)
+}
+
+// JobIDFromContext returns the ID of the task ctx belongs to, so code that
+// only sees the context, such as a logging helper, can tag its output. It
+// reports false outside a pool-managed context.
+func JobIDFromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(jobIDKey{}).(string)
+	return id, ok
+}
+
+// AttemptFromContext returns which attempt at the task is running, starting
+// from 1. Under Consume it is the backend's delivery count. It reports
+// false outside a pool-managed context.
+func AttemptFromContext(ctx context.Context) (int, bool) {
+	n, ok := ctx.Value(attemptKey{}).(int)
+	return n, ok
+}
+
+// MetadataFromContext returns the Metadata of the task ctx belongs to. The
+// map is the task's own and must not be modified. It reports false
+// outside a pool-managed context.
+func MetadataFromContext(ctx context.Context) (map[string]string, bool) {
+	m, ok := ctx.Value(metadataKey{}).(map[string]string)
+	return m, ok
+}
@@ -0,0 +1,72 @@
+package worker_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/rajatx185/golang-scalable-background-job-system/internal/worker"
+)
+
+func TestTaskValuesInContext(t *testing.T) {
+	var mu sync.Mutex
+	var seen []string
+	pool := worker.NewPool(worker.Config{MaxRetries: 1, IsRetryable: retryAll}, func(ctx context.Context, t worker.Task) (string, error) {
+		id, _ := worker.JobIDFromContext(ctx)
+		attempt, _ := worker.AttemptFromContext(ctx)
+		md, _ := worker.MetadataFromContext(ctx)
+		mu.Lock()
+		seen = append(seen, fmt.Sprintf("%s#%d trace=%s", id, attempt, md["trace"]))
+		mu.Unlock()
+		if attempt == 1 {
+			return "", errors.New("try again")
+		}
+		return "ok", nil
+	})
+	defer pool.Close()
+
+	pool.Process(context.Background(), []worker.Task{{ID: "a", Metadata: map[string]string{"trace": "t1"}}})
+	if want := []string{"a#1 trace=t1", "a#2 trace=t1"}; !slices.Equal(seen, want) {
+		t.Fatalf("handler saw %q, want %q", seen, want)
+	}
+	if _, ok := worker.JobIDFromContext(context.Background()); ok {
+		t.Fatal("JobIDFromContext ok outside a task")
+	}
+}
+
+func TestLoggerFromContextCarriesTaskFields(t *testing.T) {
+	var logs syncBuffer
+	pool := worker.NewPool(worker.Config{Logger: slog.New(slog.NewTextHandler(&logs, nil))},
+		func(ctx context.Context, t worker.Task) (string, error) {
+			worker.LoggerFromContext(ctx).Info("charging card")
+			return "ok", nil
+		})
+	defer pool.Close()
+
+	pool.Process(context.Background(), []worker.Task{{ID: "a"}})
+	if line := logs.String(); !strings.Contains(line, "msg=\"charging card\" task=a attempt=1 worker=1") {
+		t.Fatalf("logs = %q, want the handler's line tagged with its task, attempt and worker", line)
+	}
+	if worker.LoggerFromContext(context.Background()).Enabled(context.Background(), slog.LevelError) {
+		t.Fatal("LoggerFromContext outside a task logs somewhere, want it to discard")
+	}
+}
+
+func TestLoggerFromContextDiscardsWithoutALogger(t *testing.T) {
+	pool := worker.NewPool(worker.Config{}, func(ctx context.Context, t worker.Task) (string, error) {
+		if worker.LoggerFromContext(ctx).Enabled(ctx, slog.LevelError) {
+			return "", errors.New("logger enabled")
+		}
+		return "ok", nil
+	})
+	defer pool.Close()
+
+	if res := pool.Process(context.Background(), []worker.Task{{ID: "a"}}); res[0].Err != nil {
+		t.Fatalf("LoggerFromContext in a pool with no Config.Logger: %v, want it to discard", res[0].Err)
+	}
+}
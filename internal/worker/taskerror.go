@@ -0,0 +1,50 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"slices"
+)
+
+// TaskError is the Err of every failed Result. It reads as the error the
+// task failed with, which it wraps, alongside the classes that describe
+// how it failed, so errors.Is matches both: a task that kept timing out
+// matches context.DeadlineExceeded, ErrTaskTimeout and
+// ErrMaxRetriesExceeded alike. The classes are
+//   - ErrTaskTimeout: an attempt hit its timeout or hard timeout, or the
+//     task expired in the queue under DispatchEarliestDeadline;
+//   - ErrTaskCancelled: the task was cancelled, by CancelGroup, CancelTree or
+//     WithPropagatedCancel, or the pool shut down under it;
+//   - ErrMaxRetriesExceeded: it failed with a retryable error and had no
+//     retries left;
+//   - ErrPanic: the handler panicked.
+//
+// Use errors.As to get at the task's ID and attempt count.
+type TaskError struct {
+	TaskID  string
+	Attempt int // the attempts made, zero if the task never ran
+	Err     error
+	classes []error
+}
+
+func (e *TaskError) Error() string { return e.Err.Error() }
+
+func (e *TaskError) Unwrap() []error { return append(slices.Clip(e.classes), e.Err) }
+
+// taskError wraps the final err of j as a *TaskError.
+func taskError[In any](j job[In], err error) *TaskError {
+	if te, ok := err.(*TaskError); ok {
+		return te
+	}
+	e := &TaskError{TaskID: j.task.ID, Attempt: j.attempts, Err: err}
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, ErrHardTimeout) || errors.Is(err, ErrExpiredBeforeStart) {
+		e.classes = append(e.classes, ErrTaskTimeout)
+	}
+	if errors.Is(err, context.Canceled) {
+		e.classes = append(e.classes, ErrTaskCancelled)
+	}
+	if j.exhausted {
+		e.classes = append(e.classes, ErrMaxRetriesExceeded)
+	}
+	return e
+}
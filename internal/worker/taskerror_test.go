@@ -0,0 +1,78 @@
+package worker_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rajatx185/golang-scalable-background-job-system/internal/worker"
+)
+
+func TestResultErrClassifiesFailure(t *testing.T) {
+	boom := errors.New("boom")
+	pool := worker.NewPool(worker.Config{Workers: 2, MaxRetries: 1, RetryBackoff: time.Millisecond},
+		func(ctx context.Context, t worker.Task) (string, error) {
+			switch t.ID {
+			case "slow":
+				<-ctx.Done()
+				return "", ctx.Err()
+			case "panics":
+				panic("oops")
+			}
+			return "", boom
+		})
+	defer pool.Close()
+
+	results := pool.Process(context.Background(), []worker.Task{
+		{ID: "slow", Timeout: 5 * time.Millisecond},
+		{ID: "fatal"},
+		{ID: "panics"},
+	})
+	want := map[string][]error{
+		"slow":   {context.DeadlineExceeded, worker.ErrTaskTimeout, worker.ErrMaxRetriesExceeded},
+		"fatal":  {boom},
+		"panics": {worker.ErrPanic},
+	}
+	for _, r := range results {
+		for _, target := range want[r.ID] {
+			if !errors.Is(r.Err, target) {
+				t.Errorf("%s: err = %v, want it to match %v", r.ID, r.Err, target)
+			}
+		}
+		var te *worker.TaskError
+		if !errors.As(r.Err, &te) || te.TaskID != r.ID {
+			t.Fatalf("%s: err = %#v, want a *TaskError for the task", r.ID, r.Err)
+		}
+		if r.ID == "fatal" && (errors.Is(r.Err, worker.ErrMaxRetriesExceeded) || r.Err.Error() != "boom") {
+			t.Errorf("fatal: err = %q, want boom unclassified, as a non-retryable error", r.Err)
+		}
+		if r.ID == "slow" && te.Attempt != 2 {
+			t.Errorf("slow: Attempt = %d, want 2 with one retry", te.Attempt)
+		}
+	}
+}
+
+func TestCancelledResultErr(t *testing.T) {
+	started := make(chan struct{})
+	pool := worker.NewPool(worker.Config{}, func(ctx context.Context, t worker.Task) (string, error) {
+		close(started)
+		<-ctx.Done()
+		return "", ctx.Err()
+	})
+	pool.Start(context.Background())
+	defer pool.Close()
+	go func() {
+		for range pool.Results() {
+		}
+	}()
+	if err := pool.Submit(context.Background(), worker.Task{ID: "a"}); err != nil {
+		t.Fatal(err)
+	}
+	<-started
+	pool.CancelTree("a")
+	r, err := pool.Wait(context.Background(), "a")
+	if err != nil || !errors.Is(r.Err, worker.ErrTaskCancelled) || errors.Is(r.Err, worker.ErrTaskTimeout) {
+		t.Fatalf("Wait = %v, %v, want ErrTaskCancelled alone", r.Err, err)
+	}
+}
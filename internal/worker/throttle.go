@@ -0,0 +1,105 @@
+package worker
+
+import (
+	"sync"
+	"time"
+
+	"github.com/rajatx185/golang-scalable-background-job-system/internal/clock"
+)
+
+// throttle is a per-resource circuit breaker. Once a ResourceKey has
+// failed Config.ThrottleAfter attempts in a row, tasks for it are held back
+// for Config.ThrottleCooldown instead of all piling onto a downstream that
+// is plainly down. After the cooldown the breaker is half-open: the next
+// failure trips it again straight away, the next success clears it.
+//
+// The cooldown lasts at least as long as a typical task takes, going by
+// the pool's EMALatency, so the attempts already running against the key
+// when it trips have finished before the half-open probe; against a slow
+// downstream a shorter one would only see their stale failures re-trip it.
+// The average only covers time spent in the handler, never time parked
+// here or backing off, so one cooldown can't lengthen the next.
+type throttle struct {
+	after    int
+	cooldown time.Duration
+	clock    clock.Clock
+	latency  *latencyEMA
+
+	mu   sync.Mutex
+	keys map[string]*breaker
+}
+
+type breaker struct {
+	fails int
+	until time.Time
+}
+
+func newThrottle(after int, cooldown time.Duration, clk clock.Clock, latency *latencyEMA) *throttle {
+	return &throttle{after: after, cooldown: cooldown, clock: clk, latency: latency, keys: make(map[string]*breaker)}
+}
+
+// currentCooldown is how long a breaker tripping now stays open.
+func (t *throttle) currentCooldown() time.Duration {
+	avg, _ := t.latency.value()
+	return max(t.cooldown, avg)
+}
+
+func (t *throttle) enabled(key string) bool {
+	return key != "" && t.after > 0 && t.cooldown > 0
+}
+
+// blockedUntil reports when key's cooldown ends, if it is in one.
+func (t *throttle) blockedUntil(key string) (time.Time, bool) {
+	if !t.enabled(key) {
+		return time.Time{}, false
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	b, ok := t.keys[key]
+	if !ok || !t.clock.Now().Before(b.until) {
+		return time.Time{}, false
+	}
+	return b.until, true
+}
+
+// record notes the outcome of an attempt against key and reports whether
+// it tripped the breaker.
+func (t *throttle) record(key string, failed bool) bool {
+	if !t.enabled(key) {
+		return false
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !failed {
+		delete(t.keys, key)
+		return false
+	}
+	b, ok := t.keys[key]
+	if !ok {
+		b = &breaker{}
+		t.keys[key] = b
+	}
+	if b.fails++; b.fails < t.after {
+		return false
+	}
+	b.until = t.clock.Now().Add(t.currentCooldown())
+	b.fails = t.after - 1 // half-open once the cooldown ends
+	return true
+}
+
+// throttled lists the keys currently cooling down and when each ends.
+func (t *throttle) throttled() map[string]time.Time {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := t.clock.Now()
+	var out map[string]time.Time
+	for key, b := range t.keys {
+		if now.Before(b.until) {
+			if out == nil {
+				out = make(map[string]time.Time)
+			}
+			out[key] = b.until
+		}
+	}
+	return out
+}
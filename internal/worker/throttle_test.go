@@ -0,0 +1,105 @@
+package worker_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rajatx185/golang-scalable-background-job-system/internal/clock"
+	"github.com/rajatx185/golang-scalable-background-job-system/internal/worker"
+)
+
+func TestThrottleParksResourceDuringCooldown(t *testing.T) {
+	clk := clock.NewManual(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	var down atomic.Bool
+	down.Store(true)
+	var calls atomic.Int32
+	pool := worker.NewPool(worker.Config{
+		QueueSize:        4,
+		IsRetryable:      retryAll,
+		ThrottleAfter:    2,
+		ThrottleCooldown: time.Hour,
+		Clock:            clk,
+		Logger:           slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}, func(ctx context.Context, t worker.Task) (string, error) {
+		calls.Add(1)
+		if down.Load() {
+			return "", errors.New("connection refused")
+		}
+		return "ok", nil
+	})
+	parked := make(chan string, 4)
+	pool.Subscribe(func(_ context.Context, e worker.Event) {
+		if e.Kind == worker.EventRetried && errors.Is(e.Err, worker.ErrThrottled) {
+			parked <- e.Task.ID
+		}
+	})
+
+	ctx := context.Background()
+	pool.Start(ctx)
+	for _, id := range []string{"a", "b", "c", "d"} {
+		if err := pool.Submit(ctx, worker.Task{ID: id, ResourceKey: "db"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// a and b fail and trip the breaker; c and d are parked unrun.
+	<-parked
+	<-parked
+	if n := calls.Load(); n != 2 {
+		t.Fatalf("handler ran %d times before throttling, want 2", n)
+	}
+	if until, ok := pool.Stats().Throttled["db"]; !ok || !until.Equal(clk.Now().Add(time.Hour)) {
+		t.Fatalf("Throttled = %v, want db until the cooldown ends", pool.Stats().Throttled)
+	}
+
+	down.Store(false)
+	clk.BlockUntil(1)
+	clk.Advance(time.Hour)
+	go pool.Close()
+	got := map[string]worker.Result{}
+	for r := range pool.Results() {
+		got[r.ID] = r
+	}
+	for _, id := range []string{"c", "d"} {
+		if r := got[id]; r.Err != nil || r.Attempt != 1 {
+			t.Errorf("parked task %s: err = %v after %d attempts, want success on attempt 1", id, r.Err, r.Attempt)
+		}
+	}
+	if len(pool.Stats().Throttled) != 0 {
+		t.Errorf("still throttled after recovery: %v", pool.Stats().Throttled)
+	}
+}
+
+func TestThrottleCooldownDoesNotFeedOnItself(t *testing.T) {
+	const cooldown = 100 * time.Millisecond
+	pool := worker.NewPool(worker.Config{
+		Workers:          1,
+		IsRetryable:      retryAll,
+		MaxRetries:       2,
+		RetryBackoff:     time.Millisecond,
+		ThrottleAfter:    1,
+		ThrottleCooldown: cooldown,
+		LatencyEMAAlpha:  1,
+		Logger:           slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}, func(ctx context.Context, t worker.Task) (string, error) {
+		return "", errors.New("connection refused")
+	})
+	defer pool.Close()
+
+	// Every attempt trips the breaker, so each task spends most of its
+	// time parked. That wait must not stretch the next task's cooldowns.
+	for _, id := range []string{"a", "b", "c"} {
+		pool.Process(context.Background(), []worker.Task{{ID: id, ResourceKey: "db"}})
+		until, ok := pool.Stats().Throttled["db"]
+		if !ok {
+			t.Fatalf("after %s: db not throttled", id)
+		}
+		if left := time.Until(until); left > cooldown {
+			t.Fatalf("after %s: cooldown has %v left, want at most the configured %v", id, left, cooldown)
+		}
+	}
+}
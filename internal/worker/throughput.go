@@ -0,0 +1,137 @@
+package worker
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// throughputSamples is how many of the latest completions the throughput
+// estimate is taken over.
+const throughputSamples = 64
+
+// throughput keeps the times of the latest completions in a ring, to
+// estimate how fast the pool is getting through tasks.
+type throughput struct {
+	mu    sync.Mutex
+	times [throughputSamples]time.Time
+	next  int // slot the next completion goes in
+	n     int
+}
+
+func (tp *throughput) record(now time.Time) {
+	tp.mu.Lock()
+	defer tp.mu.Unlock()
+	tp.times[tp.next] = now
+	tp.next = (tp.next + 1) % len(tp.times)
+	tp.n = min(tp.n+1, len(tp.times))
+}
+
+// rate returns completions per second over the span from the oldest
+// sample to now, so it decays while nothing completes. It reports false
+// until there are two samples to measure between.
+func (tp *throughput) rate(now time.Time) (float64, bool) {
+	tp.mu.Lock()
+	defer tp.mu.Unlock()
+	if tp.n < 2 {
+		return 0, false
+	}
+	oldest := tp.times[(tp.next-tp.n+len(tp.times))%len(tp.times)]
+	span := now.Sub(oldest)
+	if span <= 0 {
+		return 0, false
+	}
+	return float64(tp.n) / span.Seconds(), true
+}
+
+// DrainEstimate estimates how long the workers need to get through the
+// tasks queued now, from the rate tasks have been reaching a final result
+// lately. Until two tasks have finished it falls back on EMALatency,
+// spreading the queue over the workers online. It reports false while
+// there is too little history for either, which callers should answer
+// with a fixed fallback.
+func (p *TypedPool[In, Out]) DrainEstimate() (time.Duration, bool) {
+	queued := float64(p.queue.size.Load())
+	if rate, ok := p.throughput.rate(p.cfg.Clock.Now()); ok {
+		return time.Duration(queued / rate * float64(time.Second)), true
+	}
+	avg, ok := p.latency.value()
+	if !ok {
+		return 0, false
+	}
+	workers := max(p.online.Load(), 1)
+	return time.Duration(queued * float64(avg) / float64(workers)), true
+}
+
+// rateBuckets is how many one-second buckets rateWindow keeps, which caps
+// the window Throughput and ErrorRate can look back over at an hour.
+const rateBuckets = 3600
+
+// rateWindow counts final results in a ring of per-second buckets. Counts
+// and the current index are atomics, so recording a result never takes a
+// lock; a ticker moves on to the next bucket every second.
+type rateWindow struct {
+	buckets [rateBuckets]rateBucket
+	cur     atomic.Int64 // ever-increasing; the bucket is cur % rateBuckets
+}
+
+type rateBucket struct {
+	done, failed atomic.Int64
+}
+
+func (w *rateWindow) record(failed bool) {
+	b := &w.buckets[w.cur.Load()%rateBuckets]
+	b.done.Add(1)
+	if failed {
+		b.failed.Add(1)
+	}
+}
+
+// rotate clears the next bucket and makes it current. A result recorded
+// concurrently can still land in the bucket just left, which only shifts
+// it by a second.
+func (w *rateWindow) rotate() {
+	next := w.cur.Load() + 1
+	b := &w.buckets[next%rateBuckets]
+	b.done.Store(0)
+	b.failed.Store(0)
+	w.cur.Store(next)
+}
+
+// sum totals the current bucket and the ones before it that make up
+// window, rounded up to whole seconds.
+func (w *rateWindow) sum(window time.Duration) (done, failed int64, seconds int64) {
+	seconds = min(max(int64((window+time.Second-1)/time.Second), 1), rateBuckets)
+	cur := w.cur.Load()
+	for i := range seconds {
+		b := &w.buckets[(cur-i+rateBuckets)%rateBuckets]
+		done += b.done.Load()
+		failed += b.failed.Load()
+	}
+	return done, failed, seconds
+}
+
+// startRateWindow rotates the rate buckets every second on Clock and
+// returns a func that stops it.
+func (p *TypedPool[In, Out]) startRateWindow() (stop func()) {
+	return p.startSweep(time.Second, p.rates.rotate)
+}
+
+// Throughput returns how many tasks per second reached a final Result over
+// the last window, counting the second in progress. window is rounded up
+// to whole seconds and capped at an hour.
+func (p *TypedPool[In, Out]) Throughput(window time.Duration) float64 {
+	done, _, seconds := p.rates.sum(window)
+	return float64(done) / float64(seconds)
+}
+
+// ErrorRate returns the share of the final Results over the last window
+// that failed, or zero if there were none. window is treated as by
+// Throughput.
+func (p *TypedPool[In, Out]) ErrorRate(window time.Duration) float64 {
+	done, failed, _ := p.rates.sum(window)
+	if done == 0 {
+		return 0
+	}
+	return float64(failed) / float64(done)
+}
@@ -0,0 +1,86 @@
+package worker_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/rajatx185/golang-scalable-background-job-system/internal/clock"
+	"github.com/rajatx185/golang-scalable-background-job-system/internal/worker"
+)
+
+func TestDrainEstimate(t *testing.T) {
+	clk := clock.NewManual(time.Unix(0, 0))
+	started, release := make(chan struct{}), make(chan struct{})
+	pool := worker.NewPool(worker.Config{QueueSize: 4, Clock: clk}, func(ctx context.Context, t worker.Task) (string, error) {
+		if t.ID == "block" {
+			close(started)
+			<-release
+		}
+		return "ok", nil
+	})
+	if _, ok := pool.DrainEstimate(); ok {
+		t.Fatal("DrainEstimate ok before any task finished")
+	}
+
+	// One task a second for four seconds.
+	for i := range 4 {
+		pool.Process(context.Background(), []worker.Task{{ID: fmt.Sprint(i)}})
+		clk.Advance(time.Second)
+	}
+	if got := pool.Stats().Throughput; got != 1 {
+		t.Fatalf("Throughput = %v, want 1 task/s", got)
+	}
+
+	if err := pool.Submit(context.Background(), worker.Task{ID: "block"}); err != nil {
+		t.Fatal(err)
+	}
+	<-started
+	for _, id := range []string{"a", "b", "c"} {
+		if err := pool.Submit(context.Background(), worker.Task{ID: id}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got, ok := pool.DrainEstimate(); !ok || got != 3*time.Second {
+		t.Fatalf("DrainEstimate = %v, %v with 3 queued at 1 task/s, want 3s", got, ok)
+	}
+	close(release)
+	pool.Close()
+}
+
+func TestWindowedRates(t *testing.T) {
+	clk := clock.NewManual(time.Unix(0, 0))
+	pool := worker.NewPool(worker.Config{Clock: clk}, func(ctx context.Context, t worker.Task) (string, error) {
+		if t.ID == "bad" {
+			return "", errors.New("bad input")
+		}
+		return "ok", nil
+	})
+	defer pool.Close()
+
+	pool.Process(context.Background(), []worker.Task{{ID: "a"}, {ID: "b"}, {ID: "c"}, {ID: "bad"}})
+	if got := pool.Throughput(time.Second); got != 4 {
+		t.Fatalf("Throughput(1s) = %v, want 4", got)
+	}
+	if got := pool.ErrorRate(time.Second); got != 0.25 {
+		t.Fatalf("ErrorRate(1s) = %v, want 0.25", got)
+	}
+
+	// The bucket rotates on its own goroutine once the ticker fires.
+	clk.Advance(time.Second)
+	deadline := time.Now().Add(time.Second)
+	for pool.Throughput(time.Second) != 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if got := pool.Throughput(time.Second); got != 0 {
+		t.Fatalf("Throughput(1s) = %v a second later, want 0", got)
+	}
+	if got := pool.Throughput(2 * time.Second); got != 2 {
+		t.Fatalf("Throughput(2s) = %v, want 2", got)
+	}
+	if got := pool.ErrorRate(time.Minute); got != 0.25 {
+		t.Fatalf("ErrorRate(1m) = %v, want 0.25", got)
+	}
+}
@@ -0,0 +1,207 @@
+package worker_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rajatx185/golang-scalable-background-job-system/internal/worker"
+)
+
+func TestSoftThenHardTimeout(t *testing.T) {
+	release := make(chan struct{})
+	pool := worker.NewPool(worker.Config{
+		DefaultTimeout:   20 * time.Millisecond,
+		HardTimeoutGrace: 20 * time.Millisecond,
+		Logger:           slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}, func(ctx context.Context, t worker.Task) (string, error) {
+		if t.ID == "stuck" {
+			<-release // ignores ctx entirely
+			return "too late", nil
+		}
+		<-ctx.Done()
+		return "", ctx.Err()
+	})
+
+	results := pool.Process(context.Background(), []worker.Task{{ID: "stuck"}, {ID: "polite"}})
+	for _, r := range results {
+		want := context.DeadlineExceeded
+		if r.ID == "stuck" {
+			want = worker.ErrHardTimeout
+		}
+		if !errors.Is(r.Err, want) {
+			t.Errorf("task %s: err = %v, want %v", r.ID, r.Err, want)
+		}
+	}
+
+	if n := pool.Stats().Abandoned; n != 1 {
+		t.Fatalf("Abandoned = %d while the handler is stuck, want 1", n)
+	}
+	close(release)
+	deadline := time.Now().Add(time.Second)
+	for pool.Stats().Abandoned != 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if n := pool.Stats().Abandoned; n != 0 {
+		t.Fatalf("Abandoned = %d after the handler returned, want 0", n)
+	}
+}
+
+func TestMaxGoroutinesHoldsDispatch(t *testing.T) {
+	release := make(chan struct{})
+	pool := worker.NewPool(worker.Config{
+		Workers:          1,
+		MaxGoroutines:    2, // the worker plus one handler goroutine
+		DefaultTimeout:   10 * time.Millisecond,
+		HardTimeoutGrace: 10 * time.Millisecond,
+		Logger:           slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}, func(ctx context.Context, t worker.Task) (string, error) {
+		if t.ID == "stuck" {
+			<-release
+		}
+		return "done", nil
+	})
+	pool.Start(context.Background())
+	got := make(chan worker.Result, 2)
+	go func() {
+		for r := range pool.Results() {
+			got <- r
+		}
+	}()
+	for _, id := range []string{"stuck", "next"} {
+		if err := pool.Submit(context.Background(), worker.Task{ID: id}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if r := <-got; r.ID != "stuck" || !errors.Is(r.Err, worker.ErrHardTimeout) {
+		t.Fatalf("first result = %+v, want stuck abandoned", r)
+	}
+	select {
+	case r := <-got:
+		t.Fatalf("%s ran while the abandoned handler held the last goroutine", r.ID)
+	case <-time.After(50 * time.Millisecond):
+	}
+	if n := pool.Stats().Goroutines; n != 2 {
+		t.Fatalf("Goroutines = %d at the ceiling, want 2", n)
+	}
+
+	close(release)
+	if r := <-got; r.ID != "next" || r.Err != nil {
+		t.Fatalf("second result = %+v, want next to succeed once room freed up", r)
+	}
+	pool.Close()
+	if n := pool.Stats().Goroutines; n != 0 {
+		t.Fatalf("Goroutines = %d after Close, want 0", n)
+	}
+}
+
+func TestTimeoutCappedBySubmitDeadline(t *testing.T) {
+	pool := worker.NewPool(worker.Config{DefaultTimeout: time.Hour}, deadlineProbe)
+	pool.Start(context.Background())
+	got := make(chan worker.Result, 1)
+	go func() {
+		for r := range pool.Results() {
+			got <- r
+		}
+	}()
+
+	// The request that queues the task has a second left; the task's own
+	// hour-long timeout must not outlive it.
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := pool.Submit(ctx, worker.Task{ID: "a"}); err != nil {
+		t.Fatal(err)
+	}
+	r := <-got
+	pool.Close()
+	left, err := time.ParseDuration(r.Value)
+	if err != nil || left <= 0 || left > time.Second {
+		t.Fatalf("task had %q left, want under the submitter's 1s", r.Value)
+	}
+}
+
+func TestHandlerReceivesTaskTimeout(t *testing.T) {
+	const timeout, grace = 20 * time.Millisecond, 500 * time.Millisecond
+	release := make(chan struct{})
+	defer close(release)
+	pool := worker.NewPool(worker.Config{
+		Workers:          2,
+		HardTimeoutGrace: grace,
+		Logger:           slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}, func(ctx context.Context, t worker.Task) (string, error) {
+		if _, ok := ctx.Deadline(); !ok {
+			return "", errors.New("handler ctx has no deadline")
+		}
+		if t.ID == "ignores" {
+			<-release
+			return "too late", nil
+		}
+		<-ctx.Done()
+		return "", ctx.Err()
+	})
+	defer pool.Close()
+
+	results := pool.Process(context.Background(), []worker.Task{
+		{ID: "respects", Timeout: timeout},
+		{ID: "ignores", Timeout: timeout},
+	})
+	for _, r := range results {
+		switch r.ID {
+		case "respects":
+			if !errors.Is(r.Err, context.DeadlineExceeded) || r.Duration >= grace {
+				t.Errorf("respects: err = %v after %v, want DeadlineExceeded well before the hard deadline", r.Err, r.Duration)
+			}
+		case "ignores":
+			if !errors.Is(r.Err, worker.ErrHardTimeout) {
+				t.Errorf("ignores: err = %v, want ErrHardTimeout", r.Err)
+			}
+		}
+	}
+}
+
+func TestSlowTaskThreshold(t *testing.T) {
+	var logs syncBuffer
+	pool := worker.NewPool(worker.Config{
+		Workers:           3,
+		SlowTaskThreshold: 20 * time.Millisecond,
+		Logger:            slog.New(slog.NewTextHandler(&logs, nil)),
+	}, func(ctx context.Context, t worker.Task) (string, error) {
+		switch t.ID {
+		case "slow":
+			time.Sleep(40 * time.Millisecond)
+		case "timed-out":
+			<-ctx.Done()
+			return "", ctx.Err()
+		}
+		return "ok", nil
+	})
+	defer pool.Close()
+
+	results := pool.Process(context.Background(), []worker.Task{
+		{ID: "fast"},
+		{ID: "slow"},
+		{ID: "timed-out", Timeout: 40 * time.Millisecond},
+	})
+	for _, r := range results {
+		if r.ID != "timed-out" && r.Err != nil {
+			t.Errorf("task %s: %v, want a slow task to still succeed", r.ID, r.Err)
+		}
+	}
+	if n := pool.Stats().SlowTasks; n != 1 {
+		t.Fatalf("SlowTasks = %d, want 1 for the slow task alone", n)
+	}
+	var flagged []string
+	for _, line := range strings.Split(logs.String(), "\n") {
+		if strings.Contains(line, `msg="slow task"`) {
+			flagged = append(flagged, line)
+		}
+	}
+	if len(flagged) != 1 || !strings.Contains(flagged[0], "task=slow") {
+		t.Fatalf("slow-task warnings = %q, want one for slow", flagged)
+	}
+}
@@ -0,0 +1,256 @@
+package worker
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"sync"
+)
+
+// tracker remembers every task between submission and its final result, so
+// callers can reach tasks that are still queued or already running.
+type tracker struct {
+	mu   sync.Mutex
+	jobs map[string]*trackedJob
+	// settling counts, by ID, the tasks finish has let go of whose final
+	// result is still being delivered; see settle.
+	settling map[string]int
+	// forgotten is closed and replaced whenever a task stops being
+	// tracked or settling, while watched says a Flush is waiting for that.
+	forgotten chan struct{}
+	watched   bool
+}
+
+type trackedJob struct {
+	group     string
+	cancel    context.CancelFunc // nil until a worker picks the task up
+	cancelled bool
+	// ancestors are the IDs of the tasks above this one in its task
+	// tree, root first, so CancelTree still reaches it after its parent
+	// has finished.
+	ancestors []string
+}
+
+func newTracker() tracker {
+	return tracker{jobs: make(map[string]*trackedJob), settling: make(map[string]int), forgotten: make(chan struct{})}
+}
+
+// queue records task id as waiting for a worker. Queueing a task that is
+// already tracked keeps its existing state.
+func (tr *tracker) queue(id, group string) {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	if _, ok := tr.jobs[id]; !ok {
+		tr.jobs[id] = &trackedJob{group: group}
+	}
+}
+
+// queueChild is queue for a task spawned by task parent, which is still
+// running. The child joins its parent's tree, and starts out cancelled if
+// the parent already is.
+func (tr *tracker) queueChild(parent, id, group string) {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	if _, ok := tr.jobs[id]; ok {
+		return
+	}
+	job := &trackedJob{group: group, ancestors: []string{parent}}
+	if p, ok := tr.jobs[parent]; ok {
+		job.ancestors = append(slices.Clone(p.ancestors), parent)
+		job.cancelled = p.cancelled
+	}
+	tr.jobs[id] = job
+}
+
+// start marks task id as in flight and returns the context its attempts
+// run under. It reports false if the task was cancelled while queued, in
+// which case it is no longer tracked.
+func (tr *tracker) start(ctx context.Context, id, group string) (context.Context, bool) {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	job, ok := tr.jobs[id]
+	if !ok {
+		job = &trackedJob{group: group}
+		tr.jobs[id] = job
+	}
+	if job.cancelled {
+		tr.forgetLocked(id)
+		return nil, false
+	}
+	ctx, job.cancel = context.WithCancel(ctx)
+	return ctx, true
+}
+
+// requeue marks task id as waiting again after a failed attempt,
+// releasing that attempt's context.
+func (tr *tracker) requeue(id string) {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	if job, ok := tr.jobs[id]; ok && job.cancel != nil {
+		job.cancel()
+		job.cancel = nil
+	}
+}
+
+// finish forgets task id once it has a final result, and reports whether
+// it was in flight rather than waiting for a worker.
+func (tr *tracker) finish(id string) (running bool) {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	job, ok := tr.jobs[id]
+	if !ok {
+		return false
+	}
+	if job.cancel != nil {
+		job.cancel()
+	}
+	tr.forgetLocked(id)
+	return job.cancel != nil
+}
+
+// finishSettling is finish for a task whose final result is about to be
+// delivered: Flush keeps waiting for it until settled is called.
+func (tr *tracker) finishSettling(id string) (running bool) {
+	tr.mu.Lock()
+	tr.settling[id]++
+	tr.mu.Unlock()
+	return tr.finish(id)
+}
+
+// settled marks the final result of task id as delivered.
+func (tr *tracker) settled(id string) {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	if tr.settling[id]--; tr.settling[id] <= 0 {
+		delete(tr.settling, id)
+	}
+	tr.wakeLocked()
+}
+
+// forgetLocked stops tracking task id and wakes any Flush. It requires
+// tr.mu.
+func (tr *tracker) forgetLocked(id string) {
+	delete(tr.jobs, id)
+	tr.wakeLocked()
+}
+
+// wakeLocked wakes any Flush waiting on forgotten. It requires tr.mu.
+func (tr *tracker) wakeLocked() {
+	if tr.watched {
+		close(tr.forgotten)
+		tr.forgotten = make(chan struct{})
+		tr.watched = false
+	}
+}
+
+// ids returns the IDs of every task tracked right now.
+func (tr *tracker) ids() []string {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	return slices.Collect(maps.Keys(tr.jobs))
+}
+
+// stillTracked returns the IDs in ids that are still tracked or settling,
+// and a channel that is closed once that may have changed.
+func (tr *tracker) stillTracked(ids []string) ([]string, <-chan struct{}) {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	ids = slices.DeleteFunc(ids, func(id string) bool {
+		_, tracked := tr.jobs[id]
+		return !tracked && tr.settling[id] == 0
+	})
+	if len(ids) > 0 {
+		tr.watched = true
+	}
+	return ids, tr.forgotten
+}
+
+// cancelled reports whether task id was cancelled while waiting for a
+// worker.
+func (tr *tracker) cancelled(id string) bool {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	job, ok := tr.jobs[id]
+	return ok && job.cancelled
+}
+
+// restore tracks task id as waiting, with the cancellation state it had
+// when it was exported.
+func (tr *tracker) restore(id, group string, cancelled bool) {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	tr.jobs[id] = &trackedJob{group: group, cancelled: cancelled}
+}
+
+// unfinished returns the IDs of the tasks still tracked, split into those
+// waiting for a worker and those in flight.
+func (tr *tracker) unfinished() (queued, running []string) {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	for id, job := range tr.jobs {
+		if job.cancel != nil {
+			running = append(running, id)
+		} else {
+			queued = append(queued, id)
+		}
+	}
+	return queued, running
+}
+
+func (tr *tracker) len() int {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	return len(tr.jobs)
+}
+
+// cancelTree cancels task id and every tracked task descended from it,
+// and returns the IDs it cancelled.
+func (tr *tracker) cancelTree(id string) map[string]bool {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	cancelled := make(map[string]bool)
+	for jobID, job := range tr.jobs {
+		if job.cancelled || (jobID != id && !slices.Contains(job.ancestors, id)) {
+			continue
+		}
+		job.cancelled = true
+		if job.cancel != nil {
+			job.cancel()
+		}
+		cancelled[jobID] = true
+	}
+	return cancelled
+}
+
+// cancel cancels task id alone. It reports false if the task isn't
+// tracked or was cancelled already.
+func (tr *tracker) cancel(id string) bool {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	job, ok := tr.jobs[id]
+	if !ok || job.cancelled {
+		return false
+	}
+	job.cancelled = true
+	if job.cancel != nil {
+		job.cancel()
+	}
+	return true
+}
+
+func (tr *tracker) cancelGroup(group string) int {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	n := 0
+	for _, job := range tr.jobs {
+		if job.group != group || job.cancelled {
+			continue
+		}
+		job.cancelled = true
+		if job.cancel != nil {
+			job.cancel()
+		}
+		n++
+	}
+	return n
+}
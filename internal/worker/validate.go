@@ -0,0 +1,95 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// TypedValidateFunc checks the value a handler returned for t with a nil
+// error, and returns an error if the value is unusable.
+type TypedValidateFunc[In, Out any] func(t TypedTask[In], value Out) error
+
+// ValidateFunc is the TypedValidateFunc for string tasks and results.
+type ValidateFunc = TypedValidateFunc[string, string]
+
+// Validated wraps process so that a value validate rejects fails the
+// attempt with ErrInvalidResult instead of succeeding. Like any error,
+// the failure is fatal under DefaultIsRetryable; wrap Config.IsRetryable
+// with RetryInvalidResults to retry it. Errors from process itself are
+// passed through without validation.
+func Validated[In, Out any](process TypedProcessFunc[In, Out], validate TypedValidateFunc[In, Out]) TypedProcessFunc[In, Out] {
+	return func(ctx context.Context, t TypedTask[In]) (Out, error) {
+		value, err := process(ctx, t)
+		if err != nil {
+			return value, err
+		}
+		if err := validate(t, value); err != nil {
+			var zero Out
+			return zero, fmt.Errorf("%w: %w", ErrInvalidResult, err)
+		}
+		return value, nil
+	}
+}
+
+// RetryInvalidResults returns a classifier for Config.IsRetryable that
+// retries values rejected by Validated, on the grounds that a handler
+// returning garbage usually got it from a flaky upstream, and leaves every
+// other error to next, or to DefaultIsRetryable if next is nil.
+func RetryInvalidResults(next func(error) bool) func(error) bool {
+	if next == nil {
+		next = DefaultIsRetryable
+	}
+	return func(err error) bool {
+		return errors.Is(err, ErrInvalidResult) || next(err)
+	}
+}
+
+// Validate checks t the way Submit does before queueing it, without
+// queueing it or touching the pool: a task needs an ID and a known Kind,
+// its Timeout and weights can't be negative, and neither weight may exceed
+// the limit it counts against, Config.MaxWeight or its ResourceKey's
+// entry in Config.ResourceLimits, since the task could then never be
+// admitted. The error wraps ErrInvalidTask and lists every problem found;
+// one over a limit also matches ErrOverResourceLimit.
+func (p *TypedPool[In, Out]) Validate(t TypedTask[In]) error {
+	var problems taskProblems
+	if t.ID == "" {
+		problems = append(problems, errors.New("empty ID"))
+	}
+	if t.Kind != KindCPU && t.Kind != KindIO {
+		problems = append(problems, fmt.Errorf("unknown Kind %v", t.Kind))
+	}
+	if t.Timeout < 0 {
+		problems = append(problems, fmt.Errorf("negative Timeout %v", t.Timeout))
+	}
+	if t.Weight < 0 {
+		problems = append(problems, fmt.Errorf("negative Weight %d", t.Weight))
+	} else if n := int64(max(t.Weight, 1)); n > p.weights.limit {
+		problems = append(problems, fmt.Errorf("%w: Weight %d, MaxWeight allows %d", ErrOverResourceLimit, n, p.weights.limit))
+	}
+	if t.ResourceWeight < 0 {
+		problems = append(problems, fmt.Errorf("negative ResourceWeight %d", t.ResourceWeight))
+	} else if sem, ok := p.resources[t.ResourceKey]; ok && max(t.ResourceWeight, 1) > sem.limit {
+		problems = append(problems, fmt.Errorf("%w: ResourceWeight %d, %q allows %d", ErrOverResourceLimit, max(t.ResourceWeight, 1), t.ResourceKey, sem.limit))
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w %q: %w", ErrInvalidTask, t.ID, problems)
+}
+
+// taskProblems is what Validate found wrong with a task, reported on one
+// line rather than errors.Join's one per problem.
+type taskProblems []error
+
+func (ps taskProblems) Error() string {
+	msgs := make([]string, len(ps))
+	for i, err := range ps {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+func (ps taskProblems) Unwrap() []error { return ps }
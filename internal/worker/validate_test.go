@@ -0,0 +1,102 @@
+package worker_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rajatx185/golang-scalable-background-job-system/internal/worker"
+)
+
+func TestValidatedRetriesRejectedValues(t *testing.T) {
+	var calls atomic.Int32
+	process := func(ctx context.Context, t worker.Task) (string, error) {
+		if t.ID == "flaky" && calls.Add(1) > 1 {
+			return `{"ok":true}`, nil
+		}
+		return "<html>502 Bad Gateway</html>", nil
+	}
+	validate := func(t worker.Task, value string) error {
+		if value == "" || value[0] != '{' {
+			return errors.New("not JSON")
+		}
+		return nil
+	}
+	pool := worker.NewPool(worker.Config{MaxRetries: 2, IsRetryable: worker.RetryInvalidResults(nil)}, worker.Validated(process, validate))
+	defer pool.Close()
+
+	for _, r := range pool.Process(context.Background(), []worker.Task{{ID: "flaky"}, {ID: "garbage"}}) {
+		switch r.ID {
+		case "flaky":
+			if r.Err != nil || r.Value != `{"ok":true}` || r.Attempt != 2 {
+				t.Errorf("flaky = %+v, want success on attempt 2", r)
+			}
+			if len(r.Attempts) != 2 || !errors.Is(r.Attempts[0].Err, worker.ErrInvalidResult) {
+				t.Errorf("flaky attempts = %+v, want the first rejected", r.Attempts)
+			}
+		case "garbage":
+			if !errors.Is(r.Err, worker.ErrInvalidResult) || r.Value != "" || r.Attempt != 3 {
+				t.Errorf("garbage = %+v, want ErrInvalidResult with no value after 3 attempts", r)
+			}
+		}
+	}
+}
+
+func TestValidatedRejectionIsFatalByDefault(t *testing.T) {
+	var calls atomic.Int32
+	process := func(ctx context.Context, t worker.Task) (string, error) {
+		calls.Add(1)
+		return "<html>502 Bad Gateway</html>", nil
+	}
+	validate := func(t worker.Task, value string) error { return errors.New("not JSON") }
+	dead := make(chan worker.DeadLetter, 1)
+	pool := worker.NewPool(worker.Config{MaxRetries: 2, DeadLetter: dead}, worker.Validated(process, validate))
+	defer pool.Close()
+
+	r := pool.Process(context.Background(), []worker.Task{{ID: "garbage"}})[0]
+	if !errors.Is(r.Err, worker.ErrInvalidResult) || r.Attempt != 1 || calls.Load() != 1 {
+		t.Fatalf("garbage = %+v after %d calls, want ErrInvalidResult on the only attempt", r, calls.Load())
+	}
+	if d := <-dead; d.Reason != worker.ReasonFatal {
+		t.Fatalf("dead letter reason = %q, want %q", d.Reason, worker.ReasonFatal)
+	}
+}
+
+func TestValidateTask(t *testing.T) {
+	pool := worker.NewPool(worker.Config{MaxWeight: 4, ResourceLimits: map[string]int64{"db": 2}},
+		func(ctx context.Context, t worker.Task) (string, error) { return "", nil })
+	tests := []struct {
+		task      worker.Task
+		wantLimit bool // also matches ErrOverResourceLimit
+	}{
+		{task: worker.Task{}},
+		{task: worker.Task{ID: "a", Timeout: -time.Second}},
+		{task: worker.Task{ID: "a", Kind: worker.KindIO + 1}},
+		{task: worker.Task{ID: "a", Weight: -1}},
+		{task: worker.Task{ID: "a", Weight: 5}, wantLimit: true},
+		{task: worker.Task{ID: "a", ResourceKey: "db", ResourceWeight: 3}, wantLimit: true},
+	}
+	for _, tt := range tests {
+		err := pool.Validate(tt.task)
+		if !errors.Is(err, worker.ErrInvalidTask) || errors.Is(err, worker.ErrOverResourceLimit) != tt.wantLimit {
+			t.Errorf("Validate(%+v) = %v", tt.task, err)
+		}
+	}
+	if err := pool.Validate(worker.Task{ID: "ok", Weight: 4, ResourceKey: "db", ResourceWeight: 2}); err != nil {
+		t.Fatalf("Validate of a task at the limits = %v", err)
+	}
+
+	// Submit rejects an invalid task outright, before it is tracked.
+	pool.Start(context.Background())
+	defer pool.Close()
+	err := pool.Submit(context.Background(), worker.Task{ID: "bad", Timeout: -time.Second, Weight: -1})
+	if !errors.Is(err, worker.ErrInvalidTask) || !strings.Contains(err.Error(), "Timeout") || !strings.Contains(err.Error(), "Weight") {
+		t.Fatalf("Submit = %v, want ErrInvalidTask naming both problems", err)
+	}
+	if _, err := pool.Wait(context.Background(), "bad"); !errors.Is(err, worker.ErrUnknownJob) {
+		t.Fatalf("Wait = %v for a rejected task, want ErrUnknownJob", err)
+	}
+}
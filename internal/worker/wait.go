@@ -0,0 +1,135 @@
+package worker
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/rajatx185/golang-scalable-background-job-system/internal/clock"
+	"github.com/rajatx185/golang-scalable-background-job-system/internal/store"
+)
+
+// waiters holds a completion per submitted task so Wait can block on it.
+// Finished completions are kept, up to a count and for an optional
+// retention window, so a Wait that arrives just after the task finished
+// still gets its result. Pending ones are kept until they finish.
+type waiters[Out any] struct {
+	mu       sync.Mutex
+	pending  map[string]*completion[Out]
+	done     *store.Store[*completion[Out]] // expires after ttl, if set
+	finished []finishedJob[Out]             // oldest first; evicted past retain
+	retain   int
+	ttl      time.Duration
+}
+
+type finishedJob[Out any] struct {
+	id string
+	c  *completion[Out]
+}
+
+type completion[Out any] struct {
+	done chan struct{}
+	res  TypedResult[Out]
+	err  error
+}
+
+func newWaiters[Out any](retain int, ttl time.Duration, clk clock.Clock) *waiters[Out] {
+	return &waiters[Out]{
+		pending: make(map[string]*completion[Out]),
+		done:    store.NewStoreWithClock[*completion[Out]](clk),
+		retain:  retain,
+		ttl:     ttl,
+	}
+}
+
+// register opens a completion for task id and returns it. A task
+// resubmitted after it finished gets a fresh one; one still pending keeps
+// its own.
+func (w *waiters[Out]) register(id string) *completion[Out] {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if c, ok := w.pending[id]; ok {
+		return c
+	}
+	c := &completion[Out]{done: make(chan struct{})}
+	w.pending[id] = c
+	return c
+}
+
+// forget drops the completion of a task whose submission failed.
+func (w *waiters[Out]) forget(id string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	delete(w.pending, id)
+}
+
+// resolve settles task id, waking every waiter.
+func (w *waiters[Out]) resolve(id string, res TypedResult[Out], err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	c, ok := w.pending[id]
+	if !ok {
+		return
+	}
+	delete(w.pending, id)
+	c.res, c.err = res, err
+	close(c.done)
+
+	if w.ttl > 0 {
+		w.done.SetWithTTL(id, c, w.ttl)
+	} else {
+		w.done.Set(id, c)
+	}
+	w.finished = append(w.finished, finishedJob[Out]{id, c})
+	for len(w.finished) > w.retain {
+		old := w.finished[0]
+		w.finished = w.finished[1:]
+		// The ID may have finished again since; keep the newer result.
+		if c, ok := w.done.Get(old.id); ok && c == old.c {
+			w.done.Delete(old.id)
+		}
+	}
+}
+
+func (w *waiters[Out]) get(id string) (*completion[Out], bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if c, ok := w.pending[id]; ok {
+		return c, true
+	}
+	return w.done.Get(id)
+}
+
+// sweep reclaims the finished results past their retention window.
+func (w *waiters[Out]) sweep() { w.done.Sweep() }
+
+// retained reports how many finished results are held, counting ones past
+// retention that have not been swept yet.
+func (w *waiters[Out]) retained() int {
+	return w.done.Len()
+}
+
+func (c *completion[Out]) finished() bool {
+	select {
+	case <-c.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// Wait blocks until task id has its final result, the same one Results
+// delivers, and returns it. Any number of callers may wait on the same
+// task. Wait returns ErrUnknownJob for an ID that was never submitted, or
+// whose result has aged out: it finished more than Config.ResultRetention
+// ago, or before the last Config.WaitRetention tasks did. It returns
+// ErrClosed if the task was handed off with Persist, and ctx.Err() if ctx
+// is done first. It does not consume Results, which still has to be
+// ranged over.
+func (p *TypedPool[In, Out]) Wait(ctx context.Context, id string) (TypedResult[Out], error) {
+	c, ok := p.waiters.get(id)
+	if !ok {
+		return TypedResult[Out]{}, ErrUnknownJob
+	}
+	return (&TypedFuture[Out]{id: id, c: c}).Get(ctx)
+}
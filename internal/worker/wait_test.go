@@ -0,0 +1,140 @@
+package worker_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rajatx185/golang-scalable-background-job-system/internal/clock"
+	"github.com/rajatx185/golang-scalable-background-job-system/internal/worker"
+)
+
+func TestWaitUnblocksEveryWaiter(t *testing.T) {
+	release := make(chan struct{})
+	pool := worker.NewPool(worker.Config{}, func(ctx context.Context, task worker.Task) (string, error) {
+		<-release
+		return "done " + task.ID, nil
+	})
+	pool.Start(context.Background())
+	go func() {
+		for range pool.Results() {
+		}
+	}()
+	if err := pool.Submit(context.Background(), worker.Task{ID: "a"}); err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for range 3 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res, err := pool.Wait(context.Background(), "a")
+			if err != nil || res.Value != "done a" {
+				t.Errorf("Wait = %+v, %v; want done a", res, err)
+			}
+		}()
+	}
+	close(release)
+	wg.Wait()
+
+	// A finished task can still be waited on.
+	if res, err := pool.Wait(context.Background(), "a"); err != nil || res.Value != "done a" {
+		t.Fatalf("Wait after finish = %+v, %v", res, err)
+	}
+	pool.Close()
+}
+
+func TestWaitErrors(t *testing.T) {
+	release := make(chan struct{})
+	pool := worker.NewPool(worker.Config{WaitRetention: 1}, func(ctx context.Context, task worker.Task) (string, error) {
+		if task.ID == "slow" {
+			<-release
+		}
+		return "", nil
+	})
+	pool.Start(context.Background())
+	go func() {
+		for range pool.Results() {
+		}
+	}()
+
+	if _, err := pool.Wait(context.Background(), "nope"); !errors.Is(err, worker.ErrUnknownJob) {
+		t.Fatalf("Wait on unknown ID: %v, want ErrUnknownJob", err)
+	}
+
+	if err := pool.Submit(context.Background(), worker.Task{ID: "slow"}); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := pool.Wait(ctx, "slow"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Wait past ctx deadline: %v, want DeadlineExceeded", err)
+	}
+	close(release)
+	if _, err := pool.Wait(context.Background(), "slow"); err != nil {
+		t.Fatal(err)
+	}
+
+	// With a retention of one, the next finished task evicts the first.
+	if err := pool.Submit(context.Background(), worker.Task{ID: "next"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := pool.Wait(context.Background(), "next"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := pool.Wait(context.Background(), "slow"); !errors.Is(err, worker.ErrUnknownJob) {
+		t.Fatalf("Wait on evicted ID: %v, want ErrUnknownJob", err)
+	}
+	pool.Close()
+}
+
+func TestResultRetentionEvictsFinishedResults(t *testing.T) {
+	clk := clock.NewManual(time.Unix(0, 0))
+	release := make(chan struct{})
+	pool := worker.NewPool(worker.Config{ResultRetention: time.Minute, Clock: clk}, func(ctx context.Context, task worker.Task) (string, error) {
+		if task.ID == "running" {
+			<-release
+		}
+		return "done", nil
+	})
+	pool.Start(context.Background())
+	results := make(chan worker.Result, 2)
+	go func() {
+		for r := range pool.Results() {
+			results <- r
+		}
+	}()
+	for _, id := range []string{"finished", "running"} {
+		if err := pool.Submit(context.Background(), worker.Task{ID: id}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if r := <-results; r.ID != "finished" {
+		t.Fatalf("first result = %s, want finished", r.ID)
+	}
+	if n := pool.Stats().RetainedResults; n != 1 {
+		t.Fatalf("RetainedResults = %d, want 1", n)
+	}
+
+	clk.Advance(time.Minute)
+	if _, err := pool.Wait(context.Background(), "finished"); !errors.Is(err, worker.ErrUnknownJob) {
+		t.Fatalf("Wait past retention: err = %v, want ErrUnknownJob", err)
+	}
+	deadline := time.Now().Add(time.Second)
+	for pool.Stats().RetainedResults != 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if n := pool.Stats().RetainedResults; n != 0 {
+		t.Fatalf("RetainedResults = %d after the sweep, want 0", n)
+	}
+
+	// The running task is never evicted, however long it takes.
+	close(release)
+	if res, err := pool.Wait(context.Background(), "running"); err != nil || res.Value != "done" {
+		t.Fatalf("Wait on running task = %+v, %v", res, err)
+	}
+	pool.Close()
+}
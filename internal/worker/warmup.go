@@ -0,0 +1,96 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// WorkerState is whatever Config.OnWorkerStart set up for one worker, such
+// as a connection or a compiled template. Handlers get it back with
+// WorkerStateFromContext.
+type WorkerState = any
+
+type workerStateKey struct{}
+
+// workerState wraps the state in the context so a nil one still counts as
+// set.
+type workerState struct{ v WorkerState }
+
+// WorkerStateFromContext returns the state Config.OnWorkerStart returned
+// for the worker running the task. It reports false outside a
+// pool-managed context or when the pool has no OnWorkerStart.
+func WorkerStateFromContext(ctx context.Context) (WorkerState, bool) {
+	s, ok := ctx.Value(workerStateKey{}).(workerState)
+	return s.v, ok
+}
+
+func (p *TypedPool[In, Out]) withWorkerState(ctx context.Context, state WorkerState) context.Context {
+	if p.cfg.OnWorkerStart == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, workerStateKey{}, workerState{state})
+}
+
+// warm runs Config.OnWorkerStart for worker id.
+func (p *TypedPool[In, Out]) warm(id int) (WorkerState, error) {
+	if p.cfg.OnWorkerStart == nil {
+		return nil, nil
+	}
+	state, err := p.cfg.OnWorkerStart(id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: worker %d: %w", ErrWarmup, id, err)
+	}
+	return state, nil
+}
+
+// warmAll warms up workers 1 to n, returning their states indexed by
+// ID - 1. If any fail, it tears down the ones that warmed up and returns
+// every failure joined.
+func (p *TypedPool[In, Out]) warmAll(n int) ([]WorkerState, error) {
+	states := make([]WorkerState, n)
+	warmed := make([]bool, n)
+	var errs []error
+	for i := range states {
+		var err error
+		if states[i], err = p.warm(i + 1); err != nil {
+			errs = append(errs, err)
+		} else {
+			warmed[i] = true
+		}
+	}
+	if len(errs) == 0 {
+		return states, nil
+	}
+	for i, ok := range warmed {
+		if ok {
+			p.teardown(i+1, states[i])
+		}
+	}
+	return nil, errors.Join(errs...)
+}
+
+// teardown runs Config.OnWorkerStop for worker id.
+func (p *TypedPool[In, Out]) teardown(id int, state WorkerState) {
+	if p.cfg.OnWorkerStop != nil {
+		p.cfg.OnWorkerStop(id, state)
+	}
+}
+
+// reap stands in for worker id when it couldn't warm up, failing the
+// tasks that would have been its with err so none of them wait forever.
+// ID 0 takes every task.
+func (p *TypedPool[In, Out]) reap(ctx, runCtx context.Context, id int, err error) {
+	defer p.workers.Done()
+	defer p.vacate(ctx, runCtx, id)
+	for {
+		j, ok := p.queue.pop(id)
+		if !ok {
+			return
+		}
+		p.take(&j)
+		res := p.result(j)
+		res.Err = err
+		p.complete(j, res)
+	}
+}
@@ -0,0 +1,156 @@
+package worker_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/rajatx185/golang-scalable-background-job-system/internal/worker"
+)
+
+func TestOnWorkerStartStateReachesHandler(t *testing.T) {
+	var warmups atomic.Int64
+	pool := worker.NewPool(worker.Config{
+		Workers: 2,
+		OnWorkerStart: func(id int) (worker.WorkerState, error) {
+			warmups.Add(1)
+			return fmt.Sprintf("conn-%d", id), nil
+		},
+	}, func(ctx context.Context, t worker.Task) (string, error) {
+		state, ok := worker.WorkerStateFromContext(ctx)
+		id, _ := worker.WorkerIDFromContext(ctx)
+		if !ok || state != fmt.Sprintf("conn-%d", id) {
+			return "", fmt.Errorf("worker %d got state %v, %v", id, state, ok)
+		}
+		return state.(string), nil
+	})
+	defer pool.Close()
+
+	tasks := make([]worker.Task, 10)
+	for i := range tasks {
+		tasks[i].ID = fmt.Sprint(i)
+	}
+	for _, r := range pool.Process(context.Background(), tasks) {
+		if r.Err != nil {
+			t.Fatal(r.Err)
+		}
+	}
+	if n := warmups.Load(); n != 2 {
+		t.Fatalf("OnWorkerStart ran %d times for 2 workers, want 2", n)
+	}
+}
+
+func TestOnWorkerStartFailureStopsPool(t *testing.T) {
+	bad := errors.New("dial refused")
+	var ran atomic.Bool
+	pool := worker.NewPool(worker.Config{
+		Workers: 2,
+		Logger:  slog.New(slog.NewTextHandler(io.Discard, nil)),
+		OnWorkerStart: func(id int) (worker.WorkerState, error) {
+			if id == 2 {
+				return nil, bad
+			}
+			return nil, nil
+		},
+	}, func(ctx context.Context, t worker.Task) (string, error) {
+		ran.Store(true)
+		return "", nil
+	})
+	if err := pool.Submit(context.Background(), worker.Task{ID: "early"}); err != nil {
+		t.Fatal(err)
+	}
+	pool.Start(context.Background())
+	if err := pool.Submit(context.Background(), worker.Task{ID: "late"}); !errors.Is(err, worker.ErrWarmup) || !errors.Is(err, bad) {
+		t.Fatalf("Submit after a failed warmup: err = %v, want ErrWarmup wrapping the hook's error", err)
+	}
+
+	go pool.Close()
+	for r := range pool.Results() {
+		if r.ID != "early" || !errors.Is(r.Err, worker.ErrWarmup) {
+			t.Fatalf("result %+v, want the queued task failed with ErrWarmup", r)
+		}
+	}
+	if ran.Load() {
+		t.Fatal("the handler ran on a pool that failed to warm up")
+	}
+}
+
+// lifecycle hands out a fresh state per warmup and counts the teardowns of
+// each.
+type lifecycle struct {
+	mu      sync.Mutex
+	next    int
+	stopped map[int]int
+	failID  int
+}
+
+func (l *lifecycle) start(id int) (worker.WorkerState, error) {
+	if id == l.failID {
+		return nil, errors.New("dial refused")
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.next++
+	return l.next, nil
+}
+
+func (l *lifecycle) stop(id int, state worker.WorkerState) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.stopped[state.(int)]++
+}
+
+// check fails unless every state handed out was torn down exactly once.
+func (l *lifecycle) check(t *testing.T, warmups int) {
+	t.Helper()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.next != warmups {
+		t.Fatalf("%d warmups, want %d", l.next, warmups)
+	}
+	for state := 1; state <= l.next; state++ {
+		if n := l.stopped[state]; n != 1 {
+			t.Fatalf("state %d torn down %d times, want once", state, n)
+		}
+	}
+}
+
+func TestOnWorkerStopRunsOncePerWorker(t *testing.T) {
+	t.Run("shutdown and restart", func(t *testing.T) {
+		l := &lifecycle{stopped: map[int]int{}}
+		var panicked atomic.Bool
+		pool := worker.NewPool(worker.Config{
+			Workers:       2,
+			OnPanic:       worker.PanicRestart,
+			OnWorkerStart: l.start,
+			OnWorkerStop:  l.stop,
+			Logger:        slog.New(slog.NewTextHandler(io.Discard, nil)),
+		}, func(ctx context.Context, t worker.Task) (string, error) {
+			if t.ID == "panic" && !panicked.Swap(true) {
+				panic("boom")
+			}
+			return "", nil
+		})
+		pool.Process(context.Background(), []worker.Task{{ID: "panic"}, {ID: "a"}, {ID: "b"}})
+		pool.Close()
+		l.check(t, 3) // two workers plus the one replacing the panicked worker
+	})
+
+	t.Run("failed warmup", func(t *testing.T) {
+		l := &lifecycle{stopped: map[int]int{}, failID: 3}
+		pool := worker.NewPool(worker.Config{
+			Workers:       3,
+			OnWorkerStart: l.start,
+			OnWorkerStop:  l.stop,
+			Logger:        slog.New(slog.NewTextHandler(io.Discard, nil)),
+		}, func(context.Context, worker.Task) (string, error) { return "", nil })
+		pool.Start(context.Background())
+		pool.Close()
+		l.check(t, 2)
+	})
+}
@@ -6,6 +6,8 @@
 // 	"time"
 // 	"net/http"
 // 	"io"
+//
+// 	"github.com/rajatx185/golang-scalable-background-job-system/internal/httpclient"
 // )
 
 // func fetchWithTimeout(url string, timeout time.Duration) (string, error) {
@@ -19,8 +21,8 @@
 // 	return "", err
 // }
 
-// // Execute request
-// resp, err := http.DefaultClient.Do(req)
+// // Execute through the shared, pool-tuned client
+// resp, err := httpclient.Default().Do(req)
 // if err != nil {
 // 	return "", err
 // }
@@ -3,19 +3,20 @@
 // import (
 // 	"fmt"
 // 	"context"
+//
+// 	"github.com/rajatx185/golang-scalable-background-job-system/internal/ctxutil"
 // )
 
 // type contextKey string
 
 // const (
-// 	userIdKey contextKey = "userID"
 // 	requestIdKey contextKey = "requestID"
 // )
 
 // func main() {
 // 	// Create context with values
 // 	ctx := context.Background()
-// 	ctx = context.WithValue(ctx, userIdKey, "rajatx185")
+// 	ctx = ctxutil.WithUserID(ctx, "rajatx185")
 // 	ctx = context.WithValue(ctx, requestIdKey, "req-12345")
 
 // 	// pass to functions
@@ -23,8 +24,10 @@
 // }
 
 // func handleRequest(ctx context.Context) {
-// 	// Retrieve values from context
-// 	userId := ctx.Value(userIdKey).(string)
+// 	// Retrieve values from context. A bare ctx.Value(userIdKey).(string)
+// 	// panics when the value is missing; ctxutil.UserIDFromContext
+// 	// reports ok=false instead.
+// 	userId, _ := ctxutil.UserIDFromContext(ctx)
 // 	requestId := ctx.Value(requestIdKey).(string)
 
 // 	fmt.Printf("Handling request %s for user %s\n", requestId, userId)
@@ -36,6 +39,6 @@
 
 // func processData(ctx context.Context) {
 // 	// can still access context values
-// 	userId := ctx.Value((userIdKey))
+// 	userId, _ := ctxutil.UserIDFromContext(ctx)
 // 	fmt.Println("Processing data for user:", userId)
 // }
\ No newline at end of file
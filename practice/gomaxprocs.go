@@ -2,9 +2,10 @@ package main
 
 import (
     "fmt"
-    "net/http"
     "runtime"
     "time"
+
+    "github.com/rajatx185/golang-scalable-background-job-system/internal/httpclient"
 )
 
 func demonstrateThreadCreation() {
@@ -17,7 +18,7 @@ func demonstrateThreadCreation() {
     for i := 0; i < 100; i++ {
         go func(id int) {
             // Blocking system call (file I/O, network, etc.)
-            resp, _ := http.Get("https://example.com")
+            resp, _ := httpclient.Default().Get("https://example.com")
             if resp != nil {
                 resp.Body.Close()
             }
@@ -1,16 +1,36 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"time"
 )
 
 func main() {
+	// Stop after ~5 ticks, or earlier if the context is cancelled.
+	ctx, cancel := context.WithTimeout(context.Background(), 5500*time.Millisecond)
+	defer cancel()
+
 	ticker := time.NewTicker(1 * time.Second)
 	defer ticker.Stop()
 
-	for i := 0; i < 5; i++ {
-		t := <-ticker.C
-		fmt.Println("Ticked at", t)
+	var last time.Time
+	dropped := 0
+	for {
+		select {
+		case <-ctx.Done():
+			fmt.Println("Stopped:", ctx.Err(), "dropped ticks:", dropped)
+			return
+		case t := <-ticker.C:
+			// ticker.C holds one tick; a slow consumer loses the rest.
+			if !last.IsZero() {
+				if missed := int(t.Sub(last)/time.Second) - 1; missed > 0 {
+					dropped += missed
+					fmt.Println("Dropped", missed, "ticks")
+				}
+			}
+			last = t
+			fmt.Println("Ticked at", t)
+		}
 	}
 }